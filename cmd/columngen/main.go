@@ -0,0 +1,167 @@
+// Command columngen generates typed column references for the domain models
+//
+// For every model it writes a table constant, one types.Column constant per column and a
+// struct with the same columns for field-style access into columns_gen.go of the model's package:
+//
+//	const ColTeamMemberUserID types.Column = "team_members.user_id"
+//	TeamMemberColumns.UserID.In("tm_user") // "tm_user.user_id"
+//
+// Columns are resolved with GORM's own schema parser, so tags like column, embedded and "-"
+// are honored. Columns are generated for every model migrated by db.Models, run it with go generate from cmd
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"gorm.io/gorm/schema"
+	"konsultn-api/internal/db"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const outputFile = "columns_gen.go"
+
+// modelColumns holds the parsed columns of a single model
+type modelColumns struct {
+	Name    string
+	Table   string
+	Columns []*schema.Field
+}
+
+// modelPackage groups the models living in the same Go package
+type modelPackage struct {
+	Name   string
+	Path   string
+	Models []modelColumns
+}
+
+func main() {
+	root, module, err := findModule()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	packages, order, err := parseModels()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, path := range order {
+		pkg := packages[path]
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(pkg.Path, module), "/")
+		target := filepath.Join(root, filepath.FromSlash(rel), outputFile)
+
+		src, err := render(pkg)
+		if err != nil {
+			log.Fatalf("failed to render %s: %v", target, err)
+		}
+		if err := os.WriteFile(target, src, 0o644); err != nil {
+			log.Fatalf("failed to write %s: %v", target, err)
+		}
+		log.Printf("wrote %s", target)
+	}
+}
+
+// parseModels parses every model with GORM and groups them by package, in the order they are listed
+func parseModels() (map[string]*modelPackage, []string, error) {
+	cache := &sync.Map{}
+	namer := schema.NamingStrategy{}
+
+	packages := make(map[string]*modelPackage)
+	var order []string
+
+	for _, m := range db.Models() {
+		s, err := schema.Parse(m, cache, namer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %T: %w", m, err)
+		}
+
+		t := reflect.Indirect(reflect.ValueOf(m)).Type()
+		path := t.PkgPath()
+		pkg, ok := packages[path]
+		if !ok {
+			name, _, _ := strings.Cut(t.String(), ".")
+			pkg = &modelPackage{Name: name, Path: path}
+			packages[path] = pkg
+			order = append(order, path)
+		}
+
+		columns := modelColumns{Name: t.Name(), Table: s.Table}
+		for _, field := range s.Fields {
+			// Relationships and ignored fields have no column
+			if field.DBName == "" {
+				continue
+			}
+			columns.Columns = append(columns.Columns, field)
+		}
+		pkg.Models = append(pkg.Models, columns)
+	}
+
+	return packages, order, nil
+}
+
+// render generates the formatted source of a package's columns file
+func render(pkg *modelPackage) ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by columngen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg.Name)
+	fmt.Fprintf(&b, "import \"konsultn-api/internal/shared/crud/types\"\n")
+
+	for _, m := range pkg.Models {
+		fmt.Fprintf(&b, "\n// %sTable is the table of %s\n", m.Name, m.Name)
+		fmt.Fprintf(&b, "const %sTable = %q\n", m.Name, m.Table)
+
+		fmt.Fprintf(&b, "\n// Columns of %s, qualified with its table\n", m.Name)
+		fmt.Fprintf(&b, "const (\n")
+		for _, field := range m.Columns {
+			fmt.Fprintf(&b, "\tCol%s%s types.Column = %q\n", m.Name, field.Name, m.Table+"."+field.DBName)
+		}
+		fmt.Fprintf(&b, ")\n")
+
+		fmt.Fprintf(&b, "\n// %sColumns gives field-style access to the columns of %s\n", m.Name, m.Name)
+		fmt.Fprintf(&b, "var %sColumns = struct {\n", m.Name)
+		for _, field := range m.Columns {
+			fmt.Fprintf(&b, "\t%s types.Column\n", field.Name)
+		}
+		fmt.Fprintf(&b, "}{\n")
+		for _, field := range m.Columns {
+			fmt.Fprintf(&b, "\t%s: Col%s%s,\n", field.Name, m.Name, field.Name)
+		}
+		fmt.Fprintf(&b, "}\n")
+	}
+
+	return format.Source(b.Bytes())
+}
+
+// findModule walks up from the working directory to the go.mod and returns its directory and module path
+func findModule() (string, string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					return dir, strings.TrimSpace(module), nil
+				}
+			}
+			return "", "", fmt.Errorf("no module directive in %s", filepath.Join(dir, "go.mod"))
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("go.mod not found")
+		}
+		dir = parent
+	}
+}
@@ -6,11 +6,12 @@ import (
 	"gorm.io/gorm/logger"
 	"konsultn-api/internal/config"
 	"konsultn-api/internal/db"
-	"konsultn-api/internal/domain/project/model"
-	"konsultn-api/internal/domain/task"
-	model2 "konsultn-api/internal/domain/team/model"
-	"konsultn-api/internal/domain/user"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/domain/team/service"
+	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/security"
 	"konsultn-api/pkg/firebase"
+	"konsultn-api/pkg/mailer"
 	"log"
 	"os"
 	"time"
@@ -28,26 +29,94 @@ func EnableGormSQLLogging(db *gorm.DB) {
 	)
 }
 
+//go:generate go run ./columngen
+
 func main() {
 	r := gin.Default()
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "Hello, Konsultn! Coming soon!"})
 	})
 
+	authConfig := security.ConfigFromEnv()
+	identityConfig := auth.ConfigFromEnv()
 	firebaseErr := firebase.InitFirebase()
 	if firebaseErr != nil {
-		print(firebaseErr.Error())
-		return
+		if authConfig.Verifier == security.VerifierFirebase || identityConfig.Provider == auth.ProviderFirebase {
+			print(firebaseErr.Error())
+			return
+		}
+		log.Printf("firebase is not configured: %v", firebaseErr)
 	}
 
 	connection, _ := db.InitDB()
 	//connection = connection.Debug()
-	errr := connection.AutoMigrate(&user.User{}, &model.Project{}, &task.Task{}, model2.Team{}, model2.TeamMember{}, model2.TeamInvitation{})
+	errr := db.Migrate(connection)
 	if errr != nil {
 		return
 	}
 
-	config.Setup(r, connection)
+	verifier, verifierErr := security.NewTokenVerifier(authConfig, firebase.AuthClient)
+	if verifierErr != nil {
+		print(verifierErr.Error())
+		return
+	}
+	middleware.SetTokenVerifier(security.WithRevocation(verifier, auth.NewSessionStore(connection)))
+	middleware.SetAccountGate(auth.NewAccountGate(connection))
+
+	teams := service.NewTeamService(connection)
+	middleware.SetAPIKeyVerifier(auth.NewAPIKeys(connection, teams))
+
+	identityProvider, providerErr := auth.NewIdentityProvider(identityConfig, connection, firebase.AuthClient, authConfig)
+	if providerErr != nil {
+		print(providerErr.Error())
+		return
+	}
+
+	mailConfig, mailConfigErr := mailer.ConfigFromEnv()
+	if mailConfigErr != nil {
+		print(mailConfigErr.Error())
+		return
+	}
+	mail, mailerErr := mailer.New(mailConfig)
+	if mailerErr != nil {
+		print(mailerErr.Error())
+		return
+	}
+
+	attempts, attemptsErr := auth.NewAttemptStore(identityConfig.AttemptStore, connection)
+	if attemptsErr != nil {
+		print(attemptsErr.Error())
+		return
+	}
+
+	var secrets *security.Cipher
+	if identityConfig.EncryptionKey != "" {
+		var cipherErr error
+		secrets, cipherErr = security.NewCipher(identityConfig.EncryptionKey)
+		if cipherErr != nil {
+			print(cipherErr.Error())
+			return
+		}
+	} else {
+		log.Printf("AUTH_ENCRYPTION_KEY is not set, two-factor authentication and social sign in are disabled")
+	}
+
+	socialProviders, socialErr := auth.OIDCConfigsFromEnv()
+	if socialErr != nil {
+		print(socialErr.Error())
+		return
+	}
+
+	config.Setup(r, connection, auth.Dependencies{
+		IdentityProvider:  identityProvider,
+		Mailer:            mail,
+		AppURL:            identityConfig.AppURL,
+		SecretCipher:      secrets,
+		SocialProviders:   socialProviders,
+		SocialRedirectURL: identityConfig.SocialRedirectURL,
+		TeamAdmins:        teams,
+		Attempts:          attempts,
+	})
 
 	/*
 	* Handle port and host from environment
@@ -128,10 +128,6 @@ const docTemplate = `{
                 "lastName": {
                     "type": "string"
                 },
-                "password": {
-                    "description": "raw password from user",
-                    "type": "string"
-                },
                 "phoneNumber": {
                     "type": "string"
                 },
@@ -13,13 +13,13 @@ import (
 	"konsultn-api/internal/domain/user"
 )
 
-func Setup(r *gin.Engine, db *gorm.DB) {
+func Setup(r *gin.Engine, db *gorm.DB, authDeps auth.Dependencies) {
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	apiGroup := r.Group("/api")
 	{
 		user.RegisterRoutes(apiGroup, db)
-		auth.RegisterRoutes(apiGroup, db)
+		auth.RegisterRoutes(apiGroup, db, authDeps)
 		task.RegisterRoutes(apiGroup, db)
 		project.RegisterRoutes(apiGroup, db)
 		team.RegisterRoutes(apiGroup, db)
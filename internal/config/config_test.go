@@ -0,0 +1,133 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"gorm.io/datatypes"
+	"konsultn-api/internal/config"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/domain/task"
+	"konsultn-api/internal/domain/team/enum"
+	"konsultn-api/internal/domain/user"
+	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/security"
+	"konsultn-api/internal/testkit"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// secret is stored in every sensitive column the test can reach, no response may carry it
+const secret = "do-not-render"
+
+// TestResponsesRenderViews calls the handlers of every domain through the API router
+// testkit.Router fails the test when one of them renders a sensitive model instead of a response view
+func TestResponsesRenderViews(t *testing.T) {
+	db := testkit.DB(t)
+	tokenConfig := security.Config{Verifier: security.VerifierHMAC, HMACSecret: "test-secret"}
+	provider, err := auth.NewIdentityProvider(auth.Config{Provider: auth.ProviderLocal}, db, nil, tokenConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := security.NewTokenVerifier(tokenConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	middleware.SetTokenVerifier(security.WithRevocation(verifier, auth.NewSessionStore(db)))
+	middleware.SetAccountGate(auth.NewAccountGate(db))
+
+	router := testkit.Router(t)
+	config.Setup(router, db, auth.Dependencies{IdentityProvider: provider})
+
+	call := func(method, path, token string, body interface{}) (int, string) {
+		t.Helper()
+
+		var buf bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&buf).Encode(body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if strings.Contains(w.Body.String(), secret) {
+			t.Errorf("%s %s rendered a secret: %s", method, path, w.Body.String())
+		}
+		return w.Code, w.Body.String()
+	}
+	signIn := func(email string, roles ...string) (*user.User, string) {
+		t.Helper()
+
+		if code, body := call(http.MethodPost, "/api/auth/register", "", map[string]string{"email": email, "password": "password123"}); code != http.StatusOK {
+			t.Fatalf("register %s: %d %s", email, code, body)
+		}
+		err := db.Model(&user.User{}).Where("email = ?", email).Updates(map[string]interface{}{
+			"status":             user.StatusActive,
+			"reset_token":        secret,
+			"verification_token": secret,
+			"social_id":          secret,
+			"two_factor_secret":  secret,
+			"roles":              datatypes.JSONSlice[string](append([]string{security.RoleFreelancer}, roles...)),
+		}).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		code, body := call(http.MethodPost, "/api/auth/login", "", map[string]string{"email": email, "password": "password123"})
+		var response struct {
+			IDToken string `json:"id_token"`
+		}
+		if code != http.StatusOK || json.Unmarshal([]byte(body), &response) != nil {
+			t.Fatalf("login %s: %d %s", email, code, body)
+		}
+		var account user.User
+		if err := db.Where("email = ?", email).First(&account).Error; err != nil {
+			t.Fatal(err)
+		}
+		return &account, response.IDToken
+	}
+
+	ada, token := signIn("ada@example.com")
+	grace, _ := signIn("grace@example.com")
+	_, adminToken := signIn("admin@example.com", security.RolePlatformAdmin)
+
+	f := testkit.NewFactory(t, db)
+	project := f.Project()
+	assigned := f.Task(project, func(task *task.Task) { task.AssigneeID = &ada.ID })
+	team := f.Team(ada)
+	f.Member(team, grace, enum.Member)
+
+	for _, c := range []struct {
+		method, path, token string
+		body                interface{}
+		want                int
+	}{
+		{http.MethodGet, "/api/users", token, nil, http.StatusOK},
+		{http.MethodGet, "/api/users/" + ada.ID, token, nil, http.StatusOK},
+		{http.MethodGet, "/api/users/" + grace.ID, token, nil, http.StatusOK},
+		{http.MethodGet, "/api/users/" + grace.ID, adminToken, nil, http.StatusOK},
+		{http.MethodGet, "/api/auth/sessions", token, nil, http.StatusOK},
+		{http.MethodGet, "/api/auth/identities", token, nil, http.StatusOK},
+		{http.MethodGet, "/api/auth/2fa", token, nil, http.StatusOK},
+		{http.MethodPost, "/api/auth/api-keys", token, map[string]interface{}{"name": "ci", "scopes": []string{security.ScopeReadTasks}}, http.StatusCreated},
+		{http.MethodGet, "/api/auth/api-keys", token, nil, http.StatusOK},
+		{http.MethodPost, "/api/tasks", token, map[string]string{"title": "Write", "description": "Docs", "assignee_id": ada.ID}, http.StatusOK},
+		{http.MethodGet, "/api/tasks/" + assigned.ID, token, nil, http.StatusOK},
+		{http.MethodPost, "/api/projects/", token, map[string]string{"name": "Launch"}, http.StatusCreated},
+		{http.MethodGet, "/api/projects/" + project.ID, token, nil, http.StatusOK},
+		{http.MethodPost, "/api/teams", token, map[string]string{"name": "Core", "slug": "core"}, http.StatusCreated},
+		{http.MethodGet, "/api/teams/" + team.ID, token, nil, http.StatusOK},
+		{http.MethodGet, "/api/teams", token, nil, http.StatusOK},
+	} {
+		if code, body := call(c.method, c.path, c.token, c.body); code != c.want {
+			t.Errorf("%s %s = %d %s, want %d", c.method, c.path, code, body, c.want)
+		}
+	}
+}
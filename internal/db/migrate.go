@@ -0,0 +1,83 @@
+package db
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/domain/project/model"
+	"konsultn-api/internal/domain/task"
+	model2 "konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/domain/user"
+)
+
+// Models lists every model managed by the migrations, in migration order
+func Models() []interface{} {
+	return []interface{}{
+		&user.User{},
+		&auth.Session{},
+		&auth.RecoveryCode{},
+		&auth.LinkedIdentity{},
+		&auth.APIKey{},
+		&auth.APIKeyEvent{},
+		&auth.AuthAttempt{},
+		&model.Project{},
+		&task.Task{},
+		&model2.Team{},
+		&model2.TeamMember{},
+		&model2.TeamInvitation{},
+	}
+}
+
+// Migrate creates or updates the tables of all models
+func Migrate(db *gorm.DB) error {
+	// Checked before AutoMigrate adds the verification columns
+	activate := predatesVerification(db)
+	if err := db.AutoMigrate(Models()...); err != nil {
+		return err
+	}
+	if activate {
+		if err := activateExistingUsers(db); err != nil {
+			return err
+		}
+	}
+	if err := dropPlaintextPasswords(db); err != nil {
+		return err
+	}
+	return dropStoredTokens(db)
+}
+
+// predatesVerification reports whether the users table exists without the email verification columns
+func predatesVerification(db *gorm.DB) bool {
+	return db.Migrator().HasTable(&user.User{}) && !db.Migrator().HasColumn(&user.User{}, user.ColUserVerificationToken.Name())
+}
+
+// activateExistingUsers activates the pending accounts created before email verification,
+// they got the pending status by default but were never sent a link to verify with
+func activateExistingUsers(db *gorm.DB) error {
+	return db.Model(&user.User{}).
+		Where(fmt.Sprintf("%s = ?", user.ColUserStatus), user.StatusPending).
+		UpdateColumn(user.ColUserStatus.Name(), user.StatusActive).Error
+}
+
+// dropPlaintextPasswords drops the password column users had before passwords were left to the identity provider,
+// it held them in plain text. It only runs once, as the column is gone afterwards
+func dropPlaintextPasswords(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&user.User{}, "password") {
+		return nil
+	}
+	return db.Migrator().DropColumn(&user.User{}, "password")
+}
+
+// dropStoredTokens drops the access_token and refresh_token columns users had before tokens were tracked per session,
+// they still hold the last tokens issued to each user. It only runs once, as the columns are gone afterwards
+func dropStoredTokens(db *gorm.DB) error {
+	for _, column := range []string{"access_token", "refresh_token"} {
+		if !db.Migrator().HasColumn(&user.User{}, column) {
+			continue
+		}
+		if err := db.Migrator().DropColumn(&user.User{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
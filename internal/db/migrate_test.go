@@ -0,0 +1,127 @@
+package db_test
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"konsultn-api/internal/db"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/domain/user"
+	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/security"
+	"konsultn-api/internal/testkit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// uidVerifier accepts every token as the principal whose UID is the token
+type uidVerifier struct{}
+
+func (uidVerifier) Verify(_ context.Context, token string) (*security.Principal, error) {
+	return &security.Principal{UID: token}, nil
+}
+
+func TestMigrateActivatesExistingUsers(t *testing.T) {
+	conn := testkit.DB(t)
+	f := testkit.NewFactory(t, conn)
+	existing := f.User(func(u *user.User) { u.Status = user.StatusPending })
+
+	// The users table as it was before email verification
+	for _, column := range []string{user.ColUserVerificationToken.Name(), user.ColUserVerificationExpiry.Name()} {
+		if err := conn.Migrator().DropColumn(&user.User{}, column); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	// The backfill only runs once, accounts created afterwards verify their email
+	created := f.User(func(u *user.User) { u.Status = user.StatusPending })
+	if err := db.Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	middleware.SetAccountGate(auth.NewAccountGate(conn))
+	t.Cleanup(func() { middleware.SetAccountGate(nil) })
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/gated", middleware.Authenticate(uidVerifier{}), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, tc := range []struct {
+		account *user.User
+		code    int
+	}{
+		{existing, http.StatusNoContent},
+		{created, http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/gated", nil)
+		req.Header.Set("Authorization", "Bearer "+tc.account.UID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tc.code {
+			t.Errorf("gated route for %s = %d %s, expected %d", tc.account.Email, w.Code, w.Body, tc.code)
+		}
+	}
+}
+
+func TestMigrateDropsPlaintextPasswords(t *testing.T) {
+	conn := testkit.DB(t)
+	f := testkit.NewFactory(t, conn)
+	account := f.User()
+
+	testkit.AddColumn(t, conn, user.UserTable, "password", "text")
+	if err := conn.Exec("UPDATE users SET password = ? WHERE id = ?", "hunter2", account.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	if conn.Migrator().HasColumn(&user.User{}, "password") {
+		t.Fatal("expected the password column to be dropped")
+	}
+
+	// The column is gone, so migrating again leaves the users alone
+	if err := db.Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	conn.Model(&user.User{}).Where("id = ?", account.ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected the user to be kept, got %d", count)
+	}
+}
+
+func TestMigrateDropsStoredTokens(t *testing.T) {
+	conn := testkit.DB(t)
+	account := testkit.NewFactory(t, conn).User()
+
+	for _, column := range []string{"access_token", "refresh_token"} {
+		testkit.AddColumn(t, conn, user.UserTable, column, "text")
+		if err := conn.Exec("UPDATE users SET "+column+" = ? WHERE id = ?", "token", account.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	for _, column := range []string{"access_token", "refresh_token"} {
+		if conn.Migrator().HasColumn(&user.User{}, column) {
+			t.Fatalf("expected the %s column to be dropped", column)
+		}
+	}
+
+	// The columns are gone, so migrating again leaves the users alone
+	if err := db.Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	conn.Model(&user.User{}).Where("id = ?", account.ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected the user to be kept, got %d", count)
+	}
+}
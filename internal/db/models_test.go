@@ -0,0 +1,30 @@
+package db_test
+
+import (
+	"encoding/json"
+	"errors"
+	"konsultn-api/internal/db"
+	"konsultn-api/internal/shared/sensitive"
+	"reflect"
+	"testing"
+)
+
+func TestSensitiveModelsFailToRender(t *testing.T) {
+	sensitive.SetStrict(true)
+
+	for _, model := range db.Models() {
+		name := reflect.TypeOf(model).Elem().Name()
+		if len(sensitive.Keys(reflect.TypeOf(model))) == 0 {
+			continue
+		}
+
+		// Tags alone do nothing, the model has to marshal itself through sensitive.Marshal
+		if _, ok := model.(json.Marshaler); !ok {
+			t.Errorf("%s has sensitive fields but does not implement json.Marshaler", name)
+			continue
+		}
+		if _, err := json.Marshal(model); !errors.Is(err, sensitive.ErrRendered) {
+			t.Errorf("expected rendering %s to fail with ErrRendered in strict mode, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/security"
+)
+
+// AccountGate lets principals use the API according to the status of their account in the users table
+type AccountGate struct {
+	repo *user2.Repository[user2.User]
+}
+
+func NewAccountGate(db *gorm.DB) *AccountGate {
+	return &AccountGate{repo: user2.NewRepository(db)}
+}
+
+// CheckAccount rejects principals without an active account, principals without a users row are inactive
+func (g *AccountGate) CheckAccount(ctx context.Context, principal *security.Principal) error {
+	account, err := g.repo.WithContext(ctx).FindFirstBy("uid", principal.UID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return middleware.ErrAccountInactive
+		}
+		return err
+	}
+	return statusError(account.Status)
+}
+
+// statusError maps the status of an account to the error of the account gate, nil for active accounts
+func statusError(status user2.Status) error {
+	switch status {
+	case user2.StatusActive:
+		return nil
+	case user2.StatusPending:
+		return middleware.ErrAccountUnverified
+	default:
+		return middleware.ErrAccountInactive
+	}
+}
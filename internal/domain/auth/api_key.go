@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared"
+	"konsultn-api/internal/shared/crud"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/shared/security"
+	"konsultn-api/internal/shared/sensitive"
+	"log"
+	"time"
+)
+
+const (
+	// apiKeyLimit is how many active API keys a user may hold
+	apiKeyLimit = 25
+	// apiKeyDefaultTTL is the lifetime of API keys created without an expiry
+	apiKeyDefaultTTL = 90 * 24 * time.Hour
+	// apiKeyMaxTTL is the longest lifetime of an API key
+	apiKeyMaxTTL = 365 * 24 * time.Hour
+	// apiKeyUsageInterval is how often the use of an API key is recorded, so busy keys do not write on every request
+	apiKeyUsageInterval = time.Minute
+)
+
+// Actions recorded in the audit trail of API keys
+const (
+	APIKeyCreated  = "created"
+	APIKeyUsed     = "used"
+	APIKeyRejected = "rejected"
+	APIKeyRevoked  = "revoked"
+)
+
+// Errors returned for rejected API key requests
+var (
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrAPIKeyLimit        = errors.New("too many active api keys, revoke one first")
+	ErrInvalidScope       = errors.New("unknown or unsupported scope")
+	ErrInvalidKeyExpiry   = errors.New("api keys must expire within a year")
+	ErrTeamKeyUnavailable = errors.New("team api keys are not configured")
+	ErrNotTeamAdmin       = errors.New("only team admins can manage the api keys of a team")
+)
+
+// TeamAdmins tells who administers a team, API keys of a team are managed by its admins
+type TeamAdmins interface {
+	CanUpdateOrDeleteTeam(teamId string, userId string) bool
+}
+
+// APIKey lets an integration call the API as the user who created it, limited to its scopes
+// Keys scoped to a team may only access that team. Only a digest of the secret is stored
+type APIKey struct {
+	shared.ULID `gorm:"embedded"`
+	UserID      string                      `gorm:"not null;index"`
+	TeamID      *string                     `gorm:"type:varchar(26);index"`
+	Name        string                      `gorm:"size:100;not null"`
+	Prefix      string                      `gorm:"size:16;not null"`
+	KeyHash     string                      `gorm:"size:64;not null;uniqueIndex" json:"-" sensitive:"true"`
+	Scopes      datatypes.JSONSlice[string] `gorm:"type:jsonb" swaggertype:"array,string"`
+	ExpiresAt   time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time `gorm:"index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// MarshalJSON leaves the sensitive fields out, or fails in strict mode, as keys are rendered as APIKeyResponse
+func (m APIKey) MarshalJSON() ([]byte, error) {
+	type plain APIKey
+	return sensitive.Marshal(plain(m))
+}
+
+// APIKeyEvent is an entry of the audit trail of an API key
+type APIKeyEvent struct {
+	shared.ULID `gorm:"embedded"`
+	APIKeyID    string `gorm:"not null;index"`
+	// ActorID is the user who created or revoked the key, or its owner when it was used
+	ActorID   string `gorm:"not null"`
+	Action    string `gorm:"size:20;not null"`
+	IPAddress string `gorm:"size:64"`
+	CreatedAt time.Time
+}
+
+// APIKeys manages API keys and verifies them as bearer tokens
+type APIKeys struct {
+	keys   *crud.Repository[APIKey, string]
+	events *crud.Repository[APIKeyEvent, string]
+	users  *user2.Repository[user2.User]
+	teams  TeamAdmins
+}
+
+// NewAPIKeys returns the API keys of the users of db, team keys cannot be managed without teams
+func NewAPIKeys(db *gorm.DB, teams TeamAdmins) *APIKeys {
+	return &APIKeys{
+		keys:   crud.NewRepository[APIKey, string](db),
+		events: crud.NewRepository[APIKeyEvent, string](db),
+		users:  user2.NewRepository(db),
+		teams:  teams,
+	}
+}
+
+// Create issues an API key for a user, scoped to a team when teamID is set
+// The secret is only returned here, a nil expiry defaults to apiKeyDefaultTTL
+func (a *APIKeys) Create(ctx context.Context, userID, teamID, name string, scopes []string, expiresAt *time.Time, ip string) (*APIKey, string, error) {
+	if len(scopes) == 0 {
+		return nil, "", ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !security.IsScope(scope) || (teamID != "" && !security.IsTeamScope(scope)) {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+
+	now := time.Now()
+	expiry := now.Add(apiKeyDefaultTTL)
+	if expiresAt != nil {
+		if !expiresAt.After(now) || expiresAt.After(now.Add(apiKeyMaxTTL)) {
+			return nil, "", ErrInvalidKeyExpiry
+		}
+		expiry = *expiresAt
+	}
+
+	if teamID != "" {
+		if err := a.checkTeamAdmin(teamID, userID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	active, err := a.active(ctx).Where(ColAPIKeyUserID, userID).Count()
+	if err != nil {
+		return nil, "", err
+	}
+	if active >= apiKeyLimit {
+		return nil, "", ErrAPIKeyLimit
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	raw := security.APIKeyPrefix + secret
+
+	key := &APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    raw[:len(security.APIKeyPrefix)+6],
+		KeyHash:   digest(raw),
+		Scopes:    scopes,
+		ExpiresAt: expiry,
+	}
+	if teamID != "" {
+		key.TeamID = &teamID
+	}
+	if _, err := a.keys.WithContext(ctx).Save(key); err != nil {
+		return nil, "", fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	a.record(ctx, key.ID, userID, APIKeyCreated, ip)
+	return key, raw, nil
+}
+
+// List returns the API keys a user created, active ones included, most recent first
+func (a *APIKeys) List(ctx context.Context, userID string) ([]APIKey, error) {
+	return a.keys.WithContext(ctx).Query().
+		Where(ColAPIKeyUserID, userID).
+		OrderBy(ColAPIKeyCreatedAt, "DESC").
+		All()
+}
+
+// ListTeam returns the API keys of a team to one of its admins, most recent first
+func (a *APIKeys) ListTeam(ctx context.Context, teamID, userID string) ([]APIKey, error) {
+	if err := a.checkTeamAdmin(teamID, userID); err != nil {
+		return nil, err
+	}
+	return a.keys.WithContext(ctx).Query().
+		Where(ColAPIKeyTeamID, teamID).
+		OrderBy(ColAPIKeyCreatedAt, "DESC").
+		All()
+}
+
+// Find returns an API key the user manages, the keys they created and those of the teams they administer
+func (a *APIKeys) Find(ctx context.Context, id, userID string) (*APIKey, error) {
+	key, err := a.keys.WithContext(ctx).FindFirstBy(ColAPIKeyID.Name(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	if key.UserID == userID {
+		return key, nil
+	}
+	if key.TeamID != nil && a.teams != nil && a.teams.CanUpdateOrDeleteTeam(*key.TeamID, userID) {
+		return key, nil
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+// Revoke revokes an API key, revoking a revoked key does nothing
+func (a *APIKeys) Revoke(ctx context.Context, key *APIKey, actorID, ip string) error {
+	result := a.keys.WithContext(ctx).GetDB().
+		Model(&APIKey{}).
+		Where(fmt.Sprintf("%s = ? AND %s IS NULL", ColAPIKeyID, ColAPIKeyRevokedAt), key.ID).
+		Update(ColAPIKeyRevokedAt.Name(), time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		a.record(ctx, key.ID, actorID, APIKeyRevoked, ip)
+	}
+	return nil
+}
+
+// Events returns the audit trail of an API key, most recent first
+func (a *APIKeys) Events(ctx context.Context, key *APIKey) ([]APIKeyEvent, error) {
+	return a.events.WithContext(ctx).Query().
+		Where(ColAPIKeyEventAPIKeyID, key.ID).
+		OrderBy(ColAPIKeyEventCreatedAt, "DESC").
+		All()
+}
+
+// Verify resolves the principal of an API key, it acts as the user who created it
+// Uses of revoked and expired keys are recorded as rejected
+func (a *APIKeys) Verify(ctx context.Context, token string) (*security.Principal, error) {
+	key, err := a.keys.WithContext(ctx).FindFirstBy(ColAPIKeyKeyHash.Name(), digest(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: unknown api key", security.ErrInvalidToken)
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || now.After(key.ExpiresAt) {
+		a.record(ctx, key.ID, key.UserID, APIKeyRejected, "")
+		return nil, fmt.Errorf("%w: api key is revoked or expired", security.ErrInvalidToken)
+	}
+
+	owner, err := a.users.WithContext(ctx).FindFirstBy(user2.ColUserID.Name(), key.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: api key has no owner", security.ErrInvalidToken)
+	}
+
+	a.touch(ctx, key, now)
+
+	principal := &security.Principal{
+		UID:      owner.UID,
+		UserID:   owner.ID,
+		Email:    owner.Email,
+		Roles:    owner.Roles,
+		Claims:   map[string]interface{}{},
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}
+	if key.TeamID != nil {
+		principal.TeamID = *key.TeamID
+	}
+	return principal, nil
+}
+
+// touch records the use of a key at most once per apiKeyUsageInterval
+func (a *APIKeys) touch(ctx context.Context, key *APIKey, now time.Time) {
+	result := a.keys.WithContext(ctx).GetDB().
+		Model(&APIKey{}).
+		Where(fmt.Sprintf("%s = ? AND (%s IS NULL OR %s < ?)", ColAPIKeyID, ColAPIKeyLastUsedAt, ColAPIKeyLastUsedAt), key.ID, now.Add(-apiKeyUsageInterval)).
+		Update(ColAPIKeyLastUsedAt.Name(), now)
+	if result.Error == nil && result.RowsAffected > 0 {
+		a.record(ctx, key.ID, key.UserID, APIKeyUsed, "")
+	}
+}
+
+// record adds an entry to the audit trail, failures are not fatal to the action they record
+func (a *APIKeys) record(ctx context.Context, keyID, actorID, action, ip string) {
+	_, err := a.events.WithContext(ctx).Save(&APIKeyEvent{
+		APIKeyID:  keyID,
+		ActorID:   actorID,
+		Action:    action,
+		IPAddress: ip,
+	})
+	if err != nil {
+		log.Printf("failed to record api key event %s of %s: %v", action, keyID, err)
+	}
+}
+
+func (a *APIKeys) checkTeamAdmin(teamID, userID string) error {
+	if a.teams == nil {
+		return ErrTeamKeyUnavailable
+	}
+	if !a.teams.CanUpdateOrDeleteTeam(teamID, userID) {
+		return ErrNotTeamAdmin
+	}
+	return nil
+}
+
+func (a *APIKeys) active(ctx context.Context) types.QueryBuilder[APIKey] {
+	return a.keys.WithContext(ctx).Query().
+		WhereNull(ColAPIKeyRevokedAt).
+		WhereGT(ColAPIKeyExpiresAt, time.Now())
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"konsultn-api/internal/middleware"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	TeamID    string     `json:"team_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	TeamID     *string    `json:"team_id"`
+	UserID     string     `json:"user_id"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type APIKeyEventResponse struct {
+	Action    string    `json:"action"`
+	ActorID   string    `json:"actor_id"`
+	IPAddress string    `json:"ip_address"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListAPIKeys lists the API keys of the caller, or those of a team they administer with ?team_id=
+func (h *Handler) ListAPIKeys(ctx *gin.Context) {
+	userID := middleware.CurrentUserID(ctx)
+
+	var keys []APIKey
+	var err error
+	if teamID := ctx.Query("team_id"); teamID != "" {
+		keys, err = h.apiKeys.ListTeam(ctx, teamID, userID)
+	} else {
+		keys, err = h.apiKeys.List(ctx, userID)
+	}
+	if err != nil {
+		apiKeyError(ctx, err)
+		return
+	}
+
+	response := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, toAPIKeyResponse(&key))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CreateAPIKey issues an API key acting as the caller, the key itself is only part of this response
+func (h *Handler) CreateAPIKey(ctx *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	key, raw, err := h.apiKeys.Create(ctx, middleware.CurrentUserID(ctx), req.TeamID, req.Name, scopes, req.ExpiresAt, ctx.ClientIP())
+	if err != nil {
+		apiKeyError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"api_key": toAPIKeyResponse(key),
+		"key":     raw,
+		"message": "Store this key now, it cannot be shown again",
+	})
+}
+
+// RevokeAPIKey revokes an API key of the caller or of a team they administer
+func (h *Handler) RevokeAPIKey(ctx *gin.Context) {
+	userID := middleware.CurrentUserID(ctx)
+
+	key, err := h.apiKeys.Find(ctx, ctx.Param("id"), userID)
+	if err != nil {
+		apiKeyError(ctx, err)
+		return
+	}
+
+	if err := h.apiKeys.Revoke(ctx, key, userID, ctx.ClientIP()); err != nil {
+		apiKeyError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "api key revoked"})
+}
+
+// ListAPIKeyEvents returns the audit trail of an API key of the caller or of a team they administer
+func (h *Handler) ListAPIKeyEvents(ctx *gin.Context) {
+	key, err := h.apiKeys.Find(ctx, ctx.Param("id"), middleware.CurrentUserID(ctx))
+	if err != nil {
+		apiKeyError(ctx, err)
+		return
+	}
+
+	events, err := h.apiKeys.Events(ctx, key)
+	if err != nil {
+		apiKeyError(ctx, err)
+		return
+	}
+
+	response := make([]APIKeyEventResponse, 0, len(events))
+	for _, event := range events {
+		response = append(response, APIKeyEventResponse{
+			Action:    event.Action,
+			ActorID:   event.ActorID,
+			IPAddress: event.IPAddress,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func toAPIKeyResponse(key *APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		TeamID:     key.TeamID,
+		UserID:     key.UserID,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func apiKeyError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidScope), errors.Is(err, ErrInvalidKeyExpiry):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotTeamAdmin):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAPIKeyNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAPIKeyLimit):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTeamKeyUnavailable):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "api key request failed"})
+	}
+}
@@ -0,0 +1,132 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/shared/security"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// teamAdmins maps the teams of a test to the users administering them
+type teamAdmins map[string]string
+
+func (a teamAdmins) CanUpdateOrDeleteTeam(teamId string, userId string) bool {
+	return a[teamId] == userId
+}
+
+// signIn registers an active account and returns its user ID and ID token
+func (s *server) signIn(email string) (string, string) {
+	s.t.Helper()
+
+	userID := s.register(email, "password123")
+	s.activate(email)
+	return userID, s.login(email, "password123")["id_token"].(string)
+}
+
+// createAPIKey creates an API key through the API and returns its ID and secret
+func (s *server) createAPIKey(token string, body map[string]interface{}) (string, string) {
+	s.t.Helper()
+
+	code, response := s.call(http.MethodPost, "/api/auth/api-keys", token, body)
+	if code != http.StatusCreated {
+		s.t.Fatalf("create api key: %d %v", code, response)
+	}
+	return response["api_key"].(map[string]interface{})["id"].(string), response["key"].(string)
+}
+
+// apiKeyActions returns the actions recorded for an API key, sorted
+func (s *server) apiKeyActions(keyID string) []string {
+	s.t.Helper()
+
+	var events []auth.APIKeyEvent
+	if err := s.db.Where("api_key_id = ?", keyID).Find(&events).Error; err != nil {
+		s.t.Fatal(err)
+	}
+	actions := make([]string, 0, len(events))
+	for _, event := range events {
+		actions = append(actions, event.Action)
+	}
+	slices.Sort(actions)
+	return actions
+}
+
+func TestAPIKeys(t *testing.T) {
+	s := newServer(t, auth.Dependencies{})
+	keys := auth.NewAPIKeys(s.db, nil)
+	ctx := context.Background()
+
+	userID, token := s.signIn("ada@example.com")
+	if code, response := s.call(http.MethodPost, "/api/auth/api-keys", token, map[string]interface{}{"name": "ci", "scopes": []string{"read:nothing"}}); code != http.StatusBadRequest {
+		t.Fatalf("create with an unknown scope = %d %v", code, response)
+	}
+	keyID, secret := s.createAPIKey(token, map[string]interface{}{"name": "ci", "scopes": []string{security.ScopeReadTasks}})
+
+	principal, err := keys.Verify(ctx, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.UserID != userID || principal.APIKeyID != keyID || !slices.Equal(principal.Scopes, []string{security.ScopeReadTasks}) {
+		t.Fatalf("unexpected principal %+v", principal)
+	}
+	if _, err := keys.Verify(ctx, secret+"x"); !errors.Is(err, security.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for an unknown key, got %v", err)
+	}
+
+	// Keys are only managed by the users who created them
+	_, otherToken := s.signIn("grace@example.com")
+	if code, response := s.call(http.MethodDelete, "/api/auth/api-keys/"+keyID, otherToken, nil); code != http.StatusNotFound {
+		t.Fatalf("revoking the key of another user = %d %v", code, response)
+	}
+	if code, response := s.call(http.MethodDelete, "/api/auth/api-keys/"+keyID, token, nil); code != http.StatusOK {
+		t.Fatalf("revoke = %d %v", code, response)
+	}
+
+	if _, err := keys.Verify(ctx, secret); !errors.Is(err, security.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for a revoked key, got %v", err)
+	}
+	want := []string{auth.APIKeyCreated, auth.APIKeyRejected, auth.APIKeyRevoked, auth.APIKeyUsed}
+	if actions := s.apiKeyActions(keyID); !slices.Equal(actions, want) {
+		t.Fatalf("expected the events %v, got %v", want, actions)
+	}
+}
+
+func TestTeamAPIKeys(t *testing.T) {
+	admins := teamAdmins{}
+	s := newServer(t, auth.Dependencies{TeamAdmins: admins})
+
+	adminID, token := s.signIn("ada@example.com")
+	otherID, otherToken := s.signIn("grace@example.com")
+	admins["team"] = adminID
+
+	if code, response := s.call(http.MethodPost, "/api/auth/api-keys", token, map[string]interface{}{"name": "report", "scopes": []string{security.ScopeReadTasks}, "team_id": "team"}); code != http.StatusBadRequest {
+		t.Fatalf("create a team key with a user scope = %d %v", code, response)
+	}
+	if code, response := s.call(http.MethodPost, "/api/auth/api-keys", otherToken, map[string]interface{}{"name": "report", "scopes": []string{security.ScopeReadTeams}, "team_id": "team"}); code != http.StatusForbidden {
+		t.Fatalf("create a team key without administering the team = %d %v", code, response)
+	}
+	keyID, secret := s.createAPIKey(token, map[string]interface{}{"name": "report", "scopes": []string{security.ScopeReadTeams}, "team_id": "team"})
+
+	principal, err := auth.NewAPIKeys(s.db, admins).Verify(context.Background(), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.TeamID != "team" {
+		t.Fatalf("expected the key to be scoped to the team, got %+v", principal)
+	}
+
+	if code, response := s.call(http.MethodGet, "/api/auth/api-keys?team_id=team", otherToken, nil); code != http.StatusForbidden {
+		t.Fatalf("listing the keys of a team without administering it = %d %v", code, response)
+	}
+	if code, response := s.call(http.MethodDelete, "/api/auth/api-keys/"+keyID, otherToken, nil); code != http.StatusNotFound {
+		t.Fatalf("revoking a team key without administering the team = %d %v", code, response)
+	}
+
+	// Admins manage the keys of their team, whoever created them
+	admins["team"] = otherID
+	if code, response := s.call(http.MethodDelete, "/api/auth/api-keys/"+keyID, otherToken, nil); code != http.StatusOK {
+		t.Fatalf("revoking a team key as its new admin = %d %v", code, response)
+	}
+}
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"sync"
+	"time"
+)
+
+// Attempt stores selectable by configuration
+const (
+	AttemptStoreMemory   = "memory"
+	AttemptStorePostgres = "postgres"
+)
+
+// Attempts counts the recent attempts of a key, like the failed sign ins of an account
+type Attempts struct {
+	Count  int
+	LastAt time.Time
+}
+
+// AttemptStore counts attempts per key, attempts are forgotten once a key is idle for longer than its window
+type AttemptStore interface {
+	// Get returns the attempts of key, the zero value when there are none
+	Get(ctx context.Context, key string) (Attempts, error)
+	// Record counts an attempt of key and returns its attempts, counting restarts when the last one is older than window
+	Record(ctx context.Context, key string, window time.Duration) (Attempts, error)
+	// Reset forgets the attempts of key
+	Reset(ctx context.Context, key string) error
+}
+
+// NewAttemptStore builds the store selected by the configuration
+// Memory stores count per instance of the API, instances sharing a database count together with the postgres store
+func NewAttemptStore(kind string, db *gorm.DB) (AttemptStore, error) {
+	switch kind {
+	case AttemptStoreMemory:
+		return NewMemoryAttemptStore(), nil
+	case AttemptStorePostgres:
+		return NewDBAttemptStore(db), nil
+	default:
+		return nil, fmt.Errorf("unknown attempt store '%s'", kind)
+	}
+}
+
+// MemoryAttemptStore counts attempts in memory, it forgets keys idle for longer than their window
+type MemoryAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]*memoryAttempts
+	swept    time.Time
+}
+
+type memoryAttempts struct {
+	Attempts
+	window time.Duration
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{attempts: make(map[string]*memoryAttempts), swept: time.Now()}
+}
+
+func (s *MemoryAttemptStore) Get(_ context.Context, key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.attempts[key]
+	if !ok || time.Since(entry.LastAt) > entry.window {
+		return Attempts{}, nil
+	}
+	return entry.Attempts, nil
+}
+
+func (s *MemoryAttemptStore) Record(_ context.Context, key string, window time.Duration) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.swept) > time.Minute {
+		for k, entry := range s.attempts {
+			if now.Sub(entry.LastAt) > entry.window {
+				delete(s.attempts, k)
+			}
+		}
+		s.swept = now
+	}
+
+	entry, ok := s.attempts[key]
+	if !ok || now.Sub(entry.LastAt) > entry.window {
+		entry = &memoryAttempts{}
+		s.attempts[key] = entry
+	}
+	entry.Count++
+	entry.LastAt = now
+	entry.window = window
+	return entry.Attempts, nil
+}
+
+func (s *MemoryAttemptStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// AuthAttempt is the row of a key of the DBAttemptStore
+type AuthAttempt struct {
+	Key       string `gorm:"primaryKey;size:100"`
+	Count     int    `gorm:"not null"`
+	LastAt    time.Time
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// DBAttemptStore counts attempts in the database, so every instance of the API shares them
+// Each instance deletes the expired rows now and then
+type DBAttemptStore struct {
+	db    *gorm.DB
+	mu    sync.Mutex
+	swept time.Time
+}
+
+func NewDBAttemptStore(db *gorm.DB) *DBAttemptStore {
+	return &DBAttemptStore{db: db, swept: time.Now()}
+}
+
+func (s *DBAttemptStore) Get(ctx context.Context, key string) (Attempts, error) {
+	var row AuthAttempt
+	err := s.db.WithContext(ctx).Where("key = ? AND expires_at > ?", key, time.Now()).Take(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Attempts{}, nil
+		}
+		return Attempts{}, err
+	}
+	return Attempts{Count: row.Count, LastAt: row.LastAt}, nil
+}
+
+// Record counts the attempt with a single upsert, so concurrent attempts of instances are all counted
+func (s *DBAttemptStore) Record(ctx context.Context, key string, window time.Duration) (Attempts, error) {
+	now := time.Now()
+	s.sweep(ctx, now)
+	row := AuthAttempt{Key: key, Count: 1, LastAt: now, ExpiresAt: now.Add(window)}
+
+	err := s.db.WithContext(ctx).Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "count"}, Value: gorm.Expr("CASE WHEN auth_attempts.expires_at <= ? THEN 1 ELSE auth_attempts.count + 1 END", now)},
+				{Column: clause.Column{Name: "last_at"}, Value: now},
+				{Column: clause.Column{Name: "expires_at"}, Value: now.Add(window)},
+			},
+		},
+		clause.Returning{},
+	).Create(&row).Error
+	if err != nil {
+		return Attempts{}, err
+	}
+	return Attempts{Count: row.Count, LastAt: row.LastAt}, nil
+}
+
+func (s *DBAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("key = ?", key).Delete(&AuthAttempt{}).Error
+}
+
+func (s *DBAttemptStore) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.swept) < 10*time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.swept = now
+	s.mu.Unlock()
+
+	if err := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&AuthAttempt{}).Error; err != nil {
+		log.Printf("failed to delete expired auth attempts: %v", err)
+	}
+}
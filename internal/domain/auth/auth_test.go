@@ -0,0 +1,194 @@
+package auth_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/domain/user"
+	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/security"
+	"konsultn-api/internal/testkit"
+	"konsultn-api/pkg/mailer"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tokenConfig signs and verifies the tokens of the local identity provider in tests
+var tokenConfig = security.Config{Verifier: security.VerifierHMAC, HMACSecret: "test-secret"}
+
+// mailbox records the emails sent by the auth handlers
+type mailbox struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *mailbox) Send(_ context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// link waits for the last email with the subject and returns the token of the link it carries
+// Some emails are sent in the background, so it waits for them a short while
+func (m *mailbox) link(t *testing.T, subject string) string {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		m.mu.Lock()
+		for i := len(m.messages) - 1; i >= 0; i-- {
+			if m.messages[i].Subject == subject {
+				body := m.messages[i].Body
+				m.mu.Unlock()
+				match := linkToken.FindStringSubmatch(body)
+				if match == nil {
+					t.Fatalf("no link in %q", body)
+				}
+				return match[1]
+			}
+		}
+		m.mu.Unlock()
+	}
+	t.Fatalf("no email %q was sent", subject)
+	return ""
+}
+
+// linkToken matches the token of links in auth emails
+var linkToken = regexp.MustCompile(`token=([\w-]+)`)
+
+// server serves the auth routes backed by the local identity provider and a test database
+type server struct {
+	t        *testing.T
+	db       *gorm.DB
+	router   *gin.Engine
+	provider auth.IdentityProvider
+	verifier security.TokenVerifier
+}
+
+// newServer registers the auth routes with the given dependencies, the identity provider is always the local one
+func newServer(t *testing.T, deps auth.Dependencies) *server {
+	t.Helper()
+
+	db := testkit.DB(t)
+	provider, err := auth.NewIdentityProvider(auth.Config{Provider: auth.ProviderLocal}, db, nil, tokenConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := security.NewTokenVerifier(tokenConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	middleware.SetTokenVerifier(security.WithRevocation(verifier, auth.NewSessionStore(db)))
+	middleware.SetAccountGate(auth.NewAccountGate(db))
+
+	deps.IdentityProvider = provider
+	router := testkit.Router(t)
+	auth.RegisterRoutes(router.Group("/api"), db, deps)
+	return &server{t: t, db: db, router: router, provider: provider, verifier: verifier}
+}
+
+// call sends a JSON request and decodes the JSON object it responds with
+func (s *server) call(method, path, token string, body interface{}) (int, map[string]interface{}) {
+	s.t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "auth-test")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	response := map[string]interface{}{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	return w.Code, response
+}
+
+// register signs up an account through the API and returns its user ID
+func (s *server) register(email, password string) string {
+	s.t.Helper()
+
+	code, response := s.call(http.MethodPost, "/api/auth/register", "", map[string]string{"email": email, "password": password})
+	if code != http.StatusOK {
+		s.t.Fatalf("register %s: %d %v", email, code, response)
+	}
+	return response["user"].(map[string]interface{})["id"].(string)
+}
+
+// activate marks the email of an account as verified, as opening the emailed link does
+func (s *server) activate(email string) {
+	s.t.Helper()
+
+	if err := s.db.Model(&user.User{}).Where("email = ?", email).Update("status", user.StatusActive).Error; err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// enableTwoFactor enrolls the caller of the token in two-factor authentication and returns its TOTP secret
+func (s *server) enableTwoFactor(token string) string {
+	s.t.Helper()
+
+	code, enrollment := s.call(http.MethodPost, "/api/auth/2fa/enroll", token, nil)
+	if code != http.StatusOK {
+		s.t.Fatalf("enroll two-factor authentication: %d %v", code, enrollment)
+	}
+	secret := enrollment["secret"].(string)
+	if code, response := s.call(http.MethodPost, "/api/auth/2fa/enable", token, map[string]string{"code": totp(secret, 0)}); code != http.StatusOK {
+		s.t.Fatalf("enable two-factor authentication: %d %v", code, response)
+	}
+	return secret
+}
+
+// testCipher returns a cipher with a fixed key, for the TOTP secrets and social sign in state of tests
+func testCipher(t *testing.T) *security.Cipher {
+	t.Helper()
+
+	cipher, err := security.NewCipher(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cipher
+}
+
+// totp computes the RFC 6238 code of the secret, steps time steps of 30 seconds away from now
+func totp(secret string, steps int64) string {
+	key, _ := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(time.Now().Unix()/30+steps))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0xf
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+// login signs in and returns the response, failing the test unless it succeeds
+func (s *server) login(email, password string) map[string]interface{} {
+	s.t.Helper()
+
+	code, response := s.call(http.MethodPost, "/api/auth/login", "", map[string]string{"email": email, "password": password})
+	if code != http.StatusOK {
+		s.t.Fatalf("login %s: %d %v", email, code, response)
+	}
+	return response
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"context"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared/security"
+)
+
+// userClaims are the custom claims of the tokens of a user
+func userClaims(user *user2.User) map[string]interface{} {
+	roles := []string(user.Roles)
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return map[string]interface{}{
+		security.ClaimUserID: user.ID,
+		security.ClaimRoles:  roles,
+	}
+}
+
+// syncClaims sets the claims of a user at the identity provider from the users table
+func (h *Handler) syncClaims(ctx context.Context, user *user2.User) error {
+	return h.provider.SetClaims(ctx, user.UID, userClaims(user))
+}
@@ -0,0 +1,212 @@
+// Code generated by columngen. DO NOT EDIT.
+
+package auth
+
+import "konsultn-api/internal/shared/crud/types"
+
+// SessionTable is the table of Session
+const SessionTable = "sessions"
+
+// Columns of Session, qualified with its table
+const (
+	ColSessionID               types.Column = "sessions.id"
+	ColSessionUserID           types.Column = "sessions.user_id"
+	ColSessionUID              types.Column = "sessions.uid"
+	ColSessionAuthTime         types.Column = "sessions.auth_time"
+	ColSessionRefreshTokenHash types.Column = "sessions.refresh_token_hash"
+	ColSessionUserAgent        types.Column = "sessions.user_agent"
+	ColSessionIPAddress        types.Column = "sessions.ip_address"
+	ColSessionLastUsedAt       types.Column = "sessions.last_used_at"
+	ColSessionRevokedAt        types.Column = "sessions.revoked_at"
+	ColSessionCreatedAt        types.Column = "sessions.created_at"
+	ColSessionUpdatedAt        types.Column = "sessions.updated_at"
+)
+
+// SessionColumns gives field-style access to the columns of Session
+var SessionColumns = struct {
+	ID               types.Column
+	UserID           types.Column
+	UID              types.Column
+	AuthTime         types.Column
+	RefreshTokenHash types.Column
+	UserAgent        types.Column
+	IPAddress        types.Column
+	LastUsedAt       types.Column
+	RevokedAt        types.Column
+	CreatedAt        types.Column
+	UpdatedAt        types.Column
+}{
+	ID:               ColSessionID,
+	UserID:           ColSessionUserID,
+	UID:              ColSessionUID,
+	AuthTime:         ColSessionAuthTime,
+	RefreshTokenHash: ColSessionRefreshTokenHash,
+	UserAgent:        ColSessionUserAgent,
+	IPAddress:        ColSessionIPAddress,
+	LastUsedAt:       ColSessionLastUsedAt,
+	RevokedAt:        ColSessionRevokedAt,
+	CreatedAt:        ColSessionCreatedAt,
+	UpdatedAt:        ColSessionUpdatedAt,
+}
+
+// RecoveryCodeTable is the table of RecoveryCode
+const RecoveryCodeTable = "recovery_codes"
+
+// Columns of RecoveryCode, qualified with its table
+const (
+	ColRecoveryCodeID        types.Column = "recovery_codes.id"
+	ColRecoveryCodeUserID    types.Column = "recovery_codes.user_id"
+	ColRecoveryCodeCodeHash  types.Column = "recovery_codes.code_hash"
+	ColRecoveryCodeUsedAt    types.Column = "recovery_codes.used_at"
+	ColRecoveryCodeCreatedAt types.Column = "recovery_codes.created_at"
+)
+
+// RecoveryCodeColumns gives field-style access to the columns of RecoveryCode
+var RecoveryCodeColumns = struct {
+	ID        types.Column
+	UserID    types.Column
+	CodeHash  types.Column
+	UsedAt    types.Column
+	CreatedAt types.Column
+}{
+	ID:        ColRecoveryCodeID,
+	UserID:    ColRecoveryCodeUserID,
+	CodeHash:  ColRecoveryCodeCodeHash,
+	UsedAt:    ColRecoveryCodeUsedAt,
+	CreatedAt: ColRecoveryCodeCreatedAt,
+}
+
+// LinkedIdentityTable is the table of LinkedIdentity
+const LinkedIdentityTable = "linked_identities"
+
+// Columns of LinkedIdentity, qualified with its table
+const (
+	ColLinkedIdentityID        types.Column = "linked_identities.id"
+	ColLinkedIdentityUserID    types.Column = "linked_identities.user_id"
+	ColLinkedIdentityProvider  types.Column = "linked_identities.provider"
+	ColLinkedIdentitySubject   types.Column = "linked_identities.subject"
+	ColLinkedIdentityEmail     types.Column = "linked_identities.email"
+	ColLinkedIdentityCreatedAt types.Column = "linked_identities.created_at"
+	ColLinkedIdentityUpdatedAt types.Column = "linked_identities.updated_at"
+)
+
+// LinkedIdentityColumns gives field-style access to the columns of LinkedIdentity
+var LinkedIdentityColumns = struct {
+	ID        types.Column
+	UserID    types.Column
+	Provider  types.Column
+	Subject   types.Column
+	Email     types.Column
+	CreatedAt types.Column
+	UpdatedAt types.Column
+}{
+	ID:        ColLinkedIdentityID,
+	UserID:    ColLinkedIdentityUserID,
+	Provider:  ColLinkedIdentityProvider,
+	Subject:   ColLinkedIdentitySubject,
+	Email:     ColLinkedIdentityEmail,
+	CreatedAt: ColLinkedIdentityCreatedAt,
+	UpdatedAt: ColLinkedIdentityUpdatedAt,
+}
+
+// APIKeyTable is the table of APIKey
+const APIKeyTable = "api_keys"
+
+// Columns of APIKey, qualified with its table
+const (
+	ColAPIKeyID         types.Column = "api_keys.id"
+	ColAPIKeyUserID     types.Column = "api_keys.user_id"
+	ColAPIKeyTeamID     types.Column = "api_keys.team_id"
+	ColAPIKeyName       types.Column = "api_keys.name"
+	ColAPIKeyPrefix     types.Column = "api_keys.prefix"
+	ColAPIKeyKeyHash    types.Column = "api_keys.key_hash"
+	ColAPIKeyScopes     types.Column = "api_keys.scopes"
+	ColAPIKeyExpiresAt  types.Column = "api_keys.expires_at"
+	ColAPIKeyLastUsedAt types.Column = "api_keys.last_used_at"
+	ColAPIKeyRevokedAt  types.Column = "api_keys.revoked_at"
+	ColAPIKeyCreatedAt  types.Column = "api_keys.created_at"
+	ColAPIKeyUpdatedAt  types.Column = "api_keys.updated_at"
+)
+
+// APIKeyColumns gives field-style access to the columns of APIKey
+var APIKeyColumns = struct {
+	ID         types.Column
+	UserID     types.Column
+	TeamID     types.Column
+	Name       types.Column
+	Prefix     types.Column
+	KeyHash    types.Column
+	Scopes     types.Column
+	ExpiresAt  types.Column
+	LastUsedAt types.Column
+	RevokedAt  types.Column
+	CreatedAt  types.Column
+	UpdatedAt  types.Column
+}{
+	ID:         ColAPIKeyID,
+	UserID:     ColAPIKeyUserID,
+	TeamID:     ColAPIKeyTeamID,
+	Name:       ColAPIKeyName,
+	Prefix:     ColAPIKeyPrefix,
+	KeyHash:    ColAPIKeyKeyHash,
+	Scopes:     ColAPIKeyScopes,
+	ExpiresAt:  ColAPIKeyExpiresAt,
+	LastUsedAt: ColAPIKeyLastUsedAt,
+	RevokedAt:  ColAPIKeyRevokedAt,
+	CreatedAt:  ColAPIKeyCreatedAt,
+	UpdatedAt:  ColAPIKeyUpdatedAt,
+}
+
+// APIKeyEventTable is the table of APIKeyEvent
+const APIKeyEventTable = "api_key_events"
+
+// Columns of APIKeyEvent, qualified with its table
+const (
+	ColAPIKeyEventID        types.Column = "api_key_events.id"
+	ColAPIKeyEventAPIKeyID  types.Column = "api_key_events.api_key_id"
+	ColAPIKeyEventActorID   types.Column = "api_key_events.actor_id"
+	ColAPIKeyEventAction    types.Column = "api_key_events.action"
+	ColAPIKeyEventIPAddress types.Column = "api_key_events.ip_address"
+	ColAPIKeyEventCreatedAt types.Column = "api_key_events.created_at"
+)
+
+// APIKeyEventColumns gives field-style access to the columns of APIKeyEvent
+var APIKeyEventColumns = struct {
+	ID        types.Column
+	APIKeyID  types.Column
+	ActorID   types.Column
+	Action    types.Column
+	IPAddress types.Column
+	CreatedAt types.Column
+}{
+	ID:        ColAPIKeyEventID,
+	APIKeyID:  ColAPIKeyEventAPIKeyID,
+	ActorID:   ColAPIKeyEventActorID,
+	Action:    ColAPIKeyEventAction,
+	IPAddress: ColAPIKeyEventIPAddress,
+	CreatedAt: ColAPIKeyEventCreatedAt,
+}
+
+// AuthAttemptTable is the table of AuthAttempt
+const AuthAttemptTable = "auth_attempts"
+
+// Columns of AuthAttempt, qualified with its table
+const (
+	ColAuthAttemptKey       types.Column = "auth_attempts.key"
+	ColAuthAttemptCount     types.Column = "auth_attempts.count"
+	ColAuthAttemptLastAt    types.Column = "auth_attempts.last_at"
+	ColAuthAttemptExpiresAt types.Column = "auth_attempts.expires_at"
+)
+
+// AuthAttemptColumns gives field-style access to the columns of AuthAttempt
+var AuthAttemptColumns = struct {
+	Key       types.Column
+	Count     types.Column
+	LastAt    types.Column
+	ExpiresAt types.Column
+}{
+	Key:       ColAuthAttemptKey,
+	Count:     ColAuthAttemptCount,
+	LastAt:    ColAuthAttemptLastAt,
+	ExpiresAt: ColAuthAttemptExpiresAt,
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/pkg/mailer"
+	"net/url"
+	"time"
+)
+
+const (
+	// verificationTokenTTL is how long an email verification token can be used
+	verificationTokenTTL = 24 * time.Hour
+	// verificationCooldown is how long after sending a verification email another one is not sent
+	verificationCooldown = time.Minute
+)
+
+// ErrInvalidVerificationToken is returned for verification tokens that are unknown, used or expired
+var ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+
+// EmailVerifications mails verification tokens to new accounts and activates the accounts verifying them
+// Tokens are stored as digests in the verification fields of the users table, one per user
+type EmailVerifications struct {
+	repo   *user2.Repository[user2.User]
+	mailer mailer.Mailer
+	appURL string
+}
+
+func NewEmailVerifications(repo *user2.Repository[user2.User], mail mailer.Mailer, appURL string) *EmailVerifications {
+	return &EmailVerifications{repo: repo, mailer: mail, appURL: appURL}
+}
+
+// Send mails a verification link to a pending account, replacing the token sent before
+// Nothing is sent within the cooldown after the previous email, or to accounts that are not pending
+func (v *EmailVerifications) Send(ctx context.Context, user *user2.User) error {
+	if user.Status != user2.StatusPending {
+		return nil
+	}
+	if user.VerificationExpiry != nil && time.Until(*user.VerificationExpiry) > verificationTokenTTL-verificationCooldown {
+		return nil
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	err = v.repo.WithContext(ctx).Updates(user, types.UpdateMap{
+		"verification_token":  digest(token),
+		"verification_expiry": time.Now().Add(verificationTokenTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	link := v.appURL + "/verify-email?token=" + url.QueryEscape(token)
+	return v.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Konsultn email address",
+		Body: "Welcome to Konsultn!\n\n" +
+			"Open the link below within a day to verify your email address and activate your account:\n" + link + "\n\n" +
+			"If you did not sign up, you can ignore this email.",
+	})
+}
+
+// Resend mails a new verification link to the account of email
+// Unknown emails are ignored, so callers cannot probe for accounts
+func (v *EmailVerifications) Resend(ctx context.Context, email string) error {
+	user, err := v.repo.WithContext(ctx).FindFirstBy("email", email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return v.Send(ctx, user)
+}
+
+// Verify activates the pending account a verification token was sent to
+func (v *EmailVerifications) Verify(ctx context.Context, token string) (*user2.User, error) {
+	hash := digest(token)
+
+	user, err := v.repo.WithContext(ctx).FindFirstBy("verification_token", hash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+	if user.VerificationExpiry == nil || time.Now().After(*user.VerificationExpiry) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	// Only pending accounts are activated, a suspended account stays suspended
+	activated := v.repo.GetDB().WithContext(ctx).
+		Model(&user2.User{}).
+		Where("id = ? AND verification_token = ? AND status = ?", user.ID, hash, user2.StatusPending).
+		Updates(map[string]interface{}{
+			"status":              user2.StatusActive,
+			"verification_token":  "",
+			"verification_expiry": nil,
+		})
+	if activated.Error != nil {
+		return nil, activated.Error
+	}
+	if activated.RowsAffected == 0 {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user.Status = user2.StatusActive
+	return user, nil
+}
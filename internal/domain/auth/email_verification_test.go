@@ -0,0 +1,45 @@
+package auth_test
+
+import (
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/domain/user"
+	"net/http"
+	"testing"
+)
+
+func TestEmailVerification(t *testing.T) {
+	mails := &mailbox{}
+	s := newServer(t, auth.Dependencies{Mailer: mails, AppURL: "http://app"})
+
+	s.register("pending@example.com", "password123")
+	token := s.login("pending@example.com", "password123")["id_token"].(string)
+
+	// Pending accounts may sign in, but not use the account routes until they verify their email
+	code, response := s.call(http.MethodGet, "/api/auth/identities", token, nil)
+	if code != http.StatusForbidden || response["code"] != "email_unverified" {
+		t.Fatalf("identities before verifying = %d %v", code, response)
+	}
+
+	link := mails.link(t, "Verify your Konsultn email address")
+	if code, response := s.call(http.MethodGet, "/api/auth/verify?token="+link, "", nil); code != http.StatusOK || response["status"] != user.StatusActive.String() {
+		t.Fatalf("verify = %d %v", code, response)
+	}
+	if code, _ := s.call(http.MethodGet, "/api/auth/verify?token="+link, "", nil); code != http.StatusBadRequest {
+		t.Fatalf("reusing the token = %d, want %d", code, http.StatusBadRequest)
+	}
+	if code, response := s.call(http.MethodGet, "/api/auth/identities", token, nil); code != http.StatusOK {
+		t.Fatalf("identities after verifying = %d %v", code, response)
+	}
+
+	// Suspended accounts can neither use their tokens nor sign in again
+	if err := s.db.Model(&user.User{}).Where("email = ?", "pending@example.com").Update("status", user.StatusSuspended).Error; err != nil {
+		t.Fatal(err)
+	}
+	code, response = s.call(http.MethodGet, "/api/auth/identities", token, nil)
+	if code != http.StatusForbidden || response["code"] != "account_inactive" {
+		t.Fatalf("identities while suspended = %d %v", code, response)
+	}
+	if code, _ := s.call(http.MethodPost, "/api/auth/login", "", map[string]string{"email": "pending@example.com", "password": "password123"}); code != http.StatusForbidden {
+		t.Fatalf("login while suspended = %d, want %d", code, http.StatusForbidden)
+	}
+}
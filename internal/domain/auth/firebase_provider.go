@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"firebase.google.com/go/v4/auth"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"io"
+	"konsultn-api/internal/shared/security"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	identityToolkitURL = "https://identitytoolkit.googleapis.com/v1/accounts:"
+	secureTokenURL     = "https://securetoken.googleapis.com/v1/token"
+)
+
+// FirebaseIdentityProvider keeps accounts in Firebase Authentication
+// Account management uses the Admin SDK, password sign in and token refresh the REST API of the project
+type FirebaseIdentityProvider struct {
+	client *auth.Client
+	apiKey string
+	http   *http.Client
+}
+
+// NewFirebaseIdentityProvider returns a provider for the Firebase project of client
+func NewFirebaseIdentityProvider(client *auth.Client, apiKey string) *FirebaseIdentityProvider {
+	return &FirebaseIdentityProvider{
+		client: client,
+		apiKey: apiKey,
+		http:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *FirebaseIdentityProvider) SignUp(ctx context.Context, email, password string) (string, error) {
+	params := (&auth.UserToCreate{}).Email(email)
+	if password != "" {
+		params = params.Password(password)
+	}
+
+	created, err := p.client.CreateUser(ctx, params)
+	if err != nil {
+		if auth.IsEmailAlreadyExists(err) {
+			return "", ErrAccountExists
+		}
+		return "", fmt.Errorf("failed to create firebase user: %w", err)
+	}
+	return created.UID, nil
+}
+
+func (p *FirebaseIdentityProvider) SignIn(ctx context.Context, email, password string) (*Tokens, error) {
+	var res struct {
+		IDToken      string `json:"idToken"`
+		RefreshToken string `json:"refreshToken"`
+		ExpiresIn    string `json:"expiresIn"`
+		LocalID      string `json:"localId"`
+	}
+	err := p.post(ctx, identityToolkitURL+"signInWithPassword", map[string]interface{}{
+		"email":             email,
+		"password":          password,
+		"returnSecureToken": true,
+	}, &res)
+	if err != nil {
+		return nil, rejectedAs(err, ErrInvalidCredentials)
+	}
+
+	return &Tokens{
+		UID:          res.LocalID,
+		IDToken:      res.IDToken,
+		RefreshToken: res.RefreshToken,
+		ExpiresIn:    seconds(res.ExpiresIn),
+		AuthTime:     authTime(res.IDToken),
+	}, nil
+}
+
+// SignInAs exchanges a custom token of the account for its tokens
+func (p *FirebaseIdentityProvider) SignInAs(ctx context.Context, uid string) (*Tokens, error) {
+	customToken, err := p.client.CustomToken(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create custom token: %w", err)
+	}
+
+	var res struct {
+		IDToken      string `json:"idToken"`
+		RefreshToken string `json:"refreshToken"`
+		ExpiresIn    string `json:"expiresIn"`
+	}
+	err = p.post(ctx, identityToolkitURL+"signInWithCustomToken", map[string]interface{}{
+		"token":             customToken,
+		"returnSecureToken": true,
+	}, &res)
+	if err != nil {
+		return nil, rejectedAs(err, ErrAccountNotFound)
+	}
+
+	return &Tokens{
+		UID:          uid,
+		IDToken:      res.IDToken,
+		RefreshToken: res.RefreshToken,
+		ExpiresIn:    seconds(res.ExpiresIn),
+		AuthTime:     authTime(res.IDToken),
+	}, nil
+}
+
+func (p *FirebaseIdentityProvider) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	var res struct {
+		IDToken      string `json:"id_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    string `json:"expires_in"`
+		UserID       string `json:"user_id"`
+	}
+	err := p.post(ctx, secureTokenURL, map[string]interface{}{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	}, &res)
+	if err != nil {
+		return nil, rejectedAs(err, ErrInvalidRefreshToken)
+	}
+
+	return &Tokens{
+		UID:          res.UserID,
+		IDToken:      res.IDToken,
+		RefreshToken: res.RefreshToken,
+		ExpiresIn:    seconds(res.ExpiresIn),
+		AuthTime:     authTime(res.IDToken),
+	}, nil
+}
+
+// SetPassword also makes Firebase revoke the refresh tokens of the account
+func (p *FirebaseIdentityProvider) SetPassword(ctx context.Context, uid, password string) error {
+	if _, err := p.client.UpdateUser(ctx, uid, (&auth.UserToUpdate{}).Password(password)); err != nil {
+		if auth.IsUserNotFound(err) {
+			return ErrAccountNotFound
+		}
+		return fmt.Errorf("failed to update firebase user: %w", err)
+	}
+	return nil
+}
+
+func (p *FirebaseIdentityProvider) HasPassword(ctx context.Context, uid string) (bool, error) {
+	account, err := p.client.GetUser(ctx, uid)
+	if err != nil {
+		if auth.IsUserNotFound(err) {
+			return false, ErrAccountNotFound
+		}
+		return false, fmt.Errorf("failed to get firebase user: %w", err)
+	}
+
+	for _, info := range account.ProviderUserInfo {
+		if info.ProviderID == "password" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *FirebaseIdentityProvider) SetClaims(ctx context.Context, uid string, claims map[string]interface{}) error {
+	if err := p.client.SetCustomUserClaims(ctx, uid, claims); err != nil {
+		return fmt.Errorf("failed to set custom claims: %w", err)
+	}
+	return nil
+}
+
+func (p *FirebaseIdentityProvider) DeleteUser(ctx context.Context, uid string) error {
+	if err := p.client.DeleteUser(ctx, uid); err != nil {
+		if auth.IsUserNotFound(err) {
+			return ErrAccountNotFound
+		}
+		return fmt.Errorf("failed to delete firebase user: %w", err)
+	}
+	return nil
+}
+
+// restError is a request rejected by the Firebase REST API
+type restError struct {
+	Status  int
+	Message string
+}
+
+func (e *restError) Error() string {
+	return fmt.Sprintf("firebase rejected the request with status %d: %s", e.Status, e.Message)
+}
+
+// post sends a JSON request to a Firebase REST endpoint and decodes the response into out
+func (p *FirebaseIdentityProvider) post(ctx context.Context, endpoint string, payload map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?key="+url.QueryEscape(p.apiKey), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("firebase request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read firebase response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var res struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(data, &res)
+		return &restError{Status: resp.StatusCode, Message: res.Error.Message}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse firebase response: %w", err)
+	}
+	return nil
+}
+
+// rejectedAs maps requests Firebase rejected as invalid to sentinel
+// Firebase answers 400 with codes like INVALID_PASSWORD or TOKEN_EXPIRED for them
+func rejectedAs(err error, sentinel error) error {
+	var rest *restError
+	if errors.As(err, &rest) && rest.Status == http.StatusBadRequest {
+		return fmt.Errorf("%w: %s", sentinel, strings.ToLower(rest.Message))
+	}
+	return err
+}
+
+// authTime reads the sign in time of an ID token Firebase just issued, so its signature is not checked again
+func authTime(idToken string) time.Time {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, claims); err != nil {
+		return time.Time{}
+	}
+	if value, ok := claims[security.ClaimAuthTime].(float64); ok {
+		return time.Unix(int64(value), 0)
+	}
+	return time.Time{}
+}
+
+// seconds parses the string encoded expiry of the Firebase REST API
+func seconds(value string) time.Duration {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(n) * time.Second
+}
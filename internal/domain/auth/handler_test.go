@@ -0,0 +1,31 @@
+package auth_test
+
+import (
+	"context"
+	"konsultn-api/internal/domain/auth"
+	"net/http"
+	"testing"
+)
+
+func TestRegisterAndLogin(t *testing.T) {
+	s := newServer(t, auth.Dependencies{})
+	userID := s.register("ada@example.com", "correct-horse")
+
+	code, _ := s.call(http.MethodPost, "/api/auth/register", "", map[string]string{"email": "ada@example.com", "password": "another-one"})
+	if code != http.StatusBadRequest {
+		t.Fatalf("duplicate register = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	tokens := s.login("ada@example.com", "correct-horse")
+	principal, err := s.verifier.Verify(context.Background(), tokens["id_token"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.UserID != userID {
+		t.Fatalf("token of user %q, want %q", principal.UserID, userID)
+	}
+
+	if code, _ := s.call(http.MethodPost, "/api/auth/login", "", map[string]string{"email": "ada@example.com", "password": "wrong"}); code != http.StatusUnauthorized {
+		t.Fatalf("login with a wrong password = %d", code)
+	}
+}
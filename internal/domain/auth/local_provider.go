@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/shared/ids"
+	"konsultn-api/internal/shared/security"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// localTokenTTL is how long ID tokens issued by the local provider are valid
+	localTokenTTL = time.Hour
+	// localRefreshTTL is how long refresh tokens issued by the local provider are valid
+	localRefreshTTL = 30 * 24 * time.Hour
+)
+
+// LocalIdentityProvider keeps accounts in the users table, so the API can run without Firebase
+// Passwords are stored as bcrypt hashes.
+// Refresh tokens are not stored, they are authenticated with an HMAC of secret and revoked through sessions
+type LocalIdentityProvider struct {
+	repo   *user.Repository[user.User]
+	signer security.TokenSigner
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewLocalIdentityProvider returns a provider for the accounts of repo issuing ID tokens with signer
+// and refresh tokens authenticated with secret
+func NewLocalIdentityProvider(repo *user.Repository[user.User], signer security.TokenSigner, secret []byte) *LocalIdentityProvider {
+	return &LocalIdentityProvider{repo: repo, signer: signer, secret: secret, ttl: localTokenTTL}
+}
+
+// SignUp creates the users row of the account, the caller completes its profile afterwards
+func (p *LocalIdentityProvider) SignUp(ctx context.Context, email, password string) (string, error) {
+	repo := p.repo.WithContext(ctx)
+
+	exists, err := repo.Exists("email = ?", email)
+	if err != nil {
+		return "", fmt.Errorf("failed to check email: %w", err)
+	}
+	if exists {
+		return "", ErrAccountExists
+	}
+
+	hash := ""
+	if password != "" {
+		if hash, err = hashPassword(password); err != nil {
+			return "", err
+		}
+	}
+
+	uid := ids.ULID.New()
+	account := &user.User{UID: uid, Email: email, PasswordHash: hash}
+	if _, err := repo.Save(account); err != nil {
+		return "", err
+	}
+	return uid, nil
+}
+
+func (p *LocalIdentityProvider) SignIn(ctx context.Context, email, password string) (*Tokens, error) {
+	account, err := p.repo.WithContext(ctx).FindFirstBy("email", email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if account.PasswordHash == "" || bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return p.issue(account, time.Now())
+}
+
+func (p *LocalIdentityProvider) SignInAs(ctx context.Context, uid string) (*Tokens, error) {
+	account, err := p.find(p.repo.WithContext(ctx), uid)
+	if err != nil {
+		return nil, err
+	}
+	return p.issue(account, time.Now())
+}
+
+func (p *LocalIdentityProvider) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	uid, authTime, err := p.parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := p.repo.WithContext(ctx).FindFirstBy("uid", uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	return p.issue(account, authTime)
+}
+
+func (p *LocalIdentityProvider) SetPassword(ctx context.Context, uid, password string) error {
+	repo := p.repo.WithContext(ctx)
+
+	account, err := p.find(repo, uid)
+	if err != nil {
+		return err
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return repo.Updates(account, types.UpdateMap{"password_hash": hash})
+}
+
+func (p *LocalIdentityProvider) HasPassword(ctx context.Context, uid string) (bool, error) {
+	account, err := p.find(p.repo.WithContext(ctx), uid)
+	if err != nil {
+		return false, err
+	}
+	return account.PasswordHash != "", nil
+}
+
+func (p *LocalIdentityProvider) SetClaims(ctx context.Context, uid string, claims map[string]interface{}) error {
+	repo := p.repo.WithContext(ctx)
+
+	account, err := p.find(repo, uid)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return fmt.Errorf("failed to encode custom claims: %w", err)
+	}
+	return repo.Updates(account, types.UpdateMap{"custom_claims": datatypes.JSON(encoded)})
+}
+
+// DeleteUser hard deletes the users row, since it holds the credentials of the account
+func (p *LocalIdentityProvider) DeleteUser(ctx context.Context, uid string) error {
+	repo := p.repo.WithContext(ctx)
+
+	account, err := p.find(repo, uid)
+	if err != nil {
+		return err
+	}
+	return repo.Delete(account, true)
+}
+
+func (p *LocalIdentityProvider) find(repo types.Repository[user.User, string], uid string) (*user.User, error) {
+	account, err := repo.FindFirstBy("uid", uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// issue signs an ID token and a refresh token for the account signed in at authTime
+func (p *LocalIdentityProvider) issue(account *user.User, authTime time.Time) (*Tokens, error) {
+	claims := map[string]interface{}{}
+	if len(account.CustomClaims) > 0 {
+		if err := json.Unmarshal(account.CustomClaims, &claims); err != nil {
+			return nil, fmt.Errorf("failed to decode custom claims: %w", err)
+		}
+	}
+
+	idToken, err := p.signer.Sign(security.Principal{
+		UID:      account.UID,
+		UserID:   account.ID,
+		Email:    account.Email,
+		AuthTime: authTime,
+		Claims:   claims,
+	}, p.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign id token: %w", err)
+	}
+
+	refreshToken, err := p.refreshToken(account.UID, authTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		UID:          account.UID,
+		IDToken:      idToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    p.ttl,
+		AuthTime:     time.Unix(authTime.Unix(), 0),
+	}, nil
+}
+
+// refreshToken returns "uid.authTime.expiry.nonce.mac", times being unix seconds
+func (p *LocalIdentityProvider) refreshToken(uid string, authTime time.Time) (string, error) {
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	payload := strings.Join([]string{
+		uid,
+		strconv.FormatInt(authTime.Unix(), 10),
+		strconv.FormatInt(time.Now().Add(localRefreshTTL).Unix(), 10),
+		nonce,
+	}, ".")
+	return payload + "." + p.mac(payload), nil
+}
+
+// parseRefreshToken authenticates a refresh token and returns the account and sign in time it was issued for
+func (p *LocalIdentityProvider) parseRefreshToken(token string) (string, time.Time, error) {
+	cut := strings.LastIndex(token, ".")
+	if cut < 0 || !hmac.Equal([]byte(token[cut+1:]), []byte(p.mac(token[:cut]))) {
+		return "", time.Time{}, ErrInvalidRefreshToken
+	}
+
+	parts := strings.Split(token[:cut], ".")
+	if len(parts) != 4 {
+		return "", time.Time{}, ErrInvalidRefreshToken
+	}
+	authTime, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, ErrInvalidRefreshToken
+	}
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || time.Now().Unix() > expiry {
+		return "", time.Time{}, ErrInvalidRefreshToken
+	}
+	return parts[0], time.Unix(authTime, 0), nil
+}
+
+func (p *LocalIdentityProvider) mac(payload string) string {
+	h := hmac.New(sha256.New, p.secret)
+	h.Write([]byte("refresh." + payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
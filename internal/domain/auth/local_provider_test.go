@@ -0,0 +1,65 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/shared/security"
+	"konsultn-api/internal/testkit"
+	"testing"
+)
+
+func TestLocalIdentityProvider(t *testing.T) {
+	ctx := context.Background()
+	provider, err := auth.NewIdentityProvider(auth.Config{Provider: auth.ProviderLocal}, testkit.DB(t), nil, tokenConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := security.NewTokenVerifier(tokenConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := provider.SignUp(ctx, "ada@example.com", "correct-horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.SignUp(ctx, "ada@example.com", "another-one"); !errors.Is(err, auth.ErrAccountExists) {
+		t.Fatalf("duplicate SignUp = %v, want ErrAccountExists", err)
+	}
+	if _, err := provider.SignIn(ctx, "ada@example.com", "wrong"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Fatalf("SignIn with a wrong password = %v, want ErrInvalidCredentials", err)
+	}
+
+	tokens, err := provider.SignIn(ctx, "ada@example.com", "correct-horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.SetClaims(ctx, uid, map[string]interface{}{"role": "freelancer"}); err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err := provider.Refresh(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal, err := verifier.Verify(ctx, refreshed.IDToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !principal.HasRole("freelancer") {
+		t.Fatalf("refreshed token misses the new claims: %+v", principal)
+	}
+
+	if err := provider.SetPassword(ctx, uid, "new-password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.SignIn(ctx, "ada@example.com", "new-password"); err != nil {
+		t.Fatalf("SignIn with the new password: %v", err)
+	}
+	if err := provider.DeleteUser(ctx, uid); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.SignIn(ctx, "ada@example.com", "new-password"); err == nil {
+		t.Fatal("a deleted account signed in")
+	}
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// LockoutPolicy throttles the attempts of a key
+// Attempts past FreeAttempts wait Backoff, doubled by every further attempt up to MaxBackoff.
+// From LockAfter attempts the key is locked for at least LockFor
+type LockoutPolicy struct {
+	FreeAttempts int
+	CaptchaAfter int
+	LockAfter    int
+	Backoff      time.Duration
+	LockFor      time.Duration
+	MaxBackoff   time.Duration
+	// Window is how long a key has to stay idle for its attempts to be forgotten
+	Window time.Duration
+}
+
+// Default policies of the sign in and sign up throttling
+var (
+	// AccountLoginPolicy counts the failed sign ins of an account, from any IP
+	AccountLoginPolicy = LockoutPolicy{FreeAttempts: 3, CaptchaAfter: 3, LockAfter: 10, Backoff: 2 * time.Second, LockFor: 15 * time.Minute, MaxBackoff: time.Hour, Window: 24 * time.Hour}
+	// IPLoginPolicy counts the failed sign ins of an IP, for any account, leniently as IPs can be shared
+	IPLoginPolicy = LockoutPolicy{FreeAttempts: 10, CaptchaAfter: 10, LockAfter: 50, Backoff: time.Second, LockFor: 15 * time.Minute, MaxBackoff: time.Hour, Window: time.Hour}
+	// IPSignUpPolicy counts every sign up of an IP
+	IPSignUpPolicy = LockoutPolicy{FreeAttempts: 5, CaptchaAfter: 3, LockAfter: 20, Backoff: 30 * time.Second, LockFor: time.Hour, MaxBackoff: 24 * time.Hour, Window: 24 * time.Hour}
+)
+
+// Throttle is the state of the attempts of a sign in or sign up
+type Throttle struct {
+	// BlockedUntil is when the next attempt is allowed, it is in the past when attempts are allowed
+	BlockedUntil time.Time
+	// Locked is set when the attempts reached the lockout, not just a backoff
+	Locked bool
+	// CaptchaRequired asks the client to have the user solve a CAPTCHA before the next attempt
+	CaptchaRequired bool
+	Attempts        int
+}
+
+// Blocked reports whether attempts are refused at now
+func (t Throttle) Blocked(now time.Time) bool {
+	return now.Before(t.BlockedUntil)
+}
+
+// merge combines the throttles of the keys of an attempt, the strictest wins
+func (t Throttle) merge(other Throttle) Throttle {
+	if other.BlockedUntil.After(t.BlockedUntil) {
+		t.BlockedUntil = other.BlockedUntil
+	}
+	t.Locked = t.Locked || other.Locked
+	t.CaptchaRequired = t.CaptchaRequired || other.CaptchaRequired
+	if other.Attempts > t.Attempts {
+		t.Attempts = other.Attempts
+	}
+	return t
+}
+
+// throttle applies the policy to the attempts of a key
+func (p LockoutPolicy) throttle(attempts Attempts) Throttle {
+	throttle := Throttle{
+		Attempts:        attempts.Count,
+		CaptchaRequired: attempts.Count >= p.CaptchaAfter,
+	}
+	if attempts.Count <= p.FreeAttempts {
+		return throttle
+	}
+
+	delay := p.Backoff
+	for i := p.FreeAttempts + 1; i < attempts.Count && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if attempts.Count >= p.LockAfter {
+		throttle.Locked = true
+		delay = max(delay, p.LockFor)
+	}
+	throttle.BlockedUntil = attempts.LastAt.Add(min(delay, p.MaxBackoff))
+	return throttle
+}
+
+// Lockout throttles sign ins per account and IP, and sign ups per IP
+// Accounts are keyed by a digest of their email, so the store holds no addresses
+type Lockout struct {
+	store   AttemptStore
+	account LockoutPolicy
+	ip      LockoutPolicy
+	signUp  LockoutPolicy
+}
+
+func NewLockout(store AttemptStore) *Lockout {
+	return &Lockout{store: store, account: AccountLoginPolicy, ip: IPLoginPolicy, signUp: IPSignUpPolicy}
+}
+
+// CheckLogin returns the throttle of a sign in of the email from ip
+func (l *Lockout) CheckLogin(ctx context.Context, email, ip string) (Throttle, error) {
+	account, err := l.state(ctx, accountKey(email), l.account)
+	if err != nil {
+		return Throttle{}, err
+	}
+	address, err := l.state(ctx, "login:ip:"+ip, l.ip)
+	if err != nil {
+		return Throttle{}, err
+	}
+	return account.merge(address), nil
+}
+
+// FailLogin records a failed sign in of the email from ip and returns the throttle of the next one
+func (l *Lockout) FailLogin(ctx context.Context, email, ip string) (Throttle, error) {
+	account, err := l.record(ctx, accountKey(email), l.account)
+	if err != nil {
+		return Throttle{}, err
+	}
+	address, err := l.record(ctx, "login:ip:"+ip, l.ip)
+	if err != nil {
+		return Throttle{}, err
+	}
+	return account.merge(address), nil
+}
+
+// SucceedLogin forgets the failed sign ins of the account of the email
+// The IP keeps its count, or signing in to an account of their own would let attackers reset it
+func (l *Lockout) SucceedLogin(ctx context.Context, email string) error {
+	return l.store.Reset(ctx, accountKey(email))
+}
+
+// CheckSignUp returns the throttle of a sign up from ip
+func (l *Lockout) CheckSignUp(ctx context.Context, ip string) (Throttle, error) {
+	return l.state(ctx, "signup:ip:"+ip, l.signUp)
+}
+
+// RecordSignUp counts a sign up from ip and returns the throttle of the next one
+func (l *Lockout) RecordSignUp(ctx context.Context, ip string) (Throttle, error) {
+	return l.record(ctx, "signup:ip:"+ip, l.signUp)
+}
+
+// AccountStatus returns the throttle of the sign ins of the account of the email
+func (l *Lockout) AccountStatus(ctx context.Context, email string) (Throttle, error) {
+	return l.state(ctx, accountKey(email), l.account)
+}
+
+// Unlock forgets the failed sign ins of the account of the email, lifting its lockout
+func (l *Lockout) Unlock(ctx context.Context, email string) error {
+	return l.store.Reset(ctx, accountKey(email))
+}
+
+func (l *Lockout) state(ctx context.Context, key string, policy LockoutPolicy) (Throttle, error) {
+	attempts, err := l.store.Get(ctx, key)
+	if err != nil {
+		return Throttle{}, err
+	}
+	return policy.throttle(attempts), nil
+}
+
+func (l *Lockout) record(ctx context.Context, key string, policy LockoutPolicy) (Throttle, error) {
+	attempts, err := l.store.Record(ctx, key, policy.Window)
+	if err != nil {
+		return Throttle{}, err
+	}
+	return policy.throttle(attempts), nil
+}
+
+func accountKey(email string) string {
+	return "login:account:" + digest(strings.ToLower(strings.TrimSpace(email)))
+}
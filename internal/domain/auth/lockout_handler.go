@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type LockoutResponse struct {
+	UserID          string     `json:"user_id"`
+	FailedAttempts  int        `json:"failed_attempts"`
+	Locked          bool       `json:"locked"`
+	BlockedUntil    *time.Time `json:"blocked_until"`
+	CaptchaRequired bool       `json:"captcha_required"`
+}
+
+// GetUserLockout returns the failed sign ins of a user and until when they are refused
+func (h *Handler) GetUserLockout(ctx *gin.Context) {
+	id := ctx.Param("id")
+	user, err := h.repo.FindById(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user with id " + id + " not found"})
+		return
+	}
+
+	throttle, err := h.lockout.AccountStatus(ctx, user.Email)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lockout"})
+		return
+	}
+
+	response := LockoutResponse{
+		UserID:          user.ID,
+		FailedAttempts:  throttle.Attempts,
+		CaptchaRequired: throttle.CaptchaRequired,
+	}
+	if throttle.Blocked(time.Now()) {
+		response.Locked = throttle.Locked
+		response.BlockedUntil = &throttle.BlockedUntil
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UnlockUser lifts the lockout of a user and forgets their failed sign ins
+func (h *Handler) UnlockUser(ctx *gin.Context) {
+	id := ctx.Param("id")
+	user, err := h.repo.FindById(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user with id " + id + " not found"})
+		return
+	}
+
+	if err := h.lockout.Unlock(ctx, user.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "user unlocked"})
+}
+
+// tooManyAttempts refuses a throttled sign in or sign up
+func tooManyAttempts(ctx *gin.Context, throttle Throttle) {
+	retryAfter := int(math.Ceil(time.Until(throttle.BlockedUntil).Seconds()))
+	code := "too_many_attempts"
+	if throttle.Locked {
+		code = "account_locked"
+	}
+
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	ctx.JSON(http.StatusTooManyRequests, gin.H{
+		"error":            "Too many attempts, try again later",
+		"code":             code,
+		"retry_after":      retryAfter,
+		"captcha_required": throttle.CaptchaRequired,
+	})
+}
@@ -0,0 +1,103 @@
+package auth_test
+
+import (
+	"context"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/testkit"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockoutPolicy(t *testing.T) {
+	ctx := context.Background()
+	stores := map[string]auth.AttemptStore{
+		"memory":   auth.NewMemoryAttemptStore(),
+		"database": auth.NewDBAttemptStore(testkit.DB(t)),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			lockout := auth.NewLockout(store)
+			for i := 1; i <= auth.AccountLoginPolicy.LockAfter; i++ {
+				throttle, err := lockout.FailLogin(ctx, " Ada@Example.com", "192.0.2.1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if blocked := throttle.Blocked(time.Now()); blocked != (i > auth.AccountLoginPolicy.FreeAttempts) {
+					t.Fatalf("failure %d blocked = %v", i, blocked)
+				}
+			}
+
+			status, err := lockout.AccountStatus(ctx, "ada@example.com")
+			if err != nil || !status.Locked {
+				t.Fatalf("account not locked after %d failures: %+v, %v", status.Attempts, status, err)
+			}
+			if other, _ := lockout.CheckLogin(ctx, "grace@example.com", "198.51.100.1"); other.Blocked(time.Now()) {
+				t.Fatal("the lockout of an account blocks another one")
+			}
+
+			if err := lockout.Unlock(ctx, "ada@example.com"); err != nil {
+				t.Fatal(err)
+			}
+			if throttle, _ := lockout.CheckLogin(ctx, "ada@example.com", "198.51.100.1"); throttle.Blocked(time.Now()) {
+				t.Fatalf("unlocked account still blocked: %+v", throttle)
+			}
+		})
+	}
+}
+
+func TestAttemptStoreCountsConcurrently(t *testing.T) {
+	ctx := context.Background()
+	store := auth.NewMemoryAttemptStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = store.Record(ctx, "key", time.Hour)
+		}()
+	}
+	wg.Wait()
+
+	if attempts, err := store.Get(ctx, "key"); err != nil || attempts.Count != 20 {
+		t.Fatalf("attempts = %+v, %v", attempts, err)
+	}
+}
+
+func TestRoutesCountAttemptsSeparately(t *testing.T) {
+	first := newServer(t, auth.Dependencies{})
+	first.register("ada@example.com", "correct-horse")
+	for i := 0; i <= auth.AccountLoginPolicy.FreeAttempts; i++ {
+		first.call(http.MethodPost, "/api/auth/login", "", map[string]string{"email": "ada@example.com", "password": "wrong"})
+	}
+	if code, _ := first.call(http.MethodPost, "/api/auth/login", "", map[string]string{"email": "ada@example.com", "password": "correct-horse"}); code != http.StatusTooManyRequests {
+		t.Fatalf("sign in of a throttled account = %d", code)
+	}
+
+	second := newServer(t, auth.Dependencies{})
+	second.register("ada@example.com", "correct-horse")
+	second.login("ada@example.com", "correct-horse")
+}
+
+func TestTwoFactorFailuresCountAsFailedSignIns(t *testing.T) {
+	s := newServer(t, auth.Dependencies{SecretCipher: testCipher(t)})
+	s.register("ada@example.com", "correct-horse")
+	s.enableTwoFactor(s.login("ada@example.com", "correct-horse")["id_token"].(string))
+
+	for i := 0; i < auth.AccountLoginPolicy.FreeAttempts-1; i++ {
+		if code, _ := s.call(http.MethodPost, "/api/auth/login", "", map[string]string{"email": "ada@example.com", "password": "wrong"}); code != http.StatusUnauthorized {
+			t.Fatalf("wrong password = %d", code)
+		}
+	}
+
+	// The right password alone does not clear the failures, the wrong code then reaches the backoff
+	challenge := s.login("ada@example.com", "correct-horse")["challenge"].(string)
+	for _, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		code, response := s.call(http.MethodPost, "/api/auth/2fa/verify", "", map[string]string{"challenge": challenge, "code": "000000"})
+		if code != want {
+			t.Fatalf("wrong two-factor code = %d %v, want %d", code, response, want)
+		}
+	}
+}
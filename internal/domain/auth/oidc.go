@@ -0,0 +1,381 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"konsultn-api/internal/shared/security"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSocialSignInFailed is returned when an external provider rejects a code or returns an invalid identity
+var ErrSocialSignInFailed = errors.New("social sign in failed")
+
+// OIDCConfig configures an external OpenID Connect provider
+type OIDCConfig struct {
+	// Name identifies the provider in routes and linked identities, e.g. google
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// AuthURL, TokenURL, UserInfoURL and JWKSURL override the endpoints discovered from the issuer.
+	// OAuth 2 providers without discovery, like GitHub, are configured with them and no issuer
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	JWKSURL     string
+	// EmailsURL lists the email addresses of the user, for providers like GitHub whose userinfo
+	// leaves private addresses out and does not state whether they are verified
+	EmailsURL string
+}
+
+// knownOIDCProviders are the defaults of the providers that can be enabled by name only
+var knownOIDCProviders = map[string]OIDCConfig{
+	"google": {
+		Issuer: "https://accounts.google.com",
+		Scopes: []string{"openid", "email", "profile"},
+	},
+	"github": {
+		AuthURL:     "https://github.com/login/oauth/authorize",
+		TokenURL:    "https://github.com/login/oauth/access_token",
+		UserInfoURL: "https://api.github.com/user",
+		EmailsURL:   "https://api.github.com/user/emails",
+		Scopes:      []string{"read:user", "user:email"},
+	},
+	"microsoft": {
+		Scopes: []string{"openid", "email", "profile"},
+	},
+}
+
+// OIDCConfigsFromEnv reads the social providers from the environment
+// OIDC_PROVIDERS lists their names, each configured with OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET
+// and optionally OIDC_<NAME>_ISSUER, OIDC_<NAME>_SCOPES (space separated), OIDC_<NAME>_AUTH_URL,
+// OIDC_<NAME>_TOKEN_URL, OIDC_<NAME>_USERINFO_URL, OIDC_<NAME>_JWKS_URL and OIDC_<NAME>_EMAILS_URL.
+// Microsoft signs its tokens per tenant, so it requires OIDC_MICROSOFT_TENANT or an issuer
+func OIDCConfigsFromEnv() ([]OIDCConfig, error) {
+	var configs []OIDCConfig
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		cfg := knownOIDCProviders[name]
+		cfg.Name = name
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		if name == "microsoft" && os.Getenv(prefix+"TENANT") != "" {
+			cfg.Issuer = "https://login.microsoftonline.com/" + os.Getenv(prefix+"TENANT") + "/v2.0"
+		}
+
+		setFromEnv(&cfg.Issuer, prefix+"ISSUER")
+		setFromEnv(&cfg.ClientID, prefix+"CLIENT_ID")
+		setFromEnv(&cfg.ClientSecret, prefix+"CLIENT_SECRET")
+		setFromEnv(&cfg.AuthURL, prefix+"AUTH_URL")
+		setFromEnv(&cfg.TokenURL, prefix+"TOKEN_URL")
+		setFromEnv(&cfg.UserInfoURL, prefix+"USERINFO_URL")
+		setFromEnv(&cfg.JWKSURL, prefix+"JWKS_URL")
+		setFromEnv(&cfg.EmailsURL, prefix+"EMAILS_URL")
+		if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+			cfg.Scopes = strings.Fields(scopes)
+		}
+
+		if cfg.ClientID == "" || cfg.ClientSecret == "" {
+			return nil, fmt.Errorf("the %s social provider requires %sCLIENT_ID and %sCLIENT_SECRET", name, prefix, prefix)
+		}
+		if cfg.Issuer == "" && (cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "") {
+			return nil, fmt.Errorf("the %s social provider requires %sISSUER or its endpoints", name, prefix)
+		}
+		configs = append(configs, cfg)
+	}
+	return configs, nil
+}
+
+func setFromEnv(field *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*field = value
+	}
+}
+
+// ExternalIdentity is an account at a social provider, as asserted by the provider
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// OIDCProvider signs users in with the authorization code flow of an OpenID Connect provider
+// Endpoints are discovered from the issuer on first use. ID tokens are verified against the keys of the
+// provider, providers that return none are asked for the identity at their userinfo endpoint
+type OIDCProvider struct {
+	cfg  OIDCConfig
+	http *http.Client
+
+	mu       sync.Mutex
+	ready    bool
+	verifier *security.JWKSVerifier
+}
+
+func NewOIDCProvider(cfg OIDCConfig) *OIDCProvider {
+	return &OIDCProvider{cfg: cfg, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name returns the name the provider was configured with
+func (p *OIDCProvider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL returns the URL of the consent page, the provider redirects to redirectURI with a code and the state
+// The nonce is bound to the ID token and the challenge is the S256 PKCE challenge of the code verifier
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, redirectURI, state, nonce, challenge string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", challenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(p.cfg.AuthURL, "?") {
+		separator = "&"
+	}
+	return p.cfg.AuthURL + separator + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the identity it was issued for
+func (p *OIDCProvider) Exchange(ctx context.Context, code, redirectURI, verifier, nonce string) (*ExternalIdentity, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("client_secret", p.cfg.ClientSecret)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var res struct {
+		IDToken     string `json:"id_token"`
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	status, err := p.do(req, &res)
+	if err != nil {
+		return nil, err
+	}
+	// GitHub answers rejected codes with 200 and an error field
+	if status != http.StatusOK || res.Error != "" {
+		return nil, fmt.Errorf("%w: the token endpoint answered %d %s", ErrSocialSignInFailed, status, res.Error)
+	}
+
+	if res.IDToken != "" && p.verifier != nil {
+		return p.identityFromIDToken(ctx, res.IDToken, nonce)
+	}
+	if res.AccessToken != "" && p.cfg.UserInfoURL != "" {
+		return p.identityFromUserInfo(ctx, res.AccessToken)
+	}
+	return nil, fmt.Errorf("%w: the provider returned no verifiable identity", ErrSocialSignInFailed)
+}
+
+func (p *OIDCProvider) identityFromIDToken(ctx context.Context, idToken, nonce string) (*ExternalIdentity, error) {
+	principal, err := p.verifier.Verify(ctx, idToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSocialSignInFailed, err)
+	}
+	if claimed, _ := principal.Claims["nonce"].(string); claimed != nonce {
+		return nil, fmt.Errorf("%w: the id token was not issued for this sign in", ErrSocialSignInFailed)
+	}
+	return p.identity(principal.UID, principal.Claims), nil
+}
+
+// identityFromUserInfo reads the identity from the userinfo endpoint
+// GitHub names its fields differently and states no email verification, its email is read from the emails endpoint
+func (p *OIDCProvider) identityFromUserInfo(ctx context.Context, accessToken string) (*ExternalIdentity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	claims := map[string]interface{}{}
+	status, err := p.do(req, &claims)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: the userinfo endpoint answered %d", ErrSocialSignInFailed, status)
+	}
+
+	subject := stringClaim(claims, "sub", "id")
+	if subject == "" {
+		return nil, fmt.Errorf("%w: the userinfo response has no subject", ErrSocialSignInFailed)
+	}
+
+	if p.cfg.EmailsURL != "" {
+		email, verified, err := p.primaryEmail(ctx, accessToken)
+		if err != nil {
+			return nil, err
+		}
+		claims["email"] = email
+		claims["email_verified"] = verified
+	}
+	return p.identity(subject, claims), nil
+}
+
+// primaryEmail returns the primary address of the user from the emails endpoint and whether it is verified
+func (p *OIDCProvider) primaryEmail(ctx context.Context, accessToken string) (string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.EmailsURL, nil)
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	status, err := p.do(req, &emails)
+	if err != nil {
+		return "", false, err
+	}
+	if status != http.StatusOK {
+		return "", false, fmt.Errorf("%w: the emails endpoint answered %d", ErrSocialSignInFailed, status)
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			return email.Email, email.Verified, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (p *OIDCProvider) identity(subject string, claims map[string]interface{}) *ExternalIdentity {
+	verified, ok := claims["email_verified"].(bool)
+	if !ok {
+		verified = claims["email_verified"] == "true"
+	}
+
+	return &ExternalIdentity{
+		Provider:      p.cfg.Name,
+		Subject:       subject,
+		Email:         stringClaim(claims, "email"),
+		EmailVerified: verified,
+		Name:          stringClaim(claims, "name", "login"),
+		Picture:       stringClaim(claims, "picture", "avatar_url"),
+	}
+}
+
+// discover fills the endpoints that were not configured from the discovery document of the issuer
+func (p *OIDCProvider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ready {
+		return nil
+	}
+
+	if p.cfg.Issuer != "" && (p.cfg.AuthURL == "" || p.cfg.TokenURL == "" || p.cfg.JWKSURL == "") {
+		endpoint := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return err
+		}
+
+		var doc struct {
+			Issuer                string `json:"issuer"`
+			AuthorizationEndpoint string `json:"authorization_endpoint"`
+			TokenEndpoint         string `json:"token_endpoint"`
+			UserInfoEndpoint      string `json:"userinfo_endpoint"`
+			JWKSURI               string `json:"jwks_uri"`
+		}
+		status, err := p.do(req, &doc)
+		if err != nil {
+			return err
+		}
+		if status != http.StatusOK {
+			return fmt.Errorf("discovery of %s answered %d", p.cfg.Issuer, status)
+		}
+		if doc.Issuer != p.cfg.Issuer {
+			return fmt.Errorf("discovery of %s returned the issuer %s", p.cfg.Issuer, doc.Issuer)
+		}
+
+		setDefault(&p.cfg.AuthURL, doc.AuthorizationEndpoint)
+		setDefault(&p.cfg.TokenURL, doc.TokenEndpoint)
+		setDefault(&p.cfg.UserInfoURL, doc.UserInfoEndpoint)
+		setDefault(&p.cfg.JWKSURL, doc.JWKSURI)
+	}
+
+	if p.cfg.JWKSURL != "" {
+		p.verifier = security.NewJWKSVerifier(p.cfg.JWKSURL, p.cfg.Issuer, p.cfg.ClientID)
+	}
+	p.ready = true
+	return nil
+}
+
+// do sends a request and decodes its JSON response into out, returning the status code
+func (p *OIDCProvider) do(req *http.Request, out interface{}) (int, error) {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%s request failed: %w", p.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s response: %w", p.cfg.Name, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil && resp.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("failed to parse %s response: %w", p.cfg.Name, err)
+	}
+	return resp.StatusCode, nil
+}
+
+func setDefault(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// stringClaim returns the first of keys set in claims, numeric IDs like the ones of GitHub are formatted
+func stringClaim(claims map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch value := claims[key].(type) {
+		case string:
+			if value != "" {
+				return value
+			}
+		case float64:
+			return fmt.Sprintf("%.0f", value)
+		}
+	}
+	return ""
+}
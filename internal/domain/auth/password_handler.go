@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// ForgotPassword mails a password reset link to the account of the email, if there is one
+// The response is the same either way and the mail is sent in the background, so it does not reveal accounts
+func (h *Handler) ForgotPassword(ctx *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	background := context.WithoutCancel(ctx.Request.Context())
+	go func() {
+		if err := h.resets.Request(background, req.Email); err != nil {
+			log.Printf("failed to issue password reset: %v", err)
+		}
+	}()
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "If an account exists for this email, a password reset link was sent to it"})
+}
+
+// ResetPassword sets a new password with a token mailed by ForgotPassword and signs out every session
+func (h *Handler) ResetPassword(ctx *gin.Context) {
+	var req ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.resets.Reset(ctx, req.Token, req.Password); err != nil {
+		if errors.Is(err, ErrInvalidResetToken) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "password reset, please log in again"})
+}
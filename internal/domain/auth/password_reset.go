@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/pkg/mailer"
+	"net/url"
+	"time"
+)
+
+const (
+	// resetTokenTTL is how long a password reset token can be used
+	resetTokenTTL = time.Hour
+	// resetCooldown is how long after issuing a reset token requests for the same account are ignored
+	resetCooldown = time.Minute
+)
+
+// ErrInvalidResetToken is returned for reset tokens that are unknown, used or expired
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+// PasswordResets issues password reset tokens and resets passwords with them
+// Tokens are stored as digests in the reset token fields of the users table, one per user
+type PasswordResets struct {
+	repo     *user2.Repository[user2.User]
+	provider IdentityProvider
+	sessions *SessionStore
+	mailer   mailer.Mailer
+	appURL   string
+}
+
+func NewPasswordResets(repo *user2.Repository[user2.User], provider IdentityProvider, sessions *SessionStore, mail mailer.Mailer, appURL string) *PasswordResets {
+	return &PasswordResets{repo: repo, provider: provider, sessions: sessions, mailer: mail, appURL: appURL}
+}
+
+// Request issues a reset token for the account of email and mails a link carrying it
+// Unknown emails are ignored, so callers cannot probe for accounts
+func (r *PasswordResets) Request(ctx context.Context, email string) error {
+	repo := r.repo.WithContext(ctx)
+
+	user, err := repo.FindFirstBy("email", email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.ResetTokenExpiry != nil && time.Until(*user.ResetTokenExpiry) > resetTokenTTL-resetCooldown {
+		return nil
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	err = repo.Updates(user, types.UpdateMap{
+		"reset_token":        digest(token),
+		"reset_token_expiry": time.Now().Add(resetTokenTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	link := r.appURL + "/reset-password?token=" + url.QueryEscape(token)
+	return r.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Konsultn password",
+		Body: "We received a request to reset the password of your Konsultn account.\n\n" +
+			"Open the link below within an hour to choose a new password:\n" + link + "\n\n" +
+			"If you did not ask for it, you can ignore this email.",
+	})
+}
+
+// Reset sets the password of the account a reset token was issued for and revokes its sessions
+func (r *PasswordResets) Reset(ctx context.Context, token, password string) error {
+	hash := digest(token)
+
+	user, err := r.repo.WithContext(ctx).FindFirstBy("reset_token", hash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if user.ResetTokenExpiry == nil || time.Now().After(*user.ResetTokenExpiry) {
+		return ErrInvalidResetToken
+	}
+
+	// The token is consumed before the password is set, so concurrent requests cannot use it twice
+	consumed := r.repo.GetDB().WithContext(ctx).
+		Model(&user2.User{}).
+		Where("id = ? AND reset_token = ?", user.ID, hash).
+		Updates(map[string]interface{}{"reset_token": "", "reset_token_expiry": nil})
+	if consumed.Error != nil {
+		return consumed.Error
+	}
+	if consumed.RowsAffected == 0 {
+		return ErrInvalidResetToken
+	}
+
+	if err := r.provider.SetPassword(ctx, user.UID, password); err != nil {
+		// The password is unchanged, give the token back so the link can be used again
+		restored := r.repo.GetDB().WithContext(context.WithoutCancel(ctx)).
+			Model(&user2.User{}).
+			Where("id = ? AND reset_token = ?", user.ID, "").
+			Updates(map[string]interface{}{"reset_token": hash, "reset_token_expiry": user.ResetTokenExpiry})
+		if restored.Error != nil {
+			return errors.Join(err, fmt.Errorf("failed to restore reset token: %w", restored.Error))
+		}
+		return err
+	}
+	return r.sessions.RevokeAll(ctx, user.ID)
+}
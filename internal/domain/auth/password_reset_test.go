@@ -0,0 +1,72 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/domain/user"
+	"net/http"
+	"testing"
+)
+
+const resetSubject = "Reset your Konsultn password"
+
+func TestPasswordReset(t *testing.T) {
+	mail := &mailbox{}
+	s := newServer(t, auth.Dependencies{Mailer: mail, AppURL: "http://app"})
+	s.register("ada@example.com", "correct-horse")
+	token := s.login("ada@example.com", "correct-horse")["id_token"].(string)
+
+	for _, email := range []string{"ada@example.com", "nobody@example.com"} {
+		if code, response := s.call(http.MethodPost, "/api/auth/password/forgot", "", map[string]string{"email": email}); code != http.StatusAccepted {
+			t.Fatalf("forgot password of %s = %d %v", email, code, response)
+		}
+	}
+	resetToken := mail.link(t, resetSubject)
+
+	reset := map[string]string{"token": resetToken, "password": "new-password"}
+	if code, response := s.call(http.MethodPost, "/api/auth/password/reset", "", reset); code != http.StatusOK {
+		t.Fatalf("reset = %d %v", code, response)
+	}
+	if code, _ := s.call(http.MethodPost, "/api/auth/password/reset", "", reset); code != http.StatusBadRequest {
+		t.Fatalf("reusing a reset token = %d", code)
+	}
+	if code, _ := s.call(http.MethodGet, "/api/auth/sessions", token, nil); code != http.StatusUnauthorized {
+		t.Fatalf("session started before the reset = %d", code)
+	}
+	s.login("ada@example.com", "new-password")
+}
+
+// unavailableProvider fails to set passwords
+type unavailableProvider struct {
+	auth.IdentityProvider
+}
+
+var errUnavailable = errors.New("identity provider unavailable")
+
+func (unavailableProvider) SetPassword(context.Context, string, string) error {
+	return errUnavailable
+}
+
+func TestPasswordResetKeepsTokenOnProviderFailure(t *testing.T) {
+	ctx := context.Background()
+	mail := &mailbox{}
+	s := newServer(t, auth.Dependencies{})
+	s.register("ada@example.com", "correct-horse")
+	repo, sessions := user.NewRepository(s.db), auth.NewSessionStore(s.db)
+
+	failing := auth.NewPasswordResets(repo, unavailableProvider{s.provider}, sessions, mail, "http://app")
+	if err := failing.Request(ctx, "ada@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	token := mail.link(t, resetSubject)
+	if err := failing.Reset(ctx, token, "new-password"); !errors.Is(err, errUnavailable) {
+		t.Fatalf("Reset = %v, want the provider error", err)
+	}
+
+	resets := auth.NewPasswordResets(repo, s.provider, sessions, mail, "http://app")
+	if err := resets.Reset(ctx, token, "new-password"); err != nil {
+		t.Fatalf("the token was burnt by the failed reset: %v", err)
+	}
+	s.login("ada@example.com", "new-password")
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"firebase.google.com/go/v4/auth"
+	"fmt"
+	"gorm.io/gorm"
+	"konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared/security"
+	"konsultn-api/pkg/mailer"
+	"os"
+	"strings"
+	"time"
+)
+
+// Errors returned by identity providers for rejected requests
+var (
+	ErrAccountExists       = errors.New("an account with this email already exists")
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+)
+
+// Tokens are issued to a signed in account
+type Tokens struct {
+	// UID is the subject of the account at the identity provider
+	UID          string
+	IDToken      string
+	RefreshToken string
+	ExpiresIn    time.Duration
+	// AuthTime is when the account signed in, it is kept when the tokens are refreshed
+	AuthTime time.Time
+}
+
+// IdentityProvider manages the credentials of accounts and issues their tokens
+type IdentityProvider interface {
+	// SignUp creates an account with a password and returns its UID
+	// An empty password creates an account that can only sign in through linked social providers.
+	// Returns ErrAccountExists when the email is already taken
+	SignUp(ctx context.Context, email, password string) (string, error)
+	// SignIn checks the password of an account and issues its tokens
+	// Returns ErrInvalidCredentials when the email or password is wrong
+	SignIn(ctx context.Context, email, password string) (*Tokens, error)
+	// SignInAs issues the tokens of an account the caller authenticated by other means, like a social provider
+	// Returns ErrAccountNotFound when the account does not exist
+	SignInAs(ctx context.Context, uid string) (*Tokens, error)
+	// Refresh issues new tokens for a refresh token, the new tokens carry the current claims of the account
+	// Returns ErrInvalidRefreshToken when the refresh token is invalid, revoked or expired
+	Refresh(ctx context.Context, refreshToken string) (*Tokens, error)
+	// SetPassword replaces the password of the account
+	// Returns ErrAccountNotFound when the account does not exist
+	SetPassword(ctx context.Context, uid, password string) error
+	// HasPassword reports whether the account can sign in with a password
+	HasPassword(ctx context.Context, uid string) (bool, error)
+	// SetClaims replaces the custom claims of the account, they are added to tokens issued afterwards
+	SetClaims(ctx context.Context, uid string, claims map[string]interface{}) error
+	// DeleteUser deletes the account and its credentials
+	DeleteUser(ctx context.Context, uid string) error
+}
+
+// Identity providers selectable by configuration
+const (
+	ProviderFirebase = "firebase"
+	ProviderLocal    = "local"
+)
+
+// Config selects and configures the identity provider
+type Config struct {
+	// Provider is one of ProviderFirebase or ProviderLocal
+	Provider string
+	// FirebaseAPIKey is the web API key of the Firebase project, used for the REST sign in endpoints
+	FirebaseAPIKey string
+	// AppURL is the base URL of the web app, links in auth emails point to its pages
+	AppURL string
+	// EncryptionKey is the base64 encoded 32 byte key encrypting TOTP secrets and social sign in state,
+	// two-factor authentication and social sign in are off without it
+	EncryptionKey string
+	// SocialRedirectURL is the page of the web app social providers redirect back to, suffixed with /<provider>
+	SocialRedirectURL string
+	// AttemptStore is one of AttemptStoreMemory or AttemptStorePostgres, it counts sign in attempts
+	AttemptStore string
+}
+
+// ConfigFromEnv reads the identity provider configuration from the environment:
+// IDENTITY_PROVIDER (firebase by default), FIREBASE_API_KEY, APP_URL, AUTH_ENCRYPTION_KEY,
+// OIDC_REDIRECT_URL (APP_URL/auth/callback by default) and AUTH_ATTEMPT_STORE (memory by default)
+func ConfigFromEnv() Config {
+	provider := strings.ToLower(os.Getenv("IDENTITY_PROVIDER"))
+	if provider == "" {
+		provider = ProviderFirebase
+	}
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:3000"
+	}
+	appURL = strings.TrimSuffix(appURL, "/")
+	redirectURL := os.Getenv("OIDC_REDIRECT_URL")
+	if redirectURL == "" {
+		redirectURL = appURL + "/auth/callback"
+	}
+	attemptStore := strings.ToLower(os.Getenv("AUTH_ATTEMPT_STORE"))
+	if attemptStore == "" {
+		attemptStore = AttemptStoreMemory
+	}
+
+	return Config{
+		Provider:          provider,
+		FirebaseAPIKey:    os.Getenv("FIREBASE_API_KEY"),
+		AppURL:            appURL,
+		EncryptionKey:     os.Getenv("AUTH_ENCRYPTION_KEY"),
+		SocialRedirectURL: strings.TrimSuffix(redirectURL, "/"),
+		AttemptStore:      attemptStore,
+	}
+}
+
+// NewIdentityProvider builds the provider selected by the configuration
+// The Firebase client is only used by the Firebase provider, the local provider signs its tokens
+// with the signer of the token verifier configuration, so the hmac verifier must be selected
+func NewIdentityProvider(cfg Config, db *gorm.DB, client *auth.Client, tokens security.Config) (IdentityProvider, error) {
+	switch cfg.Provider {
+	case ProviderFirebase:
+		if client == nil {
+			return nil, errors.New("the firebase identity provider requires an initialized firebase auth client")
+		}
+		if cfg.FirebaseAPIKey == "" {
+			return nil, errors.New("the firebase identity provider requires FIREBASE_API_KEY")
+		}
+		return NewFirebaseIdentityProvider(client, cfg.FirebaseAPIKey), nil
+	case ProviderLocal:
+		signer, err := security.NewTokenSigner(tokens)
+		if err != nil {
+			return nil, fmt.Errorf("the local identity provider cannot issue tokens: %w", err)
+		}
+		return NewLocalIdentityProvider(user.NewRepository(db), signer, []byte(tokens.HMACSecret)), nil
+	default:
+		return nil, fmt.Errorf("unknown identity provider '%s'", cfg.Provider)
+	}
+}
+
+// Dependencies are the services used by the auth handlers, built at startup and passed to RegisterRoutes
+type Dependencies struct {
+	IdentityProvider IdentityProvider
+	// Mailer sends auth emails, they are logged when it is nil
+	Mailer mailer.Mailer
+	// AppURL is the base URL of the links in auth emails, see Config.AppURL
+	AppURL string
+	// SecretCipher encrypts TOTP secrets and social sign in state,
+	// two-factor authentication and social sign in are off when it is nil
+	SecretCipher *security.Cipher
+	// SocialProviders are the social providers users can sign in with
+	SocialProviders []OIDCConfig
+	// SocialRedirectURL is the page social providers redirect back to, see Config.SocialRedirectURL
+	SocialRedirectURL string
+	// TeamAdmins tells who administers teams, team API keys cannot be managed when it is nil
+	TeamAdmins TeamAdmins
+	// Attempts counts sign in and sign up attempts, in a memory store of the routes when it is nil
+	Attempts AttemptStore
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/shared/security"
+	"net/http"
+	"slices"
+)
+
+type UpdateRolesRequest struct {
+	Roles []string `json:"roles" binding:"required"`
+}
+
+type RolesResponse struct {
+	UserID string   `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
+
+// GetUserRoles returns the platform roles of a user
+func (h *Handler) GetUserRoles(ctx *gin.Context) {
+	id := ctx.Param("id")
+	user, err := h.repo.FindById(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user with id " + id + " not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RolesResponse{UserID: user.ID, Roles: user.Roles})
+}
+
+// UpdateUserRoles replaces the platform roles of a user and syncs them to its token claims
+func (h *Handler) UpdateUserRoles(ctx *gin.Context) {
+	var req UpdateRolesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	roles := make([]string, 0, len(req.Roles))
+	for _, role := range req.Roles {
+		if !security.IsPlatformRole(role) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid role: " + role})
+			return
+		}
+		if !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+
+	id := ctx.Param("id")
+	user, err := h.repo.FindById(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user with id " + id + " not found"})
+		return
+	}
+
+	encoded, _ := json.Marshal(roles)
+	if err := h.repo.Updates(user, types.UpdateMap{"roles": datatypes.JSON(encoded)}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update roles"})
+		return
+	}
+	user.Roles = roles
+
+	// The users table stays the source of truth, claims that failed to sync are synced on the next login
+	if err := h.syncClaims(ctx, user); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Roles updated but the token claims could not be synced"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RolesResponse{UserID: user.ID, Roles: roles})
+}
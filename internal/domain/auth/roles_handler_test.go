@@ -0,0 +1,47 @@
+package auth_test
+
+import (
+	"context"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/shared/security"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestUpdateUserRoles(t *testing.T) {
+	s := newServer(t, auth.Dependencies{})
+	s.register("admin@example.com", "correct-horse")
+	userID := s.register("ada@example.com", "correct-horse")
+	s.activate("admin@example.com")
+	s.activate("ada@example.com")
+	s.db.Exec(`UPDATE users SET roles = '["platform_admin"]' WHERE email = 'admin@example.com'`)
+	path := "/api/admin/users/" + userID + "/roles"
+
+	ada := s.login("ada@example.com", "correct-horse")["id_token"].(string)
+	if code, _ := s.call(http.MethodGet, path, ada, nil); code != http.StatusForbidden {
+		t.Fatalf("roles read by a user without platform_admin = %d", code)
+	}
+
+	admin := s.login("admin@example.com", "correct-horse")["id_token"].(string)
+	code, response := s.call(http.MethodPut, path, admin, map[string]interface{}{
+		"roles": []string{security.RoleClient, security.RoleClient, security.RoleFreelancer},
+	})
+	if code != http.StatusOK {
+		t.Fatalf("update roles = %d %v", code, response)
+	}
+	if roles := response["roles"].([]interface{}); len(roles) != 2 {
+		t.Fatalf("roles are not deduplicated: %v", roles)
+	}
+	if code, _ := s.call(http.MethodPut, path, admin, map[string]interface{}{"roles": []string{"bogus"}}); code != http.StatusBadRequest {
+		t.Fatalf("unknown role = %d", code)
+	}
+
+	principal, err := s.verifier.Verify(context.Background(), s.login("ada@example.com", "correct-horse")["id_token"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(principal.Roles, []string{security.RoleClient, security.RoleFreelancer}) {
+		t.Fatalf("token roles = %v", principal.Roles)
+	}
+}
@@ -4,15 +4,90 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"konsultn-api/internal/domain/user"
+	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/ids"
+	"konsultn-api/internal/shared/security"
+	"konsultn-api/pkg/mailer"
+	"time"
 )
 
-func RegisterRoutes(api *gin.RouterGroup, db *gorm.DB) {
+func RegisterRoutes(api *gin.RouterGroup, db *gorm.DB, deps Dependencies) {
+	if deps.Mailer == nil {
+		deps.Mailer = mailer.NewLogMailer()
+	}
+	if deps.Attempts == nil {
+		deps.Attempts = NewMemoryAttemptStore()
+	}
+	socialProviders := make(map[string]*OIDCProvider, len(deps.SocialProviders))
+	for _, cfg := range deps.SocialProviders {
+		socialProviders[cfg.Name] = NewOIDCProvider(cfg)
+	}
+
 	auth := api.Group("/auth")
 	repo := user.NewRepository(db)
-	h := NewHandler(repo)
+	sessionStore := NewSessionStore(db)
+	resets := NewPasswordResets(repo, deps.IdentityProvider, sessionStore, deps.Mailer, deps.AppURL)
+	secondFactor := NewTwoFactor(repo, db, deps.SecretCipher)
+	verifications := NewEmailVerifications(repo, deps.Mailer, deps.AppURL)
+	social := NewSocialLogins(repo, db, deps.IdentityProvider, socialProviders, deps.SecretCipher, deps.SocialRedirectURL)
+	apiKeys := NewAPIKeys(db, deps.TeamAdmins)
+	lockout := NewLockout(deps.Attempts)
+	h := NewHandler(repo, deps.IdentityProvider, sessionStore, resets, secondFactor, verifications, social, apiKeys, lockout)
 	{
 		auth.POST("/register", h.CreateUser)
 		auth.POST("/login", h.Login)
-		//auth.POST("/refresh")
+		auth.POST("/refresh", h.Refresh)
+		auth.POST("/logout", middleware.AuthMiddleware(middleware.AllowUnverified), h.Logout)
+		auth.POST("/password/forgot", middleware.RateLimit(5, 15*time.Minute, middleware.ByClientIP), h.ForgotPassword)
+		auth.POST("/password/reset", middleware.RateLimit(10, 15*time.Minute, middleware.ByClientIP), h.ResetPassword)
+		auth.GET("/verify", middleware.RateLimit(10, 15*time.Minute, middleware.ByClientIP), h.VerifyEmail)
+		auth.POST("/verify/resend", middleware.RateLimit(5, 15*time.Minute, middleware.ByClientIP), h.ResendVerification)
+
+		auth.POST("/2fa/verify", middleware.RateLimit(10, 5*time.Minute, middleware.ByClientIP), h.VerifyTwoFactor)
+
+		twoFactor := auth.Group("/2fa", middleware.AuthMiddleware(middleware.AllowUnverified))
+		{
+			twoFactor.GET("", h.TwoFactorStatus)
+			twoFactor.POST("/enroll", h.EnrollTwoFactor)
+			twoFactor.POST("/enable", h.EnableTwoFactor)
+			twoFactor.POST("/disable", h.DisableTwoFactor)
+			twoFactor.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+		}
+
+		auth.GET("/social", h.ListSocialProviders)
+		auth.GET("/social/:provider", h.StartSocialLogin)
+		auth.POST("/social/:provider/callback", middleware.RateLimit(10, 5*time.Minute, middleware.ByClientIP), h.SocialCallback)
+
+		identities := auth.Group("/identities", middleware.AuthMiddleware())
+		{
+			identities.GET("", h.ListIdentities)
+			identities.POST("/:provider", h.StartLinkIdentity)
+			identities.POST("/:provider/callback", h.LinkIdentity)
+			identities.DELETE("/:provider", h.UnlinkIdentity)
+		}
+
+		keys := auth.Group("/api-keys", middleware.AuthMiddleware(), middleware.ValidateIDParams(ids.ULID))
+		{
+			keys.GET("", h.ListAPIKeys)
+			keys.POST("", h.CreateAPIKey)
+			keys.DELETE("/:id", h.RevokeAPIKey)
+			keys.GET("/:id/events", h.ListAPIKeyEvents)
+		}
+
+		sessions := auth.Group("/sessions", middleware.AuthMiddleware(middleware.AllowUnverified), middleware.ValidateIDParams(ids.ULID))
+		{
+			sessions.GET("", h.ListSessions)
+			sessions.DELETE("", h.RevokeOtherSessions)
+			sessions.DELETE("/:id", h.RevokeSession)
+		}
+	}
+
+	admin := api.Group("/admin/users", middleware.AuthMiddleware(), middleware.RequireRole(security.RolePlatformAdmin), middleware.ValidateIDParams(ids.ULID))
+	{
+		admin.GET("/:id/roles", h.GetUserRoles)
+		admin.PUT("/:id/roles", h.UpdateUserRoles)
+		admin.PUT("/:id/status", h.UpdateUserStatus)
+		admin.GET("/:id/lockout", h.GetUserLockout)
+		admin.DELETE("/:id/lockout", h.UnlockUser)
 	}
 }
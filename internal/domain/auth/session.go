@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared"
+	"konsultn-api/internal/shared/crud"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/shared/security"
+	"konsultn-api/internal/shared/sensitive"
+	"time"
+)
+
+// ErrSessionNotFound is returned for sessions that do not exist, are revoked or belong to another user
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is a sign in of a user on a device, it lives as long as its refresh token is used
+// Sessions are matched to ID tokens by the UID and auth_time claims, which refreshed tokens keep
+type Session struct {
+	shared.ULID      `gorm:"embedded"`
+	UserID           string `gorm:"not null;index"`
+	UID              string `gorm:"size:255;not null;index:idx_session_uid_auth_time"`
+	AuthTime         int64  `gorm:"not null;index:idx_session_uid_auth_time"` // unix seconds
+	RefreshTokenHash string `gorm:"size:64;index" json:"-" sensitive:"true"`
+	UserAgent        string `gorm:"size:512"`
+	IPAddress        string `gorm:"size:64"`
+	LastUsedAt       time.Time
+	RevokedAt        *time.Time `gorm:"index"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// MarshalJSON leaves the sensitive fields out, or fails in strict mode, as sessions are rendered as SessionResponse
+func (m Session) MarshalJSON() ([]byte, error) {
+	type plain Session
+	return sensitive.Marshal(plain(m))
+}
+
+// SessionStore records the sessions of users and checks ID tokens against them
+type SessionStore struct {
+	repo  *crud.Repository[Session, string]
+	users *user2.Repository[user2.User]
+}
+
+func NewSessionStore(db *gorm.DB) *SessionStore {
+	return &SessionStore{repo: crud.NewRepository[Session, string](db), users: user2.NewRepository(db)}
+}
+
+// Start records the session of tokens issued by a sign in
+// Sign ins of the same account within the same second cannot be told apart by their tokens, they share a session
+func (s *SessionStore) Start(ctx context.Context, userID string, tokens *Tokens, userAgent, ip string) (*Session, error) {
+	existing, err := first(s.active(ctx).Where(ColSessionUID, tokens.UID).Where(ColSessionAuthTime, tokens.AuthTime.Unix()))
+	if err == nil {
+		err = s.repo.WithContext(ctx).Updates(existing, types.UpdateMap{
+			ColSessionRefreshTokenHash.Name(): digest(tokens.RefreshToken),
+			ColSessionUserAgent.Name():        userAgent,
+			ColSessionIPAddress.Name():        ip,
+			ColSessionLastUsedAt.Name():       time.Now(),
+		})
+		return existing, err
+	}
+	if !errors.Is(err, ErrSessionNotFound) {
+		return nil, err
+	}
+
+	session := &Session{
+		UserID:           userID,
+		UID:              tokens.UID,
+		AuthTime:         tokens.AuthTime.Unix(),
+		RefreshTokenHash: digest(tokens.RefreshToken),
+		UserAgent:        userAgent,
+		IPAddress:        ip,
+		LastUsedAt:       time.Now(),
+	}
+	return s.repo.WithContext(ctx).Save(session)
+}
+
+// FindByRefreshToken returns the active session holding the refresh token
+func (s *SessionStore) FindByRefreshToken(ctx context.Context, refreshToken string) (*Session, error) {
+	return first(s.active(ctx).Where(ColSessionRefreshTokenHash, digest(refreshToken)))
+}
+
+// Rotate stores the refresh token that replaced the one of the session
+func (s *SessionStore) Rotate(ctx context.Context, session *Session, tokens *Tokens, ip string) error {
+	return s.repo.WithContext(ctx).Updates(session, types.UpdateMap{
+		ColSessionRefreshTokenHash.Name(): digest(tokens.RefreshToken),
+		ColSessionIPAddress.Name():        ip,
+		ColSessionLastUsedAt.Name():       time.Now(),
+	})
+}
+
+// List returns the active sessions of a user, most recently used first
+func (s *SessionStore) List(ctx context.Context, userID string) ([]Session, error) {
+	return s.active(ctx).
+		Where(ColSessionUserID, userID).
+		OrderBy(ColSessionLastUsedAt, "DESC").
+		All()
+}
+
+// Current returns the active session the principal signed in with
+func (s *SessionStore) Current(ctx context.Context, principal *security.Principal) (*Session, error) {
+	if principal.AuthTime.IsZero() {
+		return nil, ErrSessionNotFound
+	}
+	return first(s.active(ctx).Where(ColSessionUID, principal.UID).Where(ColSessionAuthTime, principal.AuthTime.Unix()))
+}
+
+// Revoke revokes an active session of a user
+func (s *SessionStore) Revoke(ctx context.Context, userID, id string) error {
+	session, err := first(s.active(ctx).Where(ColSessionID, id).Where(ColSessionUserID, userID))
+	if err != nil {
+		return err
+	}
+	return s.repo.WithContext(ctx).Updates(session, types.UpdateMap{ColSessionRevokedAt.Name(): time.Now()})
+}
+
+// RevokeAll revokes the active sessions of a user except the ones whose ID is in keep
+func (s *SessionStore) RevokeAll(ctx context.Context, userID string, keep ...string) error {
+	db := s.repo.WithContext(ctx).GetDB().
+		Model(&Session{}).
+		Where(fmt.Sprintf("%s = ? AND %s IS NULL", ColSessionUserID, ColSessionRevokedAt), userID)
+	if len(keep) > 0 {
+		db = db.Where(fmt.Sprintf("%s NOT IN ?", ColSessionID), keep)
+	}
+	return db.Update(ColSessionRevokedAt.Name(), time.Now()).Error
+}
+
+// Revoked reports whether the session of the principal was revoked
+// Tokens without a recorded session, like ones issued to clients signing in with Firebase directly, skipped
+// the two-factor challenge, so they are only accepted for accounts without two-factor authentication
+func (s *SessionStore) Revoked(ctx context.Context, principal *security.Principal) (bool, error) {
+	if !principal.AuthTime.IsZero() {
+		session, err := s.repo.WithContext(ctx).Query().
+			Where(ColSessionUID, principal.UID).
+			Where(ColSessionAuthTime, principal.AuthTime.Unix()).
+			First()
+		if err == nil {
+			return session.RevokedAt != nil, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, err
+		}
+	}
+
+	return s.users.WithContext(ctx).Query().
+		Where(user2.ColUserUID, principal.UID).
+		Where(user2.ColUserTwoFactorEnabled, true).
+		Exists()
+}
+
+func (s *SessionStore) active(ctx context.Context) types.QueryBuilder[Session] {
+	return s.repo.WithContext(ctx).Query().WhereNull(ColSessionRevokedAt)
+}
+
+func first(query types.QueryBuilder[Session]) (*Session, error) {
+	session, err := query.First()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return session, nil
+}
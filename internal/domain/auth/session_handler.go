@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"konsultn-api/internal/middleware"
+	"net/http"
+	"time"
+)
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+// Logout revokes the session the caller signed in with
+func (h *Handler) Logout(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+
+	session, err := h.sessions.Current(ctx, principal)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			ctx.JSON(http.StatusOK, gin.H{"message": "logged out"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	if err := h.sessions.Revoke(ctx, session.UserID, session.ID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// ListSessions lists the active sessions of the caller
+func (h *Handler) ListSessions(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+
+	sessions, err := h.sessions.List(ctx, principal.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch sessions"})
+		return
+	}
+
+	currentID := ""
+	if current, err := h.sessions.Current(ctx, principal); err == nil {
+		currentID = current.ID
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.ID == currentID,
+		})
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RevokeSession revokes a session of the caller, e.g. the one of a lost device
+func (h *Handler) RevokeSession(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+
+	if err := h.sessions.Revoke(ctx, principal.UserID, ctx.Param("id")); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
+
+// RevokeOtherSessions revokes every session of the caller except the current one
+func (h *Handler) RevokeOtherSessions(ctx *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(ctx)
+
+	var keep []string
+	if current, err := h.sessions.Current(ctx, principal); err == nil {
+		keep = append(keep, current.ID)
+	}
+
+	if err := h.sessions.RevokeAll(ctx, principal.UserID, keep...); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "sessions revoked"})
+}
@@ -0,0 +1,58 @@
+package auth_test
+
+import (
+	"konsultn-api/internal/domain/auth"
+	"net/http"
+	"testing"
+)
+
+func TestSessionLifecycle(t *testing.T) {
+	s := newServer(t, auth.Dependencies{})
+	s.register("ada@example.com", "correct-horse")
+
+	first := s.login("ada@example.com", "correct-horse")
+	token, refreshToken := first["id_token"].(string), first["refresh_token"].(string)
+	code, sessions := s.call(http.MethodGet, "/api/auth/sessions", token, nil)
+	if code != http.StatusOK {
+		t.Fatalf("list sessions = %d %v", code, sessions)
+	}
+
+	code, refreshed := s.call(http.MethodPost, "/api/auth/refresh", "", map[string]string{"refresh_token": refreshToken})
+	if code != http.StatusOK {
+		t.Fatalf("refresh = %d %v", code, refreshed)
+	}
+	if code, _ := s.call(http.MethodPost, "/api/auth/refresh", "", map[string]string{"refresh_token": refreshToken}); code != http.StatusUnauthorized {
+		t.Fatalf("reusing a rotated refresh token = %d", code)
+	}
+
+	if code, response := s.call(http.MethodPost, "/api/auth/logout", refreshed["id_token"].(string), nil); code != http.StatusOK {
+		t.Fatalf("logout = %d %v", code, response)
+	}
+	if code, _ := s.call(http.MethodGet, "/api/auth/sessions", token, nil); code != http.StatusUnauthorized {
+		t.Fatalf("token of a revoked session = %d", code)
+	}
+	if code, _ := s.call(http.MethodPost, "/api/auth/refresh", "", map[string]string{"refresh_token": refreshed["refresh_token"].(string)}); code != http.StatusUnauthorized {
+		t.Fatalf("refresh token of a revoked session = %d", code)
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	s := newServer(t, auth.Dependencies{})
+	s.register("ada@example.com", "correct-horse")
+	s.login("ada@example.com", "correct-horse")
+	// Sign ins within the same second share a session, backdate the earlier ones
+	s.db.Exec("UPDATE sessions SET auth_time = auth_time - 10")
+	other := s.login("ada@example.com", "correct-horse")
+	var before int64
+	s.db.Table("sessions").Count(&before)
+
+	if code, response := s.call(http.MethodDelete, "/api/auth/sessions", other["id_token"].(string), nil); code != http.StatusOK {
+		t.Fatalf("revoke other sessions = %d %v", code, response)
+	}
+	var total, active int64
+	s.db.Table("sessions").Count(&total)
+	s.db.Table("sessions").Where("revoked_at IS NULL").Count(&active)
+	if total != before || active != 1 {
+		t.Fatalf("%d of %d sessions active, want only the current one", active, total)
+	}
+}
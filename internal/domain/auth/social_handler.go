@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/crud/types"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type SocialCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+type IdentityResponse struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListSocialProviders lists the social providers users can sign in with
+func (h *Handler) ListSocialProviders(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"providers": h.social.Providers()})
+}
+
+// StartSocialLogin returns the consent page of a provider, the client keeps the state to send it back with the code
+func (h *Handler) StartSocialLogin(ctx *gin.Context) {
+	authURL, state, err := h.social.Start(ctx, ctx.Param("provider"), "")
+	if err != nil {
+		socialError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"authorization_url": authURL, "state": state})
+}
+
+// SocialCallback signs in with the code a provider redirected back with
+// Unknown identities sign up, or are merged into the account with the same verified email
+func (h *Handler) SocialCallback(ctx *gin.Context) {
+	var req SocialCallbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	identity, err := h.social.Complete(ctx, ctx.Param("provider"), req.Code, req.State, "")
+	if err != nil {
+		socialError(ctx, err)
+		return
+	}
+
+	user, err := h.social.FindUser(ctx, identity)
+	if errors.Is(err, ErrAccountNotFound) {
+		user, err = h.createSocialAccount(ctx, identity)
+	}
+	if err != nil {
+		socialError(ctx, err)
+		return
+	}
+
+	tokens, err := h.provider.SignInAs(ctx, user.UID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
+	h.finishSignIn(ctx, user, tokens)
+}
+
+// ListIdentities lists the social accounts linked to the caller
+func (h *Handler) ListIdentities(ctx *gin.Context) {
+	user, ok := h.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	identities, err := h.social.Identities(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch linked accounts"})
+		return
+	}
+
+	response := make([]IdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		response = append(response, IdentityResponse{
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// StartLinkIdentity returns the consent page of a provider whose account is to be linked to the caller
+func (h *Handler) StartLinkIdentity(ctx *gin.Context) {
+	authURL, state, err := h.social.Start(ctx, ctx.Param("provider"), middleware.CurrentUserID(ctx))
+	if err != nil {
+		socialError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"authorization_url": authURL, "state": state})
+}
+
+// LinkIdentity links the account of the code a provider redirected back with to the caller
+func (h *Handler) LinkIdentity(ctx *gin.Context) {
+	var req SocialCallbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	user, ok := h.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	identity, err := h.social.Complete(ctx, ctx.Param("provider"), req.Code, req.State, user.ID)
+	if err != nil {
+		socialError(ctx, err)
+		return
+	}
+
+	if err := h.social.Link(ctx, user, identity); err != nil {
+		socialError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": identity.Provider + " account linked"})
+}
+
+// UnlinkIdentity unlinks the social account of a provider from the caller
+func (h *Handler) UnlinkIdentity(ctx *gin.Context) {
+	user, ok := h.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.social.Unlink(ctx, user, ctx.Param("provider")); err != nil {
+		socialError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": ctx.Param("provider") + " account unlinked"})
+}
+
+// createSocialAccount signs up a passwordless account for an identity
+// Accounts whose email the provider verified are active right away, the others have to verify it like any sign up
+func (h *Handler) createSocialAccount(ctx *gin.Context, identity *ExternalIdentity) (*user2.User, error) {
+	uid, err := h.provider.SignUp(ctx, identity.Email, "")
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName, _ := strings.Cut(identity.Name, " ")
+	created, err := h.saveProfile(&user2.User{
+		UID:       uid,
+		Email:     identity.Email,
+		FirstName: firstName,
+		LastName:  lastName,
+		Status:    user2.StatusPending,
+	})
+	if err != nil {
+		_ = h.provider.DeleteUser(ctx, uid)
+		return nil, err
+	}
+
+	if identity.EmailVerified {
+		if err := h.repo.Updates(created, types.UpdateMap{user2.ColUserStatus.Name(): user2.StatusActive.String()}); err != nil {
+			return nil, err
+		}
+		created.Status = user2.StatusActive
+	}
+
+	if err := h.social.Link(ctx, created, identity); err != nil {
+		return nil, err
+	}
+
+	if created.Status == user2.StatusPending {
+		background, pending := context.WithoutCancel(ctx.Request.Context()), *created
+		go func() {
+			if err := h.verifications.Send(background, &pending); err != nil {
+				log.Printf("failed to send email verification: %v", err)
+			}
+		}()
+	}
+	return created, nil
+}
+
+func socialError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrSocialUnavailable):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUnknownSocialProvider), errors.Is(err, ErrIdentityNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidSocialState), errors.Is(err, ErrSocialEmailRequired):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSocialSignInFailed):
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication failed"})
+	case errors.Is(err, ErrIdentityLinked), errors.Is(err, ErrProviderAlreadyLinked), errors.Is(err, ErrLastSignInMethod),
+		errors.Is(err, ErrUnverifiedAccountMerge), errors.Is(err, ErrAccountExists):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "social sign in failed"})
+	}
+}
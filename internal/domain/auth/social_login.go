@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared"
+	"konsultn-api/internal/shared/crud"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/shared/security"
+	"konsultn-api/internal/shared/sensitive"
+	"slices"
+	"time"
+)
+
+const (
+	// socialStateTTL is how long a user has to complete the consent page of a social provider
+	socialStateTTL = 10 * time.Minute
+	// purposeSocialState binds encrypted social states to their use, see security.Cipher
+	purposeSocialState = "social-state"
+)
+
+// Errors returned for rejected social sign ins and links
+var (
+	ErrSocialUnavailable      = errors.New("social sign in is not configured")
+	ErrUnknownSocialProvider  = errors.New("unknown social provider")
+	ErrInvalidSocialState     = errors.New("invalid or expired social sign in state")
+	ErrSocialEmailRequired    = errors.New("the social provider did not share an email address")
+	ErrIdentityLinked         = errors.New("this social account is linked to another account")
+	ErrProviderAlreadyLinked  = errors.New("a social account of this provider is already linked")
+	ErrIdentityNotFound       = errors.New("no social account of this provider is linked")
+	ErrLastSignInMethod       = errors.New("the last way to sign in cannot be unlinked, set a password first")
+	ErrUnverifiedAccountMerge = errors.New("an unverified account uses this email, sign in with its password to link")
+)
+
+// LinkedIdentity is an account at a social provider that signs in a user
+type LinkedIdentity struct {
+	shared.ULID `gorm:"embedded"`
+	UserID      string `gorm:"not null;uniqueIndex:idx_identity_user_provider"`
+	Provider    string `gorm:"size:50;not null;uniqueIndex:idx_identity_provider_subject;uniqueIndex:idx_identity_user_provider"`
+	Subject     string `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject" sensitive:"true"`
+	Email       string `gorm:"size:255"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// MarshalJSON leaves the sensitive fields out, or fails in strict mode, as identities are rendered as IdentityResponse
+func (m LinkedIdentity) MarshalJSON() ([]byte, error) {
+	type plain LinkedIdentity
+	return sensitive.Marshal(plain(m))
+}
+
+// socialState travels through the consent page of a provider, encrypted so the client can neither read nor forge it
+// A state started by a signed in user links the identity to them instead of signing in
+type socialState struct {
+	Provider  string `json:"provider"`
+	Nonce     string `json:"nonce"`
+	Verifier  string `json:"verifier"`
+	UserID    string `json:"user_id,omitempty"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// SocialLogins signs users in with social providers and manages the identities linked to their accounts
+type SocialLogins struct {
+	repo        *user2.Repository[user2.User]
+	identities  *crud.Repository[LinkedIdentity, string]
+	provider    IdentityProvider
+	providers   map[string]*OIDCProvider
+	cipher      *security.Cipher
+	redirectURL string
+}
+
+// NewSocialLogins returns the social sign in of the providers, consent pages redirect to redirectURL/<provider>
+// Without a cipher no state can be issued, so social sign in is unavailable
+func NewSocialLogins(repo *user2.Repository[user2.User], db *gorm.DB, provider IdentityProvider, providers map[string]*OIDCProvider, cipher *security.Cipher, redirectURL string) *SocialLogins {
+	return &SocialLogins{
+		repo:        repo,
+		identities:  crud.NewRepository[LinkedIdentity, string](db),
+		provider:    provider,
+		providers:   providers,
+		cipher:      cipher,
+		redirectURL: redirectURL,
+	}
+}
+
+// Providers lists the names of the configured providers
+func (s *SocialLogins) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Start returns the consent page URL of a provider and the state the client sends back with the code
+// An empty userID starts a sign in, otherwise a link to that user
+func (s *SocialLogins) Start(ctx context.Context, name, userID string) (string, string, error) {
+	provider, err := s.lookup(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	payload, err := json.Marshal(socialState{
+		Provider:  name,
+		Nonce:     nonce,
+		Verifier:  verifier,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(socialStateTTL).Unix(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	state, err := s.cipher.Encrypt(purposeSocialState, string(payload))
+	if err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL, err := provider.AuthCodeURL(ctx, s.redirectURI(name), state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// Complete redeems the code of a consent page started by Start with the same provider and user
+func (s *SocialLogins) Complete(ctx context.Context, name, code, state, userID string) (*ExternalIdentity, error) {
+	provider, err := s.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := s.cipher.Decrypt(purposeSocialState, state)
+	if err != nil {
+		return nil, ErrInvalidSocialState
+	}
+	var st socialState
+	if err := json.Unmarshal([]byte(payload), &st); err != nil || time.Now().Unix() > st.ExpiresAt {
+		return nil, ErrInvalidSocialState
+	}
+	if st.Provider != name || st.UserID != userID {
+		return nil, ErrInvalidSocialState
+	}
+
+	return provider.Exchange(ctx, code, s.redirectURI(name), st.Verifier, st.Nonce)
+}
+
+// FindUser returns the user an identity signs in
+// Identities not linked yet are merged into the account using the same email, when the provider verified it and
+// so did the account. Returns ErrAccountNotFound when a new account has to be created for the identity
+func (s *SocialLogins) FindUser(ctx context.Context, identity *ExternalIdentity) (*user2.User, error) {
+	linked, err := s.identities.WithContext(ctx).Query().
+		Where(ColLinkedIdentityProvider, identity.Provider).
+		Where(ColLinkedIdentitySubject, identity.Subject).
+		First()
+	if err == nil {
+		return s.repo.WithContext(ctx).FindFirstBy(user2.ColUserID.Name(), linked.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" {
+		return nil, ErrSocialEmailRequired
+	}
+	account, err := s.repo.WithContext(ctx).FindFirstBy(user2.ColUserEmail.Name(), identity.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	// Merging into an account whose owner never proved the email would hand it to whoever registered it first
+	if !identity.EmailVerified || account.Status == user2.StatusPending {
+		return nil, ErrUnverifiedAccountMerge
+	}
+	if err := s.Link(ctx, account, identity); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Link links an identity to a user, the first linked identity is also kept on the social fields of the user
+func (s *SocialLogins) Link(ctx context.Context, user *user2.User, identity *ExternalIdentity) error {
+	repo := s.identities.WithContext(ctx)
+
+	existing, err := repo.Query().Where(ColLinkedIdentityProvider, identity.Provider).Where(ColLinkedIdentitySubject, identity.Subject).First()
+	if err == nil {
+		if existing.UserID != user.ID {
+			return ErrIdentityLinked
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	taken, err := repo.Query().Where(ColLinkedIdentityUserID, user.ID).Where(ColLinkedIdentityProvider, identity.Provider).Exists()
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrProviderAlreadyLinked
+	}
+
+	_, err = repo.Save(&LinkedIdentity{
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	if user.SocialProvider != "" {
+		return nil
+	}
+	updates := types.UpdateMap{
+		user2.ColUserSocialProvider.Name():       identity.Provider,
+		user2.ColUserSocialID.Name():             identity.Subject,
+		user2.ColUserSocialEmail.Name():          identity.Email,
+		user2.ColUserSocialProfilePicture.Name(): identity.Picture,
+	}
+	if user.ProfilePictureURL == "" && identity.Picture != "" {
+		updates[user2.ColUserProfilePictureURL.Name()] = identity.Picture
+	}
+	return s.repo.WithContext(ctx).Updates(user, updates)
+}
+
+// Unlink removes the identity of a provider from a user, unless it is the only way left to sign in
+func (s *SocialLogins) Unlink(ctx context.Context, user *user2.User, name string) error {
+	repo := s.identities.WithContext(ctx)
+
+	identity, err := repo.Query().Where(ColLinkedIdentityUserID, user.ID).Where(ColLinkedIdentityProvider, name).First()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrIdentityNotFound
+		}
+		return err
+	}
+
+	linked, err := s.Identities(ctx, user)
+	if err != nil {
+		return err
+	}
+	if len(linked) == 1 {
+		hasPassword, err := s.provider.HasPassword(ctx, user.UID)
+		if err != nil {
+			return err
+		}
+		if !hasPassword {
+			return ErrLastSignInMethod
+		}
+	}
+
+	if err := repo.Delete(identity, true); err != nil {
+		return err
+	}
+
+	if user.SocialProvider != name {
+		return nil
+	}
+	return s.repo.WithContext(ctx).Updates(user, types.UpdateMap{
+		user2.ColUserSocialProvider.Name():       "",
+		user2.ColUserSocialID.Name():             "",
+		user2.ColUserSocialEmail.Name():          "",
+		user2.ColUserSocialProfilePicture.Name(): "",
+	})
+}
+
+// Identities lists the identities linked to a user
+func (s *SocialLogins) Identities(ctx context.Context, user *user2.User) ([]LinkedIdentity, error) {
+	return s.identities.WithContext(ctx).Query().
+		Where(ColLinkedIdentityUserID, user.ID).
+		OrderBy(ColLinkedIdentityCreatedAt, "ASC").
+		All()
+}
+
+func (s *SocialLogins) lookup(name string) (*OIDCProvider, error) {
+	if s.cipher == nil {
+		return nil, ErrSocialUnavailable
+	}
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, ErrUnknownSocialProvider
+	}
+	return provider, nil
+}
+
+func (s *SocialLogins) redirectURI(name string) string {
+	return s.redirectURL + "/" + name
+}
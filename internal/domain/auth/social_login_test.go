@@ -0,0 +1,323 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v4"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/domain/user"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// issuer is an OpenID Connect provider that signs in whoever the test consents for
+// It also serves the userinfo and emails endpoints of GitHub, for providers configured without an issuer
+type issuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant
+	emails []map[string]interface{}
+}
+
+// grant is an authorization code waiting to be redeemed
+type grant struct {
+	claims    map[string]interface{}
+	challenge string
+}
+
+func newIssuer(t *testing.T) *issuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss := &issuer{t: t, key: key, grants: map[string]grant{}}
+	mux := http.NewServeMux()
+	iss.server = httptest.NewServer(mux)
+	t.Cleanup(iss.server.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 iss.server.URL,
+			"authorization_endpoint": iss.server.URL + "/authorize",
+			"token_endpoint":         iss.server.URL + "/token",
+			"jwks_uri":               iss.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", iss.token)
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		g, ok := iss.redeemed(r)
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(g.claims)
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := iss.redeemed(r); !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		iss.mu.Lock()
+		defer iss.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(iss.emails)
+	})
+	return iss
+}
+
+// token redeems a code once, checking its PKCE verifier, for an ID token and an access token naming the code
+func (iss *issuer) token(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	code := r.Form.Get("code")
+
+	iss.mu.Lock()
+	g, ok := iss.grants[code]
+	delete(iss.grants, code)
+	iss.grants["redeemed-"+code] = g
+	iss.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss": iss.server.URL,
+		"aud": "client",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for key, value := range g.claims {
+		claims[key] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	idToken, err := token.SignedString(iss.key)
+	if err != nil {
+		iss.t.Error(err)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "access_token": "redeemed-" + code})
+}
+
+// redeemed returns the grant of the access token a request is authorized with
+func (iss *issuer) redeemed(r *http.Request) (grant, bool) {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	g, ok := iss.grants[r.Header.Get("Authorization")[len("Bearer "):]]
+	return g, ok
+}
+
+// consent answers the authorization URL with a code signing in the identity of the claims
+func (iss *issuer) consent(authURL string, claims map[string]interface{}) string {
+	iss.t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		iss.t.Fatal(err)
+	}
+	query := parsed.Query()
+	claims["nonce"] = query.Get("nonce")
+
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	code := "code-" + query.Get("state")
+	iss.grants[code] = grant{claims: claims, challenge: query.Get("code_challenge")}
+	return code
+}
+
+// setEmails sets the addresses the emails endpoint lists
+func (iss *issuer) setEmails(emails ...map[string]interface{}) {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	iss.emails = emails
+}
+
+// newSocialServer serves the auth routes with the issuer configured as the mock provider and,
+// through its GitHub endpoints, as the github provider
+func newSocialServer(t *testing.T, iss *issuer) *server {
+	t.Helper()
+
+	return newServer(t, auth.Dependencies{
+		Mailer:       &mailbox{},
+		SecretCipher: testCipher(t),
+		SocialProviders: []auth.OIDCConfig{
+			{Name: "mock", Issuer: iss.server.URL, ClientID: "client", ClientSecret: "secret", Scopes: []string{"openid", "email"}},
+			{
+				Name:         "github",
+				ClientID:     "client",
+				ClientSecret: "secret",
+				AuthURL:      iss.server.URL + "/authorize",
+				TokenURL:     iss.server.URL + "/token",
+				UserInfoURL:  iss.server.URL + "/user",
+				EmailsURL:    iss.server.URL + "/user/emails",
+				Scopes:       []string{"read:user", "user:email"},
+			},
+		},
+		SocialRedirectURL: "http://app/auth/callback",
+	})
+}
+
+// socialLogin signs in through a provider, as the identity of the claims
+func (s *server) socialLogin(iss *issuer, provider string, claims map[string]interface{}) (int, map[string]interface{}) {
+	s.t.Helper()
+
+	code, start := s.call(http.MethodGet, "/api/auth/social/"+provider, "", nil)
+	if code != http.StatusOK {
+		s.t.Fatalf("start %s sign in: %d %v", provider, code, start)
+	}
+	grantCode := iss.consent(start["authorization_url"].(string), claims)
+	return s.call(http.MethodPost, "/api/auth/social/"+provider+"/callback", "", map[string]string{"code": grantCode, "state": start["state"].(string)})
+}
+
+// link links an identity of a provider to the caller of the token
+func (s *server) link(iss *issuer, token, provider string, claims map[string]interface{}) (int, map[string]interface{}) {
+	s.t.Helper()
+
+	code, start := s.call(http.MethodPost, "/api/auth/identities/"+provider, token, nil)
+	if code != http.StatusOK {
+		s.t.Fatalf("start linking %s: %d %v", provider, code, start)
+	}
+	grantCode := iss.consent(start["authorization_url"].(string), claims)
+	return s.call(http.MethodPost, "/api/auth/identities/"+provider+"/callback", token, map[string]string{"code": grantCode, "state": start["state"].(string)})
+}
+
+func TestSocialLogin(t *testing.T) {
+	iss := newIssuer(t)
+	s := newSocialServer(t, iss)
+
+	code, first := s.socialLogin(iss, "mock", map[string]interface{}{"sub": "ada", "email": "ada@example.com", "email_verified": true, "name": "Ada Lovelace"})
+	if code != http.StatusOK || first["status"] != user.StatusActive.String() {
+		t.Fatalf("sign up = %d %v", code, first)
+	}
+	code, again := s.socialLogin(iss, "mock", map[string]interface{}{"sub": "ada", "email": "ada@example.com", "email_verified": true})
+	if code != http.StatusOK || again["userId"] != first["userId"] {
+		t.Fatalf("sign in again = %d %v, want user %v", code, again, first["userId"])
+	}
+
+	token := first["id_token"].(string)
+	if code, response := s.call(http.MethodDelete, "/api/auth/identities/mock", token, nil); code != http.StatusConflict {
+		t.Fatalf("unlinking the only sign in method = %d %v", code, response)
+	}
+
+	// Accounts whose email the provider did not verify have to verify it like any sign up
+	if code, response := s.socialLogin(iss, "mock", map[string]interface{}{"sub": "grace", "email": "grace@example.com"}); code != http.StatusOK || response["status"] != user.StatusPending.String() {
+		t.Fatalf("sign up with an unverified email = %d %v", code, response)
+	}
+}
+
+func TestSocialLoginMerge(t *testing.T) {
+	iss := newIssuer(t)
+	s := newSocialServer(t, iss)
+
+	userID := s.register("ada@example.com", "password123")
+	identity := func(verified bool) map[string]interface{} {
+		return map[string]interface{}{"sub": "ada", "email": "ada@example.com", "email_verified": verified}
+	}
+
+	if code, response := s.socialLogin(iss, "mock", identity(true)); code != http.StatusConflict {
+		t.Fatalf("merging into a pending account = %d %v", code, response)
+	}
+	s.activate("ada@example.com")
+	if code, response := s.socialLogin(iss, "mock", identity(false)); code != http.StatusConflict {
+		t.Fatalf("merging an unverified email = %d %v", code, response)
+	}
+	code, merged := s.socialLogin(iss, "mock", identity(true))
+	if code != http.StatusOK || merged["userId"] != userID {
+		t.Fatalf("merge = %d %v, want user %s", code, merged, userID)
+	}
+
+	// Accounts with a password may unlink their last identity
+	token := merged["id_token"].(string)
+	if code, response := s.call(http.MethodDelete, "/api/auth/identities/mock", token, nil); code != http.StatusOK {
+		t.Fatalf("unlink = %d %v", code, response)
+	}
+}
+
+func TestLinkIdentity(t *testing.T) {
+	iss := newIssuer(t)
+	s := newSocialServer(t, iss)
+
+	_, other := s.socialLogin(iss, "mock", map[string]interface{}{"sub": "other", "email": "other@example.com", "email_verified": true})
+	userID := s.register("ada@example.com", "password123")
+	s.activate("ada@example.com")
+	token := s.login("ada@example.com", "password123")["id_token"].(string)
+
+	// The state of a link is bound to the user who started it
+	code, start := s.call(http.MethodPost, "/api/auth/identities/mock", token, nil)
+	if code != http.StatusOK {
+		t.Fatalf("start linking = %d %v", code, start)
+	}
+	grantCode := iss.consent(start["authorization_url"].(string), map[string]interface{}{"sub": "ada"})
+	if code, response := s.call(http.MethodPost, "/api/auth/identities/mock/callback", other["id_token"].(string), map[string]string{"code": grantCode, "state": start["state"].(string)}); code != http.StatusBadRequest {
+		t.Fatalf("linking with the state of another user = %d %v", code, response)
+	}
+
+	if code, response := s.link(iss, token, "mock", map[string]interface{}{"sub": "other"}); code != http.StatusConflict {
+		t.Fatalf("linking the identity of another account = %d %v", code, response)
+	}
+	if code, response := s.link(iss, token, "mock", map[string]interface{}{"sub": "ada", "email": "ada@work.example.com"}); code != http.StatusOK {
+		t.Fatalf("link = %d %v", code, response)
+	}
+
+	var linked []auth.LinkedIdentity
+	if err := s.db.Where("user_id = ?", userID).Find(&linked).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(linked) != 1 || linked[0].Subject != "ada" || linked[0].Email != "ada@work.example.com" {
+		t.Fatalf("expected the ada identity to be linked, got %+v", linked)
+	}
+}
+
+func TestGitHubLogin(t *testing.T) {
+	iss := newIssuer(t)
+	s := newSocialServer(t, iss)
+
+	// GitHub leaves private addresses out of the user, they are only listed by the emails endpoint
+	iss.setEmails(
+		map[string]interface{}{"email": "ada@old.example.com", "primary": false, "verified": true},
+		map[string]interface{}{"email": "ada@example.com", "primary": true, "verified": true},
+	)
+	userID := s.register("ada@example.com", "password123")
+	s.activate("ada@example.com")
+
+	code, response := s.socialLogin(iss, "github", map[string]interface{}{"id": 42, "login": "ada", "email": nil})
+	if code != http.StatusOK || response["userId"] != userID {
+		t.Fatalf("github sign in = %d %v, want user %s", code, response, userID)
+	}
+
+	// An unverified primary address signs up a pending account instead of merging
+	iss.setEmails(map[string]interface{}{"email": "grace@example.com", "primary": true, "verified": false})
+	code, response = s.socialLogin(iss, "github", map[string]interface{}{"id": 43, "login": "grace", "email": nil})
+	if code != http.StatusOK || response["status"] != user.StatusPending.String() {
+		t.Fatalf("github sign up with an unverified email = %d %v", code, response)
+	}
+
+	iss.setEmails()
+	if code, response := s.socialLogin(iss, "github", map[string]interface{}{"id": 44, "login": "nobody", "email": nil}); code != http.StatusBadRequest {
+		t.Fatalf("github sign in without an email = %d %v", code, response)
+	}
+}
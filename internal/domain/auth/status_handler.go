@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared/crud/types"
+	"net/http"
+)
+
+type UpdateStatusRequest struct {
+	Status user2.Status `json:"status" binding:"required"`
+}
+
+type StatusResponse struct {
+	UserID string       `json:"user_id"`
+	Status user2.Status `json:"status"`
+}
+
+// UpdateUserStatus sets the status of a user, suspending or deleting an account also signs out its sessions
+func (h *Handler) UpdateUserStatus(ctx *gin.Context) {
+	var req UpdateStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !user2.IsValidStatus(req.Status) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid status: " + req.Status.String()})
+		return
+	}
+
+	id := ctx.Param("id")
+	user, err := h.repo.FindById(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user with id " + id + " not found"})
+		return
+	}
+
+	if err := h.repo.Updates(user, types.UpdateMap{"status": req.Status.String()}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status"})
+		return
+	}
+
+	if req.Status == user2.StatusSuspended || req.Status == user2.StatusDeleted {
+		if err := h.sessions.RevokeAll(ctx, user.ID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Status updated but the sessions could not be revoked"})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, StatusResponse{UserID: user.ID, Status: req.Status})
+}
@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared"
+	"konsultn-api/internal/shared/crud"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/shared/security"
+	"konsultn-api/internal/shared/sensitive"
+	"strings"
+	"time"
+)
+
+const (
+	// totpIssuer names the account in authenticator apps
+	totpIssuer = "Konsultn"
+	// recoveryCodeCount is how many recovery codes are issued at once
+	recoveryCodeCount = 10
+	// challengeTTL is how long a password sign in waits for its second factor
+	challengeTTL = 5 * time.Minute
+	// Purposes binding the values encrypted by TwoFactor to their use, see security.Cipher
+	purposeTOTPSecret = "totp-secret"
+	purposeChallenge  = "two-factor-challenge"
+)
+
+// Errors returned for rejected two-factor requests
+var (
+	ErrTwoFactorUnavailable = errors.New("two-factor authentication is not configured")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled = errors.New("no authenticator is being enrolled")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrInvalidChallenge     = errors.New("invalid or expired two-factor challenge")
+)
+
+// RecoveryCode is a single use code signing in a user who lost their authenticator
+type RecoveryCode struct {
+	shared.ULID `gorm:"embedded"`
+	UserID      string `gorm:"not null;index"`
+	CodeHash    string `gorm:"size:64;not null" json:"-" sensitive:"true"`
+	UsedAt      *time.Time
+	CreatedAt   time.Time
+}
+
+// MarshalJSON leaves the sensitive fields out, or fails in strict mode, as recovery codes are only shown when issued
+func (m RecoveryCode) MarshalJSON() ([]byte, error) {
+	type plain RecoveryCode
+	return sensitive.Marshal(plain(m))
+}
+
+// Challenge is a password sign in of a user with two-factor authentication, waiting for the second factor
+// It is handed to the client encrypted, the refresh token it holds is only exchanged once a code is verified
+type Challenge struct {
+	UserID       string `json:"user_id"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresAt    int64  `json:"expires_at"`
+}
+
+// TwoFactor manages the TOTP authenticators and recovery codes of users
+// TOTP secrets are stored encrypted in the two-factor fields of the users table
+type TwoFactor struct {
+	repo   *user2.Repository[user2.User]
+	codes  *crud.Repository[RecoveryCode, string]
+	cipher *security.Cipher
+}
+
+// NewTwoFactor returns the two-factor authentication of the users of repo
+// Without a cipher two-factor authentication cannot be enrolled or verified
+func NewTwoFactor(repo *user2.Repository[user2.User], db *gorm.DB, cipher *security.Cipher) *TwoFactor {
+	return &TwoFactor{repo: repo, codes: crud.NewRepository[RecoveryCode, string](db), cipher: cipher}
+}
+
+// Enroll generates a TOTP secret for the user, it is enabled once Enable verifies a code of it
+// Enrolling again replaces a secret that was not enabled yet
+func (t *TwoFactor) Enroll(ctx context.Context, user *user2.User) (secret string, uri string, err error) {
+	if t.cipher == nil {
+		return "", "", ErrTwoFactorUnavailable
+	}
+	if user.TwoFactorEnabled {
+		return "", "", ErrTwoFactorEnabled
+	}
+
+	secret, err = security.NewTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	encrypted, err := t.cipher.Encrypt(purposeTOTPSecret, secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = t.repo.WithContext(ctx).Updates(user, types.UpdateMap{
+		"two_factor_secret":    encrypted,
+		"two_factor_last_step": int64(0),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return secret, security.TOTPURI(totpIssuer, user.Email, secret), nil
+}
+
+// Enable turns on two-factor authentication with a code of the enrolled secret and returns new recovery codes
+func (t *TwoFactor) Enable(ctx context.Context, user *user2.User, code string) ([]string, error) {
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if err := t.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	if err := t.repo.WithContext(ctx).Updates(user, types.UpdateMap{"two_factor_enabled": true}); err != nil {
+		return nil, err
+	}
+	return t.issueRecoveryCodes(ctx, user)
+}
+
+// Disable turns off two-factor authentication, the code may be a TOTP or a recovery code
+func (t *TwoFactor) Disable(ctx context.Context, user *user2.User, code string) error {
+	if err := t.Verify(ctx, user, code); err != nil {
+		return err
+	}
+
+	err := t.repo.WithContext(ctx).Updates(user, types.UpdateMap{
+		"two_factor_enabled":   false,
+		"two_factor_secret":    "",
+		"two_factor_last_step": int64(0),
+	})
+	if err != nil {
+		return err
+	}
+	return t.deleteRecoveryCodes(ctx, user)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user after checking a TOTP code
+func (t *TwoFactor) RegenerateRecoveryCodes(ctx context.Context, user *user2.User, code string) ([]string, error) {
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err := t.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+	return t.issueRecoveryCodes(ctx, user)
+}
+
+// RemainingRecoveryCodes counts the unused recovery codes of the user
+func (t *TwoFactor) RemainingRecoveryCodes(ctx context.Context, user *user2.User) (int64, error) {
+	var count int64
+	err := t.codes.WithContext(ctx).GetDB().
+		Model(&RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", user.ID).
+		Count(&count).Error
+	return count, err
+}
+
+// Verify checks the second factor of a user with two-factor authentication enabled
+// Codes are single use, recovery codes are consumed and TOTP codes cannot be replayed
+func (t *TwoFactor) Verify(ctx context.Context, user *user2.User, code string) error {
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == 6 {
+		return t.verifyTOTP(ctx, user, code)
+	}
+	return t.useRecoveryCode(ctx, user, code)
+}
+
+// Challenge seals a password sign in of the user, to be completed with Complete
+func (t *TwoFactor) Challenge(user *user2.User, tokens *Tokens) (string, error) {
+	if t.cipher == nil {
+		return "", ErrTwoFactorUnavailable
+	}
+
+	payload, err := json.Marshal(Challenge{
+		UserID:       user.ID,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    time.Now().Add(challengeTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return t.cipher.Encrypt(purposeChallenge, string(payload))
+}
+
+// Open returns the user and the challenge of a sealed challenge, its code is checked with Verify
+func (t *TwoFactor) Open(ctx context.Context, sealed string) (*user2.User, *Challenge, error) {
+	if t.cipher == nil {
+		return nil, nil, ErrTwoFactorUnavailable
+	}
+
+	payload, err := t.cipher.Decrypt(purposeChallenge, sealed)
+	if err != nil {
+		return nil, nil, ErrInvalidChallenge
+	}
+	var challenge Challenge
+	if err := json.Unmarshal([]byte(payload), &challenge); err != nil || time.Now().Unix() > challenge.ExpiresAt {
+		return nil, nil, ErrInvalidChallenge
+	}
+
+	user, err := t.repo.WithContext(ctx).FindFirstBy("id", challenge.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidChallenge
+		}
+		return nil, nil, err
+	}
+	return user, &challenge, nil
+}
+
+// verifyTOTP checks a code of the TOTP secret of the user and records its time step
+func (t *TwoFactor) verifyTOTP(ctx context.Context, user *user2.User, code string) error {
+	if t.cipher == nil {
+		return ErrTwoFactorUnavailable
+	}
+
+	secret, err := t.cipher.Decrypt(purposeTOTPSecret, user.TwoFactorSecret)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+
+	step, ok := security.VerifyTOTP(secret, strings.TrimSpace(code), time.Now())
+	if !ok || step <= user.TwoFactorLastStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	// The step is only recorded if it is still newer, so concurrent requests cannot both use the code
+	recorded := t.repo.WithContext(ctx).GetDB().
+		Model(&user2.User{}).
+		Where("id = ? AND two_factor_last_step < ?", user.ID, step).
+		Update("two_factor_last_step", step)
+	if recorded.Error != nil {
+		return recorded.Error
+	}
+	if recorded.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	user.TwoFactorLastStep = step
+	return nil
+}
+
+// useRecoveryCode consumes an unused recovery code of the user
+func (t *TwoFactor) useRecoveryCode(ctx context.Context, user *user2.User, code string) error {
+	used := t.codes.WithContext(ctx).GetDB().
+		Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, digest(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if used.Error != nil {
+		return used.Error
+	}
+	if used.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// issueRecoveryCodes replaces the recovery codes of the user, only their digests are stored
+func (t *TwoFactor) issueRecoveryCodes(ctx context.Context, user *user2.User) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := recoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, RecoveryCode{UserID: user.ID, CodeHash: digest(normalizeRecoveryCode(code))})
+	}
+
+	err := t.codes.WithContext(ctx).GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+func (t *TwoFactor) deleteRecoveryCodes(ctx context.Context, user *user2.User) error {
+	return t.codes.WithContext(ctx).GetDB().Where("user_id = ?", user.ID).Delete(&RecoveryCode{}).Error
+}
+
+// recoveryCodeAlphabet has 32 characters, leaving out ones that are easily confused, so bytes map to it without bias
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz023456789"
+
+// recoveryCode returns a random code formatted as xxxxx-xxxxx
+func recoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+
+	var code strings.Builder
+	for i, v := range b {
+		if i == 5 {
+			code.WriteByte('-')
+		}
+		code.WriteByte(recoveryCodeAlphabet[v%32])
+	}
+	return code.String(), nil
+}
+
+// normalizeRecoveryCode accepts codes typed without the dash or in upper case
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
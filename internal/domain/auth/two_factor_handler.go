@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	user2 "konsultn-api/internal/domain/user"
+	"konsultn-api/internal/middleware"
+	"log"
+	"net/http"
+	"time"
+)
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorChallengeRequest struct {
+	Challenge string `json:"challenge" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+}
+
+// TwoFactorStatus reports whether the caller has two-factor authentication enabled
+func (h *Handler) TwoFactorStatus(ctx *gin.Context) {
+	user, ok := h.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	remaining, err := h.twoFactor.RemainingRecoveryCodes(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch recovery codes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"enabled":                  user.TwoFactorEnabled,
+		"recovery_codes_remaining": remaining,
+	})
+}
+
+// EnrollTwoFactor generates a TOTP secret for the caller to add to an authenticator app
+func (h *Handler) EnrollTwoFactor(ctx *gin.Context) {
+	user, ok := h.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	secret, uri, err := h.twoFactor.Enroll(ctx, user)
+	if err != nil {
+		twoFactorError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": uri,
+		"message":     "Add the secret to your authenticator app and confirm a code to enable two-factor authentication",
+	})
+}
+
+// EnableTwoFactor confirms the enrolled secret with a code and returns the recovery codes, they are only shown once
+func (h *Handler) EnableTwoFactor(ctx *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	user, ok := h.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactor.Enable(ctx, user, req.Code)
+	if err != nil {
+		twoFactorError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"recovery_codes": codes,
+		"message":        "two-factor authentication enabled",
+	})
+}
+
+// DisableTwoFactor turns off two-factor authentication with a TOTP or recovery code
+func (h *Handler) DisableTwoFactor(ctx *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	user, ok := h.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactor.Disable(ctx, user, req.Code); err != nil {
+		twoFactorError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the caller, the previous ones stop working
+func (h *Handler) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	user, ok := h.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactor.RegenerateRecoveryCodes(ctx, user, req.Code)
+	if err != nil {
+		twoFactorError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// VerifyTwoFactor completes a login challenged for a second factor
+func (h *Handler) VerifyTwoFactor(ctx *gin.Context) {
+	var req TwoFactorChallengeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	user, challenge, err := h.twoFactor.Open(ctx, req.Challenge)
+	if err != nil {
+		if errors.Is(err, ErrInvalidChallenge) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, please log in again"})
+			return
+		}
+		twoFactorError(ctx, err)
+		return
+	}
+
+	// Wrong codes count as failed sign ins of the account, so the second factor is throttled like the password
+	throttle, err := h.lockout.CheckLogin(ctx, user.Email, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "two-factor authentication failed"})
+		return
+	}
+	if throttle.Blocked(time.Now()) {
+		tooManyAttempts(ctx, throttle)
+		return
+	}
+
+	if err := h.twoFactor.Verify(ctx, user, req.Code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if _, failErr := h.lockout.FailLogin(ctx, user.Email, ctx.ClientIP()); failErr != nil {
+				log.Printf("failed to record failed two-factor code: %v", failErr)
+			}
+		}
+		twoFactorError(ctx, err)
+		return
+	}
+
+	h.completeLogin(ctx, user, challenge.RefreshToken)
+}
+
+// currentUser loads the user of the caller, it writes the error response when it fails
+func (h *Handler) currentUser(ctx *gin.Context) (*user2.User, bool) {
+	user, err := h.repo.WithContext(ctx).FindFirstBy("id", middleware.CurrentUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return nil, false
+	}
+	return user, true
+}
+
+func twoFactorError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidTwoFactorCode):
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+	case errors.Is(err, ErrTwoFactorEnabled), errors.Is(err, ErrTwoFactorNotEnabled), errors.Is(err, ErrTwoFactorNotEnrolled):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTwoFactorUnavailable):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "two-factor authentication failed"})
+	}
+}
@@ -0,0 +1,110 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"konsultn-api/internal/domain/auth"
+	"konsultn-api/internal/domain/user"
+	"net/http"
+	"testing"
+)
+
+func TestTwoFactorLogin(t *testing.T) {
+	s := newServer(t, auth.Dependencies{SecretCipher: testCipher(t)})
+	s.register("ada@example.com", "correct-horse")
+	token := s.login("ada@example.com", "correct-horse")["id_token"].(string)
+
+	code, enrollment := s.call(http.MethodPost, "/api/auth/2fa/enroll", token, nil)
+	if code != http.StatusOK {
+		t.Fatalf("enroll = %d %v", code, enrollment)
+	}
+	secret := enrollment["secret"].(string)
+	if code, _ := s.call(http.MethodPost, "/api/auth/2fa/enable", token, map[string]string{"code": "000000"}); code != http.StatusUnauthorized {
+		t.Fatalf("enable with a wrong code = %d", code)
+	}
+	code, enabled := s.call(http.MethodPost, "/api/auth/2fa/enable", token, map[string]string{"code": totp(secret, 0)})
+	if code != http.StatusOK {
+		t.Fatalf("enable = %d %v", code, enabled)
+	}
+	recoveryCode := enabled["recovery_codes"].([]interface{})[0].(string)
+
+	challenged := s.login("ada@example.com", "correct-horse")
+	if challenged["id_token"] != nil || challenged["two_factor_required"] != true {
+		t.Fatalf("tokens issued before the second factor: %v", challenged)
+	}
+	challenge := challenged["challenge"].(string)
+	verify := func(challenge, code string) (int, map[string]interface{}) {
+		return s.call(http.MethodPost, "/api/auth/2fa/verify", "", map[string]string{"challenge": challenge, "code": code})
+	}
+
+	if code, _ := verify(challenge, totp(secret, 0)); code != http.StatusUnauthorized {
+		t.Fatalf("replayed code = %d", code)
+	}
+	if code, _ := verify(challenge+"x", totp(secret, 1)); code != http.StatusUnauthorized {
+		t.Fatalf("tampered challenge = %d", code)
+	}
+	if code, response := verify(challenge, totp(secret, 1)); code != http.StatusOK || response["id_token"] == nil {
+		t.Fatalf("verify = %d %v", code, response)
+	}
+	if code, response := verify(challenge, recoveryCode); code != http.StatusOK {
+		t.Fatalf("recovery code = %d %v", code, response)
+	}
+	if code, _ := verify(challenge, recoveryCode); code != http.StatusUnauthorized {
+		t.Fatalf("reused recovery code = %d", code)
+	}
+}
+
+func TestTokensWithoutSession(t *testing.T) {
+	ctx := context.Background()
+	s := newServer(t, auth.Dependencies{SecretCipher: testCipher(t)})
+	s.register("ada@example.com", "correct-horse")
+
+	// Tokens obtained from the identity provider directly, as Firebase clients can, have no session
+	direct, err := s.provider.SignIn(ctx, "ada@example.com", "correct-horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, response := s.call(http.MethodGet, "/api/auth/2fa", direct.IDToken, nil); code != http.StatusOK {
+		t.Fatalf("session-less token without two-factor authentication = %d %v", code, response)
+	}
+
+	s.enableTwoFactor(s.login("ada@example.com", "correct-horse")["id_token"].(string))
+	// Sessions are matched by sign in time in seconds, keep the recorded one apart from the next sign in
+	s.db.Exec("UPDATE sessions SET auth_time = auth_time - 10")
+	direct, err = s.provider.SignIn(ctx, "ada@example.com", "correct-horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := s.call(http.MethodGet, "/api/auth/2fa", direct.IDToken, nil); code != http.StatusUnauthorized {
+		t.Fatalf("session-less token skipping the two-factor challenge = %d", code)
+	}
+}
+
+func TestChallengePurpose(t *testing.T) {
+	s := newServer(t, auth.Dependencies{
+		SecretCipher: testCipher(t),
+		SocialProviders: []auth.OIDCConfig{{
+			Name:         "github",
+			ClientID:     "client",
+			ClientSecret: "secret",
+			AuthURL:      "http://github.test/authorize",
+			TokenURL:     "http://github.test/token",
+			UserInfoURL:  "http://github.test/user",
+		}},
+		SocialRedirectURL: "http://app/auth/callback",
+	})
+	s.register("ada@example.com", "correct-horse")
+	s.activate("ada@example.com")
+	token := s.login("ada@example.com", "correct-horse")["id_token"].(string)
+
+	code, start := s.call(http.MethodPost, "/api/auth/identities/github", token, nil)
+	if code != http.StatusOK {
+		t.Fatalf("start linking = %d %v", code, start)
+	}
+
+	// A link state carries a user ID too, yet it is sealed for another purpose and cannot be opened as a challenge
+	secondFactor := auth.NewTwoFactor(user.NewRepository(s.db), s.db, testCipher(t))
+	if _, _, err := secondFactor.Open(context.Background(), start["state"].(string)); !errors.Is(err, auth.ErrInvalidChallenge) {
+		t.Fatalf("expected ErrInvalidChallenge for a link state, got %v", err)
+	}
+}
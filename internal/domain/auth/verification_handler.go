@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+)
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// VerifyEmail activates the account of a verification token mailed on sign up
+func (h *Handler) VerifyEmail(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing verification token"})
+		return
+	}
+
+	user, err := h.verifications.Verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, ErrInvalidVerificationToken) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "email verified",
+		"status":  user.Status,
+	})
+}
+
+// ResendVerification mails a new verification link to the account of the email, if it is pending
+// The response is the same either way and the mail is sent in the background, so it does not reveal accounts
+func (h *Handler) ResendVerification(ctx *gin.Context) {
+	var req ResendVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	background := context.WithoutCancel(ctx.Request.Context())
+	go func() {
+		if err := h.verifications.Resend(background, req.Email); err != nil {
+			log.Printf("failed to resend email verification: %v", err)
+		}
+	}()
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "If a pending account exists for this email, a verification link was sent to it"})
+}
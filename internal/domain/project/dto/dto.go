@@ -1,5 +1,7 @@
 package dto
 
+import "konsultn-api/internal/domain/user"
+
 type ProjectDTO struct {
 	ID    string    `json:"id"`
 	Name  string    `json:"name"`
@@ -7,17 +9,10 @@ type ProjectDTO struct {
 }
 
 type TaskDTO struct {
-	ID          string       `json:"id"`
-	Title       string       `json:"title"`
-	Description string       `json:"description"`
-	AssigneeID  *string      `json:"assignee_id"`
-	Assignee    *AssigneeDTO `json:"assignee"`
-	ProjectID   string       `json:"project_id"`
-}
-
-type AssigneeDTO struct {
-	ID        string `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Email     string `json:"email"`
+	ID          string                   `json:"id"`
+	Title       string                   `json:"title"`
+	Description string                   `json:"description"`
+	AssigneeID  *string                  `json:"assignee_id"`
+	Assignee    *user.PublicUserResponse `json:"assignee"`
+	ProjectID   string                   `json:"project_id"`
 }
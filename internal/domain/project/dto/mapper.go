@@ -3,6 +3,7 @@ package dto
 import (
 	"konsultn-api/internal/domain/project/model"
 	"konsultn-api/internal/domain/task"
+	"konsultn-api/internal/domain/user"
 )
 
 func FromModelProject(project *model.Project) ProjectDTO {
@@ -20,12 +21,17 @@ func FromModelProject(project *model.Project) ProjectDTO {
 
 // FromModelTask maps a Task GORM model to a TaskDTO
 func FromModelTask(task *task.Task) TaskDTO {
-	return TaskDTO{
+	dto := TaskDTO{
 		ID:          task.ID,
 		Title:       task.Title,
 		Description: task.Description,
 		AssigneeID:  task.AssigneeID,
 	}
+	if task.Assignee.ID != "" {
+		assignee := user.ToPublicUserResponse(&task.Assignee)
+		dto.Assignee = &assignee
+	}
+	return dto
 }
 
 func FromDTOProject(project ProjectDTO) model.Project {
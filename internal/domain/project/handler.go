@@ -23,7 +23,7 @@ func NewHandler(projectService *service.ProjectService) *Handler {
 // @Tags         projects
 // @Produce      json
 // @Param        id        path      string  true   "Project ID"
-// @Success      200 {object} model.Project
+// @Success      200 {object} dto.ProjectDTO
 // @Router       /projects/{id} [get]
 func (h *Handler) FindByID(ctx *gin.Context) {
 	projectId := ctx.Param("id")
@@ -31,7 +31,7 @@ func (h *Handler) FindByID(ctx *gin.Context) {
 
 	h.projectService.Repo.Preload(&project, []string{"Tasks", "Tasks.Assignee"}, "id", projectId)
 
-	ctx.JSON(http.StatusOK, project)
+	ctx.JSON(http.StatusOK, dto.FromModelProject(&project))
 }
 
 func (h *Handler) CreateProject(ctx *gin.Context) {
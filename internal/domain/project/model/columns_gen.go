@@ -0,0 +1,23 @@
+// Code generated by columngen. DO NOT EDIT.
+
+package model
+
+import "konsultn-api/internal/shared/crud/types"
+
+// ProjectTable is the table of Project
+const ProjectTable = "projects"
+
+// Columns of Project, qualified with its table
+const (
+	ColProjectID   types.Column = "projects.id"
+	ColProjectName types.Column = "projects.name"
+)
+
+// ProjectColumns gives field-style access to the columns of Project
+var ProjectColumns = struct {
+	ID   types.Column
+	Name types.Column
+}{
+	ID:   ColProjectID,
+	Name: ColProjectName,
+}
@@ -0,0 +1,33 @@
+package repository_test
+
+import (
+	"konsultn-api/internal/domain/project/repository"
+	"konsultn-api/internal/domain/task"
+	"konsultn-api/internal/shared/crud"
+	"konsultn-api/internal/testkit"
+	"testing"
+)
+
+func TestProjectTasks(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	busy, idle := f.Project(), f.Project()
+	f.Task(busy, func(t *task.Task) { t.Status = "open" })
+	f.Task(busy, func(t *task.Task) { t.Status = "done" })
+	f.Task(idle, func(t *task.Task) { t.Status = "done" })
+
+	repo := repository.NewRepository(db)
+	rows, err := repo.Query().
+		WhereHas("Tasks", func(q crud.QueryBuilder[any]) { q.Where("status", "open") }).
+		WithCount("Tasks", nil).
+		AllAsMaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0]["id"] != busy.ID {
+		t.Fatalf("projects with open tasks = %+v", rows)
+	}
+	if count, _ := rows[0]["tasks_count"].(int64); count != 2 {
+		t.Fatalf("tasks_count = %v", rows[0]["tasks_count"])
+	}
+}
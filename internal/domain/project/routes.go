@@ -5,13 +5,19 @@ import (
 	"gorm.io/gorm"
 	"konsultn-api/internal/domain/project/service"
 	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/ids"
+	"konsultn-api/internal/shared/security"
 )
 
 func RegisterRoutes(api *gin.RouterGroup, db *gorm.DB) {
 	projectService := service.NewProjectService(db)
 	h := NewHandler(projectService)
 
-	project := api.Group("/projects", middleware.AuthMiddleware())
+	project := api.Group("/projects",
+		middleware.AuthMiddleware(middleware.AllowAPIKeys),
+		middleware.ValidateIDParams(ids.ULID),
+		middleware.RequireMethodScope(security.ScopeReadProjects, security.ScopeWriteProjects),
+	)
 	{
 		project.GET("/:id", h.FindByID)
 		project.POST("/", h.CreateProject)
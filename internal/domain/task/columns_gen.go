@@ -0,0 +1,56 @@
+// Code generated by columngen. DO NOT EDIT.
+
+package task
+
+import "konsultn-api/internal/shared/crud/types"
+
+// TaskTable is the table of Task
+const TaskTable = "tasks"
+
+// Columns of Task, qualified with its table
+const (
+	ColTaskID           types.Column = "tasks.id"
+	ColTaskProjectID    types.Column = "tasks.project_id"
+	ColTaskTitle        types.Column = "tasks.title"
+	ColTaskDescription  types.Column = "tasks.description"
+	ColTaskStatus       types.Column = "tasks.status"
+	ColTaskPriority     types.Column = "tasks.priority"
+	ColTaskDueDate      types.Column = "tasks.due_date"
+	ColTaskAssigneeID   types.Column = "tasks.assignee_id"
+	ColTaskParentTaskID types.Column = "tasks.parent_task_id"
+	ColTaskCustomFields types.Column = "tasks.custom_fields"
+	ColTaskCreatedAt    types.Column = "tasks.created_at"
+	ColTaskUpdatedAt    types.Column = "tasks.updated_at"
+	ColTaskDeletedAt    types.Column = "tasks.deleted_at"
+)
+
+// TaskColumns gives field-style access to the columns of Task
+var TaskColumns = struct {
+	ID           types.Column
+	ProjectID    types.Column
+	Title        types.Column
+	Description  types.Column
+	Status       types.Column
+	Priority     types.Column
+	DueDate      types.Column
+	AssigneeID   types.Column
+	ParentTaskID types.Column
+	CustomFields types.Column
+	CreatedAt    types.Column
+	UpdatedAt    types.Column
+	DeletedAt    types.Column
+}{
+	ID:           ColTaskID,
+	ProjectID:    ColTaskProjectID,
+	Title:        ColTaskTitle,
+	Description:  ColTaskDescription,
+	Status:       ColTaskStatus,
+	Priority:     ColTaskPriority,
+	DueDate:      ColTaskDueDate,
+	AssigneeID:   ColTaskAssigneeID,
+	ParentTaskID: ColTaskParentTaskID,
+	CustomFields: ColTaskCustomFields,
+	CreatedAt:    ColTaskCreatedAt,
+	UpdatedAt:    ColTaskUpdatedAt,
+	DeletedAt:    ColTaskDeletedAt,
+}
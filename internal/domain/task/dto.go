@@ -1,7 +1,62 @@
 package task
 
+import (
+	"gorm.io/datatypes"
+	"konsultn-api/internal/domain/user"
+	"time"
+)
+
 type CreateTaskRequest struct {
 }
 
 type UpdateTaskRequest struct {
 }
+
+// TaskResponse is a task with its assignee rendered through the public user view
+type TaskResponse struct {
+	ID           string                   `json:"id"`
+	ProjectID    *string                  `json:"project_id"`
+	Title        string                   `json:"title"`
+	Description  string                   `json:"description"`
+	Status       string                   `json:"status"`
+	Priority     string                   `json:"priority"`
+	DueDate      *time.Time               `json:"due_date"`
+	AssigneeID   *string                  `json:"assignee_id"`
+	Assignee     *user.PublicUserResponse `json:"assignee"`
+	ParentTaskID *string                  `json:"parent_task_id"`
+	ParentTask   *TaskResponse            `json:"parent_task,omitempty"`
+	Subtasks     []TaskResponse           `json:"subtasks,omitempty"`
+	CustomFields datatypes.JSON           `json:"custom_fields" swaggertype:"object"`
+	CreatedAt    time.Time                `json:"created_at"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+}
+
+// ToTaskResponse maps a task to its response, the assignee is only set when it was preloaded
+func ToTaskResponse(task *Task) TaskResponse {
+	response := TaskResponse{
+		ID:           task.ID,
+		ProjectID:    task.ProjectID,
+		Title:        task.Title,
+		Description:  task.Description,
+		Status:       task.Status,
+		Priority:     task.Priority,
+		DueDate:      task.DueDate,
+		AssigneeID:   task.AssigneeID,
+		ParentTaskID: task.ParentTaskID,
+		CustomFields: task.CustomFields,
+		CreatedAt:    task.CreatedAt,
+		UpdatedAt:    task.UpdatedAt,
+	}
+	if task.Assignee.ID != "" {
+		assignee := user.ToPublicUserResponse(&task.Assignee)
+		response.Assignee = &assignee
+	}
+	if task.ParentTask != nil {
+		parent := ToTaskResponse(task.ParentTask)
+		response.ParentTask = &parent
+	}
+	for i := range task.Subtasks {
+		response.Subtasks = append(response.Subtasks, ToTaskResponse(&task.Subtasks[i]))
+	}
+	return response
+}
@@ -32,7 +32,7 @@ func (h *Handler) CreateTask(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "task created successfully",
-		"task":    task,
+		"task":    ToTaskResponse(task),
 	})
 
 }
@@ -47,5 +47,5 @@ func (h *Handler) GetTaskById(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	}
 
-	ctx.JSON(http.StatusOK, task)
+	ctx.JSON(http.StatusOK, ToTaskResponse(task))
 }
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"konsultn-api/internal/domain/user"
 	"konsultn-api/internal/shared"
@@ -16,8 +17,11 @@ type Task struct {
 	Priority     string         `gorm:"type:varchar(20)" json:"priority"`
 	DueDate      *time.Time     `gorm:"type:timestamp" json:"due_date"`
 	AssigneeID   *string        `gorm:"type:varchar(26);" json:"assignee_id"`
-	Assignee     user.User      `json:"assignee"`
+	Assignee     user.User      `json:"-"` // rendered through its public view, see TaskResponse
 	ParentTaskID *string        `gorm:"type:varchar(26)" json:"parent_task_id"`
+	ParentTask   *Task          `gorm:"foreignKey:ParentTaskID" json:"parent_task,omitempty"`
+	Subtasks     []Task         `gorm:"foreignKey:ParentTaskID" json:"subtasks,omitempty"`
+	CustomFields datatypes.JSON `gorm:"type:jsonb" json:"custom_fields" swaggertype:"object"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `json:"deleted_at" swaggerignore:"true"`
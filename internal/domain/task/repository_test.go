@@ -0,0 +1,44 @@
+package task_test
+
+import (
+	"konsultn-api/internal/domain/task"
+	"konsultn-api/internal/shared/crud"
+	"konsultn-api/internal/testkit"
+	"testing"
+)
+
+func TestRepositoryRelations(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	project := f.Project()
+	assignee := f.User()
+	parent := f.Task(project)
+	child := f.Task(project, func(t *task.Task) {
+		t.ParentTaskID = &parent.ID
+		t.AssigneeID = &assignee.ID
+	})
+
+	repo := task.NewRepository(db)
+	subtasks, err := repo.Query().JoinRelation("ParentTask").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subtasks) != 1 || subtasks[0].ID != child.ID {
+		t.Fatalf("tasks with a parent = %+v", subtasks)
+	}
+
+	withSubtasks, err := repo.Query().WhereHas("Subtasks", func(q crud.QueryBuilder[any]) {
+		q.Where("assignee_id", assignee.ID)
+	}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(withSubtasks) != 1 || withSubtasks[0].ID != parent.ID {
+		t.Fatalf("tasks with an assigned subtask = %+v", withSubtasks)
+	}
+
+	page, err := repo.Query().Paginate()
+	if err != nil || page.TotalCount != 2 || len(page.Result) != 2 {
+		t.Fatalf("Paginate = %+v, %v", page, err)
+	}
+}
@@ -4,10 +4,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/ids"
+	"konsultn-api/internal/shared/security"
 )
 
 func RegisterRoutes(api *gin.RouterGroup, db *gorm.DB) {
-	task := api.Group("/tasks", middleware.AuthMiddleware())
+	task := api.Group("/tasks",
+		middleware.AuthMiddleware(middleware.AllowAPIKeys),
+		middleware.ValidateIDParams(ids.ULID),
+		middleware.RequireMethodScope(security.ScopeReadTasks, security.ScopeWriteTasks),
+	)
 	repo := NewRepository(db)
 	h := NewHandler(repo)
 	{
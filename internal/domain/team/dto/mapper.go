@@ -11,12 +11,13 @@ func mapTeamMembers(members []model.TeamMember) []TeamMember {
 	result := make([]TeamMember, 0, len(members))
 	for _, m := range members {
 		result = append(result, TeamMember{
-			ID:        m.User.ID,
-			FirstName: m.User.FirstName,
-			LastName:  m.User.LastName,
-			Email:     m.User.Email,
-			JoinedAt:  m.JoinedAt.String(),
-			Role:      m.Role,
+			ID:               m.User.ID,
+			FirstName:        m.User.FirstName,
+			LastName:         m.User.LastName,
+			Email:            m.User.Email,
+			JoinedAt:         m.JoinedAt.String(),
+			Role:             m.Role,
+			TwoFactorEnabled: m.User.TwoFactorEnabled,
 		})
 	}
 	return result
@@ -8,9 +8,10 @@ type CreateTeamRequest struct {
 }
 
 type UpdateTeamRequest struct {
-	Name    *string `json:"name,omitempty"`
-	Slug    *string `json:"slug,omitempty"`
-	OwnerId *string `json:"owner_id,omitempty"`
+	Name             *string `json:"name,omitempty"`
+	Slug             *string `json:"slug,omitempty"`
+	OwnerId          *string `json:"owner_id,omitempty"`
+	RequireTwoFactor *bool   `json:"require_two_factor,omitempty"`
 }
 
 type AddMemberRequest struct {
@@ -7,6 +7,8 @@ type Team struct {
 	Description string        `json:"description"`
 	Owner       *TeamMember   `json:"owner"`
 	Members     *[]TeamMember `json:"members,omitempty"`
+	// RequireTwoFactor is set when members must have two-factor authentication enabled
+	RequireTwoFactor bool `json:"require_two_factor"`
 }
 
 type TeamMember struct {
@@ -16,6 +18,8 @@ type TeamMember struct {
 	Email     string `json:"email"`
 	JoinedAt  string `json:"joined_at,omitempty"`
 	Role      string `json:"role,omitempty"`
+	// TwoFactorEnabled lets team admins see who has to enable two-factor authentication
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
 }
 
 type TeamSummary struct {
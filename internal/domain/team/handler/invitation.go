@@ -1,15 +1,18 @@
 package handler
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"konsultn-api/internal/domain/team/dto"
+	"konsultn-api/internal/domain/team/service"
+	"konsultn-api/internal/middleware"
 	"konsultn-api/internal/shared/transport"
 	"net/http"
 )
 
 func (h *Handler) InviteUsersToTeam(ctx *gin.Context) {
 	teamId := ctx.Param("id")
-	fromUserId, _ := ctx.Get("userId")
+	fromUserId := middleware.CurrentUserID(ctx)
 
 	var addMemberRequest []dto.AddMemberRequest
 
@@ -18,8 +21,12 @@ func (h *Handler) InviteUsersToTeam(ctx *gin.Context) {
 		return
 	}
 
-	err := h.teamService.InviteUsersToTeam(fromUserId.(string), teamId, addMemberRequest)
+	err := h.teamService.InviteUsersToTeam(fromUserId, teamId, addMemberRequest)
 
+	if errors.Is(err, service.ErrInviteeNotActive) {
+		ctx.JSON(http.StatusUnprocessableEntity, transport.ErrorResponse{Message: err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, transport.ErrorResponse{Message: err.Error()})
 		return
@@ -29,9 +36,9 @@ func (h *Handler) InviteUsersToTeam(ctx *gin.Context) {
 }
 func (h *Handler) AcceptInvitation(ctx *gin.Context) {
 	invitationId := ctx.Param("invitationId")
-	actingUserId, _ := ctx.Get("userId")
+	actingUserId := middleware.CurrentUserID(ctx)
 
-	if err := h.teamService.UpdateTeamInvitation(invitationId, "accept", actingUserId.(string)); err != nil {
+	if err := h.teamService.UpdateTeamInvitation(invitationId, "accept", actingUserId); err != nil {
 		ctx.JSON(http.StatusBadRequest, transport.ErrorResponse{Message: err.Error()})
 		return
 	}
@@ -41,9 +48,9 @@ func (h *Handler) AcceptInvitation(ctx *gin.Context) {
 
 func (h *Handler) RejectInvitation(ctx *gin.Context) {
 	invitationId := ctx.Param("invitationId")
-	actingUserId, _ := ctx.Get("userId")
+	actingUserId := middleware.CurrentUserID(ctx)
 
-	if err := h.teamService.UpdateTeamInvitation(invitationId, "reject", actingUserId.(string)); err != nil {
+	if err := h.teamService.UpdateTeamInvitation(invitationId, "reject", actingUserId); err != nil {
 		ctx.JSON(http.StatusBadRequest, transport.ErrorResponse{Message: err.Error()})
 		return
 	}
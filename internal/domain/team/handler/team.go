@@ -3,15 +3,14 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"konsultn-api/internal/domain/team/dto"
+	"konsultn-api/internal/middleware"
 	"konsultn-api/internal/shared/crud"
 	"konsultn-api/internal/shared/transport"
 	"net/http"
 )
 
 func (h *Handler) CreateTeam(ctx *gin.Context) {
-	_, exists := ctx.Get("userId")
-
-	if !exists {
+	if middleware.CurrentUserID(ctx) == "" {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
@@ -52,7 +51,7 @@ func (h *Handler) FindTeamById(ctx *gin.Context) {
 
 func (h *Handler) UpdateTeamById(ctx *gin.Context) {
 	teamId := ctx.Param("id")
-	userId, _ := ctx.Get("userId")
+	userId := middleware.CurrentUserID(ctx)
 
 	var updateTeamRequest dto.UpdateTeamRequest
 	if err := ctx.ShouldBindJSON(&updateTeamRequest); err != nil {
@@ -60,7 +59,7 @@ func (h *Handler) UpdateTeamById(ctx *gin.Context) {
 		return
 	}
 
-	updatedTeam, err := h.teamService.UpdateTeamById(userId.(string), teamId, updateTeamRequest)
+	updatedTeam, err := h.teamService.UpdateTeamById(userId, teamId, updateTeamRequest)
 
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, transport.ErrorResponse{Message: err.Error()})
@@ -79,7 +78,12 @@ func (h *Handler) ListAllTeams(ctx *gin.Context) {
 		return
 	}
 
-	res := h.teamService.WithUser(ctx).Testing(params)
+	res, err := h.teamService.WithUser(ctx).Testing(params)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, transport.ErrorResponse{Message: err.Error()})
+		return
+	}
 
 	ctx.JSON(http.StatusOK, res)
 
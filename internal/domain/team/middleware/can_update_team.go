@@ -3,21 +3,22 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"konsultn-api/internal/domain/team/service"
+	authmw "konsultn-api/internal/middleware"
 	"net/http"
 )
 
 func CanUpdateTeam(teamService *service.TeamService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userId, exists := c.Get("userId")
+		userId := authmw.CurrentUserID(c)
 
-		if !exists {
+		if userId == "" {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You are not allowed to update this team"})
 			return
 		}
 
 		teamId := c.Param("id")
 
-		allowed := teamService.CanUpdateOrDeleteTeam(teamId, userId.(string))
+		allowed := teamService.CanUpdateOrDeleteTeam(teamId, userId)
 
 		if !allowed {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You are not allowed to update this team"})
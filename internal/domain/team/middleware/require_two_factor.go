@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"konsultn-api/internal/domain/team/service"
+	authmw "konsultn-api/internal/middleware"
+	"net/http"
+)
+
+// RequireTeamTwoFactor blocks callers without two-factor authentication from teams that require it
+// Routes without a team id are not checked
+func RequireTeamTwoFactor(teamService *service.TeamService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		teamId := c.Param("id")
+		if teamId == "" {
+			c.Next()
+			return
+		}
+
+		allowed, err := teamService.MeetsTwoFactorRequirement(teamId, authmw.CurrentUserID(c))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check team requirements"})
+			return
+		}
+
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "This team requires two-factor authentication, enable it on your account to continue",
+				"code":  "two_factor_required",
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	authmw "konsultn-api/internal/middleware"
+	"net/http"
+)
+
+// RestrictTeamKey keeps API keys scoped to a team to the routes of that team
+// Routes without a team id are not available to them
+func RestrictTeamKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := authmw.CurrentPrincipal(c)
+		if !ok || principal.TeamID == "" || principal.TeamID == c.Param("id") {
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "This API key is limited to another team",
+			"code":  "api_key_team_mismatch",
+		})
+	}
+}
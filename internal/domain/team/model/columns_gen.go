@@ -0,0 +1,128 @@
+// Code generated by columngen. DO NOT EDIT.
+
+package model
+
+import "konsultn-api/internal/shared/crud/types"
+
+// TeamTable is the table of Team
+const TeamTable = "teams"
+
+// Columns of Team, qualified with its table
+const (
+	ColTeamID               types.Column = "teams.id"
+	ColTeamName             types.Column = "teams.name"
+	ColTeamSlug             types.Column = "teams.slug"
+	ColTeamDescription      types.Column = "teams.description"
+	ColTeamOwnerID          types.Column = "teams.owner_id"
+	ColTeamSettings         types.Column = "teams.settings"
+	ColTeamUpdatedBy        types.Column = "teams.updated_by"
+	ColTeamCreatedAt        types.Column = "teams.created_at"
+	ColTeamUpdatedAt        types.Column = "teams.updated_at"
+	ColTeamDeletedAt        types.Column = "teams.deleted_at"
+	ColTeamRequireTwoFactor types.Column = "teams.require_two_factor"
+)
+
+// TeamColumns gives field-style access to the columns of Team
+var TeamColumns = struct {
+	ID               types.Column
+	Name             types.Column
+	Slug             types.Column
+	Description      types.Column
+	OwnerID          types.Column
+	Settings         types.Column
+	UpdatedBy        types.Column
+	CreatedAt        types.Column
+	UpdatedAt        types.Column
+	DeletedAt        types.Column
+	RequireTwoFactor types.Column
+}{
+	ID:               ColTeamID,
+	Name:             ColTeamName,
+	Slug:             ColTeamSlug,
+	Description:      ColTeamDescription,
+	OwnerID:          ColTeamOwnerID,
+	Settings:         ColTeamSettings,
+	UpdatedBy:        ColTeamUpdatedBy,
+	CreatedAt:        ColTeamCreatedAt,
+	UpdatedAt:        ColTeamUpdatedAt,
+	DeletedAt:        ColTeamDeletedAt,
+	RequireTwoFactor: ColTeamRequireTwoFactor,
+}
+
+// TeamMemberTable is the table of TeamMember
+const TeamMemberTable = "team_members"
+
+// Columns of TeamMember, qualified with its table
+const (
+	ColTeamMemberID        types.Column = "team_members.id"
+	ColTeamMemberTeamID    types.Column = "team_members.team_id"
+	ColTeamMemberUserID    types.Column = "team_members.user_id"
+	ColTeamMemberRole      types.Column = "team_members.role"
+	ColTeamMemberJoinedAt  types.Column = "team_members.joined_at"
+	ColTeamMemberUpdatedBy types.Column = "team_members.updated_by"
+	ColTeamMemberDeletedAt types.Column = "team_members.deleted_at"
+)
+
+// TeamMemberColumns gives field-style access to the columns of TeamMember
+var TeamMemberColumns = struct {
+	ID        types.Column
+	TeamID    types.Column
+	UserID    types.Column
+	Role      types.Column
+	JoinedAt  types.Column
+	UpdatedBy types.Column
+	DeletedAt types.Column
+}{
+	ID:        ColTeamMemberID,
+	TeamID:    ColTeamMemberTeamID,
+	UserID:    ColTeamMemberUserID,
+	Role:      ColTeamMemberRole,
+	JoinedAt:  ColTeamMemberJoinedAt,
+	UpdatedBy: ColTeamMemberUpdatedBy,
+	DeletedAt: ColTeamMemberDeletedAt,
+}
+
+// TeamInvitationTable is the table of TeamInvitation
+const TeamInvitationTable = "team_invitations"
+
+// Columns of TeamInvitation, qualified with its table
+const (
+	ColTeamInvitationID         types.Column = "team_invitations.id"
+	ColTeamInvitationFromUserID types.Column = "team_invitations.from_user_id"
+	ColTeamInvitationToUserID   types.Column = "team_invitations.to_user_id"
+	ColTeamInvitationTeamID     types.Column = "team_invitations.team_id"
+	ColTeamInvitationMessage    types.Column = "team_invitations.message"
+	ColTeamInvitationStatus     types.Column = "team_invitations.status"
+	ColTeamInvitationRole       types.Column = "team_invitations.role"
+	ColTeamInvitationCreatedAt  types.Column = "team_invitations.created_at"
+	ColTeamInvitationUpdatedAt  types.Column = "team_invitations.updated_at"
+	ColTeamInvitationDeletedAt  types.Column = "team_invitations.deleted_at"
+	ColTeamInvitationExpiresAt  types.Column = "team_invitations.expires_at"
+)
+
+// TeamInvitationColumns gives field-style access to the columns of TeamInvitation
+var TeamInvitationColumns = struct {
+	ID         types.Column
+	FromUserID types.Column
+	ToUserID   types.Column
+	TeamID     types.Column
+	Message    types.Column
+	Status     types.Column
+	Role       types.Column
+	CreatedAt  types.Column
+	UpdatedAt  types.Column
+	DeletedAt  types.Column
+	ExpiresAt  types.Column
+}{
+	ID:         ColTeamInvitationID,
+	FromUserID: ColTeamInvitationFromUserID,
+	ToUserID:   ColTeamInvitationToUserID,
+	TeamID:     ColTeamInvitationTeamID,
+	Message:    ColTeamInvitationMessage,
+	Status:     ColTeamInvitationStatus,
+	Role:       ColTeamInvitationRole,
+	CreatedAt:  ColTeamInvitationCreatedAt,
+	UpdatedAt:  ColTeamInvitationUpdatedAt,
+	DeletedAt:  ColTeamInvitationDeletedAt,
+	ExpiresAt:  ColTeamInvitationExpiresAt,
+}
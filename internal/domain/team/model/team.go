@@ -1,6 +1,7 @@
 package model
 
 import (
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"konsultn-api/internal/shared"
 	"time"
@@ -9,16 +10,20 @@ import (
 type Team struct {
 	// Identifiers
 	shared.ULID `gorm:"embedded"`
-	Name        string       `gorm:"type:varchar(255);not null"`
-	Slug        string       `gorm:"type:varchar(255);uniqueIndex;not null"`
-	Description string       `gorm:"type:varchar(255)"`
-	OwnerID     string       `gorm:"type:varchar(255);not null;index"`
-	Owner       *UserView    `gorm:"-"` // ignored by GORM; populated manually
-	Members     []TeamMember `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE"`
+	Name        string         `gorm:"type:varchar(255);not null"`
+	Slug        string         `gorm:"type:varchar(255);uniqueIndex;not null"`
+	Description string         `gorm:"type:varchar(255)"`
+	OwnerID     string         `gorm:"type:varchar(255);not null;index"`
+	Owner       *UserView      `gorm:"-"` // ignored by GORM; populated manually
+	Members     []TeamMember   `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE"`
+	Settings    datatypes.JSON `gorm:"type:jsonb" swaggertype:"object"`
 	UpdatedBy   string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	DeletedAt   gorm.DeletedAt `swaggerignore:"true"`
+
+	// RequireTwoFactor makes members without two-factor authentication lose access to the team
+	RequireTwoFactor bool `gorm:"not null;default:false"`
 }
 
 type TeamSummaryView struct {
@@ -5,6 +5,9 @@ type UserView struct {
 	FirstName string
 	LastName  string
 	Email     string
+	Status    string
+	// TwoFactorEnabled is checked for teams requiring two-factor authentication
+	TwoFactorEnabled bool
 }
 
 func (UserView) TableName() string {
@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"konsultn-api/internal/domain/team/enum"
+	"konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/domain/team/repository"
+	"konsultn-api/internal/shared/crud"
+	"konsultn-api/internal/testkit"
+	"testing"
+	"time"
+)
+
+func TestTeamMembers(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	withAdmin := f.Team(f.User())
+	f.Member(withAdmin, f.User(), enum.Admin)
+	f.Team(f.User())
+
+	repo := repository.NewTeamRepository(db)
+	teams, err := repo.Members().Where(model.ColTeamMemberRole, enum.Admin.String()).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(teams) != 1 || teams[0].ID != withAdmin.ID {
+		t.Fatalf("teams with an admin = %+v", teams)
+	}
+
+	count, err := repo.Query().WhereHas("Members", func(q crud.QueryBuilder[any]) {
+		q.Where("role", enum.Admin.String())
+	}).Count()
+	if err != nil || count != 1 {
+		t.Fatalf("WhereHas count = %d, %v", count, err)
+	}
+}
+
+func TestIsTeamAdmin(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	owner, admin, member, stranger := f.User(), f.User(), f.User(), f.User()
+	team := f.Team(owner)
+	f.Member(team, admin, enum.Admin)
+	f.Member(team, member, enum.Member)
+
+	repo := repository.NewTeamMemberRepository(db)
+	cases := map[string]bool{owner.ID: true, admin.ID: true, member.ID: false, stranger.ID: false}
+	for userID, want := range cases {
+		if got := repo.IsTeamAdmin(team.ID, userID); got != want {
+			t.Errorf("IsTeamAdmin(%s) = %v, want %v", userID, got, want)
+		}
+	}
+}
+
+func TestFindValidInvitations(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	owner, invited, expired := f.User(), f.User(), f.User()
+	team := f.Team(owner)
+	valid := f.Invitation(team, owner, invited)
+	f.Invitation(team, owner, expired, func(i *model.TeamInvitation) {
+		past := time.Now().Add(-time.Hour)
+		i.ExpiresAt = &past
+	})
+	f.Invitation(f.Team(owner), owner, invited)
+
+	repo := repository.NewTeamInvitationRepository(db)
+	invitations, err := repo.FindValidInvitations(team.ID, []string{invited.ID, expired.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invitations) != 1 || invitations[0].ID != valid.ID {
+		t.Fatalf("valid invitations = %+v", invitations)
+	}
+}
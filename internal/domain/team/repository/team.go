@@ -19,5 +19,5 @@ func NewTeamRepository(db *gorm.DB) *TeamRepository {
 
 func (r TeamRepository) Members() TeamQuery {
 	qb := r.Query()
-	return qb.Join("team_members").On("id", "team_id")
+	return qb.JoinRelation("Members")
 }
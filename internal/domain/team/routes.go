@@ -7,14 +7,23 @@ import (
 	middleware2 "konsultn-api/internal/domain/team/middleware"
 	"konsultn-api/internal/domain/team/service"
 	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/ids"
+	"konsultn-api/internal/shared/security"
 )
 
 func RegisterRoutes(api *gin.RouterGroup, db *gorm.DB) {
 	teamService := service.NewTeamService(db)
 	h := handler.NewHandler(teamService)
 	canUpdateTeamMiddleware := middleware2.CanUpdateTeam(teamService)
+	requireTwoFactorMiddleware := middleware2.RequireTeamTwoFactor(teamService)
 
-	teams := api.Group("/teams", middleware.AuthMiddleware())
+	teams := api.Group("/teams",
+		middleware.AuthMiddleware(middleware.AllowAPIKeys),
+		middleware.ValidateIDParams(ids.ULID, "id", "memberId", "invitationId"),
+		middleware.RequireMethodScope(security.ScopeReadTeams, security.ScopeAdminTeam),
+		middleware2.RestrictTeamKey(),
+		requireTwoFactorMiddleware,
+	)
 	{
 		// Basic team operations
 		teams.POST("", h.CreateTeam)      // Create a team
@@ -6,6 +6,7 @@ import (
 	"konsultn-api/internal/domain/team/client"
 	"konsultn-api/internal/domain/team/model"
 	"konsultn-api/internal/domain/team/repository"
+	"konsultn-api/internal/middleware"
 	"konsultn-api/internal/shared/crud"
 	"konsultn-api/internal/shared/helper"
 )
@@ -21,9 +22,8 @@ type TeamService struct {
 
 func (s *TeamService) WithUser(ctx *gin.Context) *TeamService {
 	// Return a shallow copy with user context
-	userId, _ := ctx.Get("userId")
 	newTeamService := *s // creates a shallow copy of the struct
-	newTeamService.actingUserId = userId.(string)
+	newTeamService.actingUserId = middleware.CurrentUserID(ctx)
 	return &newTeamService
 }
 
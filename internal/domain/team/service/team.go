@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"gorm.io/gorm"
 	"konsultn-api/internal/domain/team/dto"
@@ -86,6 +87,14 @@ func (s *TeamService) UpdateTeamById(userId string, teamId string, updateTeamReq
 		team.OwnerID = *updateTeamRequest.OwnerId
 	}
 
+	if updateTeamRequest.RequireTwoFactor != nil {
+		// Admins requiring it must have it, so they do not lock themselves out of the team
+		if *updateTeamRequest.RequireTwoFactor && !s.userClient.GetUserById(userId).TwoFactorEnabled {
+			return model.Team{}, fmt.Errorf("enable two-factor authentication on your account before requiring it for the team")
+		}
+		team.RequireTwoFactor = *updateTeamRequest.RequireTwoFactor
+	}
+
 	team.UpdatedBy = userId
 
 	team, err = s.teamRepo.Save(team)
@@ -107,13 +116,13 @@ func (s *TeamService) GetAllUserTeams(params crud.QueryParams) (*crud.PaginatedR
 	userId := s.actingUserId
 
 	qb := s.teamRepo.Members().
-		Select("teams.id", "teams.name", []string{"COUNT(team_members.user_id)", "member_count"}).
-		Join("team_members", "tm_user").OnGroup(
+		Select(model.ColTeamID, model.ColTeamName, []string{"COUNT(team_members.user_id)", "member_count"}).
+		Join("team_members", "tm_user").From("teams").OnGroup(
 		func(jb crud.JoinBuilder) {
 			jb.And("id", "=", "team_id")
-			jb.And("tm_user.user_id", "=", jb.Raw(userId))
+			jb.And(model.ColTeamMemberUserID.In("tm_user").String(), "=", jb.Raw(userId))
 		}).
-		GroupBy("teams.id").
+		GroupBy(model.ColTeamID).
 		WithPageParams(params)
 
 	allTeams, err := qb.Paginate()
@@ -147,18 +156,22 @@ func (s *TeamService) GetAllUserTeams(params crud.QueryParams) (*crud.PaginatedR
 	return (*crud.PaginatedResult[model.Team])(allTeams), nil
 }
 
-func (s *TeamService) Testing(params crud.QueryParams) any {
-	res, _ := s.teamRepo.Members().
+func (s *TeamService) Testing(params crud.QueryParams) (*crud.PaginatedResult[model.TeamSummaryView], error) {
+	qb := s.teamRepo.Members().
 		Select("teams.*", []string{"COUNT(team_members.user_id)", "member_count"}).
-		Join("team_members", "tm_user").OnGroup(
+		Join("team_members", "tm_user").From("teams").OnGroup(
 		func(jb crud.JoinBuilder) {
 			jb.On("id", "=", "team_id")
-			jb.And("tm_user.user_id", "IN", jb.RawSQL("(SELECT id from users where id = ?)", s.actingUserId))
+			jb.And(model.ColTeamMemberUserID.In("tm_user").String(), "IN", jb.RawSQL("(SELECT id from users where id = ?)", s.actingUserId))
 		}).
-		GroupBy("teams.id").
-		WithPageParams(params).PaginateMap()
+		GroupBy(model.ColTeamID).
+		WithPageParams(params)
 
-	allTeams, _ := crud.ConvertPaginated[model.TeamSummaryView](res)
+	allTeams, err := crud.PaginateInto[model.TeamSummaryView](qb)
+
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user teams: %w", err)
+	}
 
 	ownerIDSet := make(map[string]struct{})
 	for _, team := range allTeams.Result {
@@ -175,15 +188,31 @@ func (s *TeamService) Testing(params crud.QueryParams) any {
 	}
 
 	// 3. Assign owners to teams
-	for _, team := range allTeams.Result {
-		if owner, ok := ownerMap[team.OwnerID]; ok {
-			team.Owner = owner
+	for i := range allTeams.Result {
+		if owner, ok := ownerMap[allTeams.Result[i].OwnerID]; ok {
+			allTeams.Result[i].Owner = owner
 		}
 	}
 
-	return allTeams
+	return allTeams, nil
 }
 
 func (s *TeamService) CanUpdateOrDeleteTeam(teamId string, userId string) bool {
 	return s.teamMemberRepo.IsTeamAdmin(teamId, userId)
 }
+
+// MeetsTwoFactorRequirement checks if the user may access a team that might require two-factor authentication
+func (s *TeamService) MeetsTwoFactorRequirement(teamId string, userId string) (bool, error) {
+	team, err := s.teamRepo.FindById(teamId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if !team.RequireTwoFactor {
+		return true, nil
+	}
+	return s.userClient.GetUserById(userId).TwoFactorEnabled, nil
+}
@@ -1,19 +1,43 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"gorm.io/gorm"
 	"konsultn-api/internal/domain/team/dto"
 	"konsultn-api/internal/domain/team/enum"
 	"konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/domain/user"
+	"strings"
 	"time"
 )
 
+// ErrInviteeNotActive is returned when an invitee does not exist or has not activated their account
+var ErrInviteeNotActive = errors.New("only active accounts can be invited")
+
 func (s *TeamService) InviteUsersToTeam(fromUserId string, teamId string, invitations []dto.AddMemberRequest) error {
 	userIds := make([]string, 0, len(invitations))
 	for _, inv := range invitations {
 		userIds = append(userIds, inv.UserId)
 	}
 
+	// Only active accounts can be invited, unverified ones have to verify their email first
+	invitees := s.userClient.GetUsersByIds(userIds)
+	inviteeMap := make(map[string]*model.UserView, len(invitees))
+	for _, invitee := range invitees {
+		inviteeMap[invitee.ID] = invitee
+	}
+
+	var inactive []string
+	for _, id := range userIds {
+		if invitee, ok := inviteeMap[id]; !ok || invitee.Status != user.StatusActive.String() {
+			inactive = append(inactive, id)
+		}
+	}
+	if len(inactive) > 0 {
+		return fmt.Errorf("%w: %s", ErrInviteeNotActive, strings.Join(inactive, ", "))
+	}
+
 	validInvites, err := s.teamInvitationRepo.FindValidInvitations(teamId, userIds)
 	if err != nil {
 		return fmt.Errorf("fetching valid invitations: %w", err)
@@ -25,10 +49,6 @@ func (s *TeamService) InviteUsersToTeam(fromUserId string, teamId string, invita
 	}
 
 	for _, invitation := range invitations {
-		if s.userClient.GetUserById(invitation.UserId).ID == "" {
-			continue
-		}
-
 		if cur, ok := existing[invitation.UserId]; ok && cur.Role == invitation.Role.String() {
 			continue
 		}
@@ -57,53 +77,65 @@ func (s *TeamService) InviteUsersToTeam(fromUserId string, teamId string, invita
 }
 
 func (s *TeamService) UpdateTeamInvitation(invitationId string, action string, actingUserId string) error {
-	// Fetch the invitation
-	invitation, err := s.teamInvitationRepo.FindById(invitationId)
-	if err != nil {
-		return fmt.Errorf("error finding invitation: %w", err)
-	}
-
-	// Check if the invitation is for the acting user
-	if invitation.ToUserID != actingUserId {
-		return fmt.Errorf("unauthorized: you are not allowed to respond to this invitation")
-	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		invitationRepo := s.teamInvitationRepo.WithTx(tx)
 
-	// Check if the invitation is still valid
-	if invitation.ExpiresAt != nil && invitation.ExpiresAt.Before(time.Now()) {
-		return fmt.Errorf("invitation has expired")
-	}
+		// Fetch and lock the invitation so concurrent accept/reject calls are serialized
+		invitation, err := invitationRepo.Query().Where("id", invitationId).ForUpdate().First()
+		if err != nil {
+			return fmt.Errorf("error finding invitation: %w", err)
+		}
 
-	// Only proceed if status is still pending
-	if invitation.Status != enum.Pending.String() {
-		return fmt.Errorf("invitation already responded to")
-	}
+		// Check if the invitation is for the acting user
+		if invitation.ToUserID != actingUserId {
+			return fmt.Errorf("unauthorized: you are not allowed to respond to this invitation")
+		}
 
-	switch action {
-	case "accept":
-		invitation.Status = enum.Accepted.String()
-
-		// Add the user as a team member
-		_, err := s.teamMemberRepo.Save(&model.TeamMember{
-			TeamID:   invitation.TeamID,
-			UserID:   invitation.ToUserID,
-			Role:     invitation.Role,
-			JoinedAt: time.Now(),
-		})
-		if err != nil {
-			return fmt.Errorf("error adding member to team: %w", err)
+		// Check if the invitation is still valid
+		if invitation.ExpiresAt != nil && invitation.ExpiresAt.Before(time.Now()) {
+			return fmt.Errorf("invitation has expired")
 		}
 
-	case "reject":
-		invitation.Status = enum.Rejected.String()
+		// Only proceed if status is still pending
+		if invitation.Status != enum.Pending.String() {
+			return fmt.Errorf("invitation already responded to")
+		}
 
-	default:
-		return fmt.Errorf("invalid action: must be 'accept' or 'reject'")
-	}
+		switch action {
+		case "accept":
+			allowed, checkErr := s.MeetsTwoFactorRequirement(invitation.TeamID, actingUserId)
+			if checkErr != nil {
+				return fmt.Errorf("error checking team requirements: %w", checkErr)
+			}
+			if !allowed {
+				return fmt.Errorf("this team requires two-factor authentication, enable it before accepting")
+			}
+
+			invitation.Status = enum.Accepted.String()
+
+			// Add the user as a team member
+			_, err := s.teamMemberRepo.WithTx(tx).Save(&model.TeamMember{
+				TeamID:   invitation.TeamID,
+				UserID:   invitation.ToUserID,
+				Role:     invitation.Role,
+				JoinedAt: time.Now(),
+			})
+			if err != nil {
+				return fmt.Errorf("error adding member to team: %w", err)
+			}
+
+		case "reject":
+			invitation.Status = enum.Rejected.String()
+
+		default:
+			return fmt.Errorf("invalid action: must be 'accept' or 'reject'")
+		}
 
-	// Persist the updated invitation
-	if _, err := s.teamInvitationRepo.Save(invitation); err != nil {
-		return fmt.Errorf("failed to update invitation status: %w", err)
-	}
+		// Persist the updated invitation
+		if _, err := invitationRepo.Save(invitation); err != nil {
+			return fmt.Errorf("failed to update invitation status: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"konsultn-api/internal/domain/team/dto"
+	"konsultn-api/internal/domain/team/enum"
+	"konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/domain/team/service"
+	"konsultn-api/internal/domain/user"
+	"konsultn-api/internal/testkit"
+	"strings"
+	"testing"
+)
+
+func TestInviteUsersToTeam(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	owner := f.User()
+	team := f.Team(owner)
+	active := f.User()
+	pending := f.User(func(u *user.User) { u.Status = user.StatusPending })
+
+	invitations := []dto.AddMemberRequest{
+		{UserId: active.ID, Role: enum.Member},
+		{UserId: pending.ID, Role: enum.Member},
+		{UserId: "missing", Role: enum.Member},
+	}
+	// The invitees are loaded with a single query
+	userQueries := 0
+	if err := db.Callback().Query().Before("gorm:query").Register("test:count_user_queries", func(tx *gorm.DB) {
+		if tx.Statement.Table == user.UserTable {
+			userQueries++
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := service.NewTeamService(db).InviteUsersToTeam(owner.ID, team.ID, invitations)
+	if userQueries != 1 {
+		t.Fatalf("expected the invitees to be loaded with 1 query, got %d", userQueries)
+	}
+	if !errors.Is(err, service.ErrInviteeNotActive) {
+		t.Fatalf("expected ErrInviteeNotActive, got %v", err)
+	}
+	if !strings.Contains(err.Error(), pending.ID) || !strings.Contains(err.Error(), "missing") || strings.Contains(err.Error(), active.ID) {
+		t.Fatalf("expected the error to name the inactive invitees, got %v", err)
+	}
+
+	// Nobody is invited while one of the invitees is not active
+	var count int64
+	db.Model(&model.TeamInvitation{}).Where("team_id = ?", team.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected no invitations, got %d", count)
+	}
+
+	if err := service.NewTeamService(db).InviteUsersToTeam(owner.ID, team.ID, invitations[:1]); err != nil {
+		t.Fatal(err)
+	}
+	db.Model(&model.TeamInvitation{}).Where("team_id = ? AND to_user_id = ?", team.ID, active.ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected 1 invitation, got %d", count)
+	}
+}
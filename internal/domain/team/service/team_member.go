@@ -2,34 +2,38 @@ package service
 
 import (
 	"fmt"
+	"gorm.io/gorm"
 	"konsultn-api/internal/domain/team/dto"
 	"konsultn-api/internal/shared/crud/types"
 )
 
 func (s *TeamService) UpdateTeamMember(teamId string, memberId string, updateMemberRequest dto.UpdateMemberRequest) error {
-	record, err := s.teamMemberRepo.FindWhere(map[string]interface{}{
-		"team_id": teamId,
-		"user_id": memberId,
-	})
-
-	if err != nil {
-		return fmt.Errorf("unable to find team member %s", err)
-	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// The row lock serializes concurrent role changes of the member
+		memberRepo := s.teamMemberRepo.WithTx(tx)
+		record, err := memberRepo.Query().
+			Where("team_id", teamId).
+			Where("user_id", memberId).
+			ForUpdate().
+			First()
+
+		if err != nil {
+			return fmt.Errorf("unable to find team member: %w", err)
+		}
+
+		if record.Role == updateMemberRequest.Role.String() {
+			return nil
+		}
+
+		record.Role = updateMemberRequest.Role.String()
+		record.UpdatedBy = s.actingUserId
+
+		if _, err = memberRepo.Save(record); err != nil {
+			return fmt.Errorf("failed to update team member: %w", err)
+		}
 
-	if record[0].Role == updateMemberRequest.Role.String() {
 		return nil
-	}
-
-	record[0].Role = updateMemberRequest.Role.String()
-	record[0].UpdatedBy = s.actingUserId
-
-	_, err = s.teamMemberRepo.Save(record[0])
-
-	if err != nil {
-		return fmt.Errorf("here")
-	}
-
-	return nil
+	})
 }
 
 func (s *TeamService) RemoveTeamMember(teamId string, memberId string) error {
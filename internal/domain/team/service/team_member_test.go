@@ -0,0 +1,43 @@
+package service_test
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"konsultn-api/internal/domain/team/dto"
+	"konsultn-api/internal/domain/team/enum"
+	"konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/domain/team/service"
+	"konsultn-api/internal/testkit"
+	"testing"
+)
+
+func TestUpdateTeamMember(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	owner := f.User()
+	team := f.Team(owner)
+	member := f.User()
+	f.Member(team, member, enum.Member)
+
+	err := service.NewTeamService(db).UpdateTeamMember(team.ID, member.ID, dto.UpdateMemberRequest{Role: enum.Admin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var record model.TeamMember
+	db.Where("team_id = ? AND user_id = ?", team.ID, member.ID).First(&record)
+	if record.Role != enum.Admin.String() {
+		t.Fatalf("expected role %s, got %s", enum.Admin, record.Role)
+	}
+}
+
+func TestUpdateTeamMemberNotFound(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	team := f.Team(f.User())
+
+	err := service.NewTeamService(db).UpdateTeamMember(team.ID, "missing", dto.UpdateMemberRequest{Role: enum.Admin})
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound, got %v", err)
+	}
+}
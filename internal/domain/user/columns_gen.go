@@ -0,0 +1,95 @@
+// Code generated by columngen. DO NOT EDIT.
+
+package user
+
+import "konsultn-api/internal/shared/crud/types"
+
+// UserTable is the table of User
+const UserTable = "users"
+
+// Columns of User, qualified with its table
+const (
+	ColUserID                   types.Column = "users.id"
+	ColUserUID                  types.Column = "users.uid"
+	ColUserFirstName            types.Column = "users.first_name"
+	ColUserLastName             types.Column = "users.last_name"
+	ColUserEmail                types.Column = "users.email"
+	ColUserPasswordHash         types.Column = "users.password_hash"
+	ColUserPhoneNumber          types.Column = "users.phone_number"
+	ColUserProfilePictureURL    types.Column = "users.profile_picture_url"
+	ColUserSocialProvider       types.Column = "users.social_provider"
+	ColUserSocialID             types.Column = "users.social_id"
+	ColUserSocialEmail          types.Column = "users.social_email"
+	ColUserSocialProfilePicture types.Column = "users.social_profile_picture"
+	ColUserStatus               types.Column = "users.status"
+	ColUserLastLogin            types.Column = "users.last_login"
+	ColUserCreatedAt            types.Column = "users.created_at"
+	ColUserUpdatedAt            types.Column = "users.updated_at"
+	ColUserDeletedAt            types.Column = "users.deleted_at"
+	ColUserVerificationToken    types.Column = "users.verification_token"
+	ColUserVerificationExpiry   types.Column = "users.verification_expiry"
+	ColUserResetToken           types.Column = "users.reset_token"
+	ColUserResetTokenExpiry     types.Column = "users.reset_token_expiry"
+	ColUserTwoFactorEnabled     types.Column = "users.two_factor_enabled"
+	ColUserTwoFactorSecret      types.Column = "users.two_factor_secret"
+	ColUserTwoFactorLastStep    types.Column = "users.two_factor_last_step"
+	ColUserRoles                types.Column = "users.roles"
+	ColUserCustomClaims         types.Column = "users.custom_claims"
+)
+
+// UserColumns gives field-style access to the columns of User
+var UserColumns = struct {
+	ID                   types.Column
+	UID                  types.Column
+	FirstName            types.Column
+	LastName             types.Column
+	Email                types.Column
+	PasswordHash         types.Column
+	PhoneNumber          types.Column
+	ProfilePictureURL    types.Column
+	SocialProvider       types.Column
+	SocialID             types.Column
+	SocialEmail          types.Column
+	SocialProfilePicture types.Column
+	Status               types.Column
+	LastLogin            types.Column
+	CreatedAt            types.Column
+	UpdatedAt            types.Column
+	DeletedAt            types.Column
+	VerificationToken    types.Column
+	VerificationExpiry   types.Column
+	ResetToken           types.Column
+	ResetTokenExpiry     types.Column
+	TwoFactorEnabled     types.Column
+	TwoFactorSecret      types.Column
+	TwoFactorLastStep    types.Column
+	Roles                types.Column
+	CustomClaims         types.Column
+}{
+	ID:                   ColUserID,
+	UID:                  ColUserUID,
+	FirstName:            ColUserFirstName,
+	LastName:             ColUserLastName,
+	Email:                ColUserEmail,
+	PasswordHash:         ColUserPasswordHash,
+	PhoneNumber:          ColUserPhoneNumber,
+	ProfilePictureURL:    ColUserProfilePictureURL,
+	SocialProvider:       ColUserSocialProvider,
+	SocialID:             ColUserSocialID,
+	SocialEmail:          ColUserSocialEmail,
+	SocialProfilePicture: ColUserSocialProfilePicture,
+	Status:               ColUserStatus,
+	LastLogin:            ColUserLastLogin,
+	CreatedAt:            ColUserCreatedAt,
+	UpdatedAt:            ColUserUpdatedAt,
+	DeletedAt:            ColUserDeletedAt,
+	VerificationToken:    ColUserVerificationToken,
+	VerificationExpiry:   ColUserVerificationExpiry,
+	ResetToken:           ColUserResetToken,
+	ResetTokenExpiry:     ColUserResetTokenExpiry,
+	TwoFactorEnabled:     ColUserTwoFactorEnabled,
+	TwoFactorSecret:      ColUserTwoFactorSecret,
+	TwoFactorLastStep:    ColUserTwoFactorLastStep,
+	Roles:                ColUserRoles,
+	CustomClaims:         ColUserCustomClaims,
+}
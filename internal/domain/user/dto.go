@@ -1,5 +1,7 @@
 package user
 
+import "time"
+
 type CreateUserRequest struct {
 	Email     string
 	Password  string
@@ -9,3 +11,34 @@ type CreateUserRequest struct {
 
 type UpdateUserRequest struct {
 }
+
+// PublicUserResponse is a user as seen by other users
+type PublicUserResponse struct {
+	ID                string `json:"id"`
+	FirstName         string `json:"first_name"`
+	LastName          string `json:"last_name"`
+	ProfilePictureURL string `json:"profile_picture_url"`
+}
+
+// UserResponse is a user as seen by themselves
+type UserResponse struct {
+	PublicUserResponse
+	Email                string     `json:"email"`
+	PhoneNumber          string     `json:"phone_number"`
+	Status               Status     `json:"status"`
+	Roles                []string   `json:"roles"`
+	TwoFactorEnabled     bool       `json:"two_factor_enabled"`
+	SocialProvider       string     `json:"social_provider"`
+	SocialEmail          string     `json:"social_email"`
+	SocialProfilePicture string     `json:"social_profile_picture"`
+	LastLogin            *time.Time `json:"last_login"`
+	CreatedAt            time.Time  `json:"created_at"`
+}
+
+// AdminUserResponse is a user as seen by platform admins
+type AdminUserResponse struct {
+	UserResponse
+	UID       string     `json:"uid"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
@@ -1,9 +1,8 @@
 package user
 
 import (
-	"firebase.google.com/go/v4/auth"
 	"github.com/gin-gonic/gin"
-	"konsultn-api/pkg/firebase"
+	"konsultn-api/internal/middleware"
 	"net/http"
 )
 
@@ -21,7 +20,13 @@ func (h *Handler) ListAllUsers(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch users"})
 		return
 	}
-	ctx.JSON(http.StatusOK, users)
+
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	response := make([]interface{}, 0, len(users))
+	for _, user := range users {
+		response = append(response, ToUserView(user, VisibilityFor(principal, user.ID)))
+	}
+	ctx.JSON(http.StatusOK, response)
 }
 
 func (h *Handler) GetUserById(ctx *gin.Context) {
@@ -31,48 +36,9 @@ func (h *Handler) GetUserById(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user with id " + id + " not found"})
 		return
 	}
-	ctx.JSON(http.StatusOK, user)
-}
-
-func (h *Handler) CreateUser(ctx *gin.Context) {
-	var user CreateUserRequest
-	if err := ctx.ShouldBindJSON(&user); err != nil {
-		// If there is an error (invalid JSON or missing fields), return an error response
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	params := (&auth.UserToCreate{}).
-		Email(user.Email).
-		Password(user.Password)
-
-	fbUser, err := firebase.AuthClient.CreateUser(ctx, params)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	customToken, err := firebase.AuthClient.CustomToken(ctx, fbUser.UID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create auth token"})
-		return
-	}
-
-	// Process the user data (e.g., save it to the database)
-	var userModel = ToUserModel(user)
-	userModel.UID = fbUser.UID
-	createdUser, creationError := h.repo.Save(&userModel)
-
-	if creationError != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": creationError.Error()})
-	}
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": "User created successfully",
-		"user":    createdUser,
-		"token":   customToken,
-	})
 
+	principal, _ := middleware.CurrentPrincipal(ctx)
+	ctx.JSON(http.StatusOK, ToUserView(user, VisibilityFor(principal, user.ID)))
 }
 
 func (h *Handler) DeleteUser(ctx *gin.Context) {
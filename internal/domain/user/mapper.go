@@ -5,12 +5,61 @@ package user
 ///*
 
 func ToUserModel(dto CreateUserRequest) User {
+	// The password goes to the identity provider only, it is never stored on the user
 	return User{
-		Email:    dto.Email,
-		Password: dto.Password,
+		Email: dto.Email,
 	}
 	//var user *User
 	//shared.Mapper(dto, &user)
 	//
 	//return user
 }
+
+// ToUserView maps a user to the response view of the visibility
+func ToUserView(user *User, visibility Visibility) interface{} {
+	switch visibility {
+	case VisibilityAdmin:
+		return ToAdminUserResponse(user)
+	case VisibilitySelf:
+		return ToUserResponse(user)
+	default:
+		return ToPublicUserResponse(user)
+	}
+}
+
+func ToPublicUserResponse(user *User) PublicUserResponse {
+	return PublicUserResponse{
+		ID:                user.ID,
+		FirstName:         user.FirstName,
+		LastName:          user.LastName,
+		ProfilePictureURL: user.ProfilePictureURL,
+	}
+}
+
+func ToUserResponse(user *User) UserResponse {
+	return UserResponse{
+		PublicUserResponse:   ToPublicUserResponse(user),
+		Email:                user.Email,
+		PhoneNumber:          user.PhoneNumber,
+		Status:               user.Status,
+		Roles:                user.Roles,
+		TwoFactorEnabled:     user.TwoFactorEnabled,
+		SocialProvider:       user.SocialProvider,
+		SocialEmail:          user.SocialEmail,
+		SocialProfilePicture: user.SocialProfilePicture,
+		LastLogin:            user.LastLogin,
+		CreatedAt:            user.CreatedAt,
+	}
+}
+
+func ToAdminUserResponse(user *User) AdminUserResponse {
+	response := AdminUserResponse{
+		UserResponse: ToUserResponse(user),
+		UID:          user.UID,
+		UpdatedAt:    user.UpdatedAt,
+	}
+	if user.DeletedAt.Valid {
+		response.DeletedAt = &user.DeletedAt.Time
+	}
+	return response
+}
@@ -1,34 +1,48 @@
 package user
 
 import (
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"konsultn-api/internal/shared"
+	"konsultn-api/internal/shared/sensitive"
 	"time"
 )
 
+// User is an account of the platform, render it through the response views of ToUserView
+// Fields tagged sensitive hold secrets, see the sensitive package
 type User struct {
 	shared.ULID          `gorm:"embedded"`
 	UID                  string `gorm:"size:255;unique"`
 	FirstName            string `gorm:"size:255"`
 	LastName             string `gorm:"size:255"`
 	Email                string `gorm:"unique;size:255;not null"`
-	Password             string `json:"password"` // raw password from user
-	PasswordHash         string `gorm:"size:255" json:"-"`
+	PasswordHash         string `gorm:"size:255" json:"-" sensitive:"true"` // bcrypt, only set by the local identity provider
 	PhoneNumber          string `gorm:"size:20"`
 	ProfilePictureURL    string `gorm:"size:255"`
 	SocialProvider       string `gorm:"size:50"`
-	SocialID             string `gorm:"size:255"`
+	SocialID             string `gorm:"size:255" sensitive:"true"`
 	SocialEmail          string `gorm:"size:255"`
 	SocialProfilePicture string `gorm:"size:255"`
-	AccessToken          string `gorm:"size:255"`
-	RefreshToken         string `gorm:"size:255"`
-	Status               string `gorm:"size:255;default:'PENDING VERIFICATION'"`
+	Status               Status `gorm:"size:255;default:'PENDING VERIFICATION'"`
 	LastLogin            *time.Time
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
 	DeletedAt            gorm.DeletedAt `json:"deleted_at" swaggerignore:"true"`
-	ResetToken           string         `gorm:"size:255"`
+	VerificationToken    string         `gorm:"size:64;index" json:"-" sensitive:"true"` // digest of the email verification token
+	VerificationExpiry   *time.Time     `json:"-"`
+	ResetToken           string         `gorm:"size:255" sensitive:"true"`
 	ResetTokenExpiry     *time.Time
 	TwoFactorEnabled     bool   `gorm:"default:false"`
-	TwoFactorSecret      string `gorm:"size:255"`
+	TwoFactorSecret      string `gorm:"size:255" json:"-" sensitive:"true"` // encrypted, see security.Cipher
+	TwoFactorLastStep    int64  `json:"-"`                                  // TOTP time step last accepted, codes are single use
+	// Roles are the platform roles of the user, see security.PlatformRoles
+	Roles datatypes.JSONSlice[string] `gorm:"type:jsonb;default:'[\"freelancer\"]'" swaggertype:"array,string"`
+	// CustomClaims are added to the tokens issued by the local identity provider
+	CustomClaims datatypes.JSON `gorm:"type:jsonb" json:"-" swaggerignore:"true" sensitive:"true"`
+}
+
+// MarshalJSON leaves the sensitive fields out, or fails in strict mode, as users are rendered through their views
+func (u User) MarshalJSON() ([]byte, error) {
+	type plain User
+	return sensitive.Marshal(plain(u))
 }
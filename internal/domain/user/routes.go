@@ -4,16 +4,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"konsultn-api/internal/middleware"
+	"konsultn-api/internal/shared/ids"
 )
 
 func RegisterRoutes(api *gin.RouterGroup, db *gorm.DB) {
-	user := api.Group("/users", middleware.AuthMiddleware())
+	user := api.Group("/users", middleware.AuthMiddleware(), middleware.ValidateIDParams(ids.ULID))
 	repo := NewRepository(db)
 	h := NewHandler(repo)
 	{
 		user.GET("", h.ListAllUsers)
 		user.GET("/:id", h.GetUserById)
-		user.POST("", h.CreateUser)
 		user.DELETE("/:id", h.DeleteUser)
 	}
 }
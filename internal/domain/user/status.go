@@ -0,0 +1,26 @@
+package user
+
+// Status is the lifecycle state of an account
+type Status string
+
+// Account statuses, new accounts are pending until they verify their email
+const (
+	StatusPending   Status = "PENDING VERIFICATION"
+	StatusActive    Status = "ACTIVE"
+	StatusSuspended Status = "SUSPENDED"
+	StatusDeleted   Status = "DELETED"
+)
+
+// String method to get string representation of Status
+func (s Status) String() string {
+	return string(s)
+}
+
+// IsValidStatus checks if the given status is valid
+func IsValidStatus(status Status) bool {
+	switch status {
+	case StatusPending, StatusActive, StatusSuspended, StatusDeleted:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package user
+
+import "konsultn-api/internal/shared/security"
+
+// Visibility is how much of a user a caller may see
+type Visibility int
+
+const (
+	VisibilityPublic Visibility = iota
+	VisibilitySelf
+	VisibilityAdmin
+)
+
+// VisibilityFor returns what the principal may see of the user with userID
+// Platform admins see every user in full, users see themselves and only the public profile of others
+func VisibilityFor(principal *security.Principal, userID string) Visibility {
+	switch {
+	case principal == nil:
+		return VisibilityPublic
+	case principal.HasRole(security.RolePlatformAdmin):
+		return VisibilityAdmin
+	case principal.UserID == userID:
+		return VisibilitySelf
+	default:
+		return VisibilityPublic
+	}
+}
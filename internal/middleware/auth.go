@@ -1,14 +1,86 @@
 package middleware
 
 import (
-	"konsultn-api/pkg/firebase"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"konsultn-api/internal/shared/security"
 	"net/http"
 	"strings"
+)
 
-	"github.com/gin-gonic/gin"
+// principalKey is the gin context key of the authenticated principal
+const principalKey = "principal"
+
+// tokenVerifier verifies the tokens of AuthMiddleware, it is configured at startup
+var tokenVerifier security.TokenVerifier
+
+// SetTokenVerifier sets the verifier used by AuthMiddleware
+func SetTokenVerifier(verifier security.TokenVerifier) {
+	tokenVerifier = verifier
+}
+
+// apiKeyVerifier verifies the API keys of AuthMiddleware, it is configured at startup
+var apiKeyVerifier security.TokenVerifier
+
+// SetAPIKeyVerifier sets the verifier of bearer tokens starting with security.APIKeyPrefix
+func SetAPIKeyVerifier(verifier security.TokenVerifier) {
+	apiKeyVerifier = verifier
+}
+
+// Errors returned by an AccountGate for accounts that may not use the API
+var (
+	ErrAccountUnverified = errors.New("email address is not verified")
+	ErrAccountInactive   = errors.New("account is suspended or deleted")
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AccountGate checks the status of the account of a principal
+type AccountGate interface {
+	// CheckAccount returns ErrAccountUnverified or ErrAccountInactive for accounts that may not use the API
+	CheckAccount(ctx context.Context, principal *security.Principal) error
+}
+
+// accountGate checks the accounts of AuthMiddleware, it is configured at startup
+var accountGate AccountGate
+
+// SetAccountGate sets the gate AuthMiddleware checks accounts with, accounts are not checked without one
+func SetAccountGate(gate AccountGate) {
+	accountGate = gate
+}
+
+// AuthOption relaxes the checks of AuthMiddleware
+type AuthOption func(*authOptions)
+
+type authOptions struct {
+	allowUnverified bool
+	allowAPIKeys    bool
+}
+
+// AllowUnverified lets accounts that did not verify their email yet through, for the endpoints they need before
+func AllowUnverified(options *authOptions) {
+	options.allowUnverified = true
+}
+
+// AllowAPIKeys lets API keys through, the routes check their scopes with RequireScope
+func AllowAPIKeys(options *authOptions) {
+	options.allowAPIKeys = true
+}
+
+// AuthMiddleware authenticates requests with the verifier set by SetTokenVerifier
+func AuthMiddleware(options ...AuthOption) gin.HandlerFunc {
+	return Authenticate(nil, options...)
+}
+
+// Authenticate rejects requests without a valid bearer token and puts the principal of the token on the context
+// A nil verifier uses the one set by SetTokenVerifier, resolved on each request.
+// API keys are verified by the verifier set by SetAPIKeyVerifier and forbidden unless AllowAPIKeys is given.
+// Accounts rejected by the gate set by SetAccountGate are forbidden
+func Authenticate(verifier security.TokenVerifier, options ...AuthOption) gin.HandlerFunc {
+	var opts authOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
@@ -16,18 +88,82 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		idToken := strings.TrimPrefix(authHeader, "Bearer ")
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		isAPIKey := security.IsAPIKey(token)
+		if isAPIKey && !opts.allowAPIKeys {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "API keys cannot access this endpoint",
+				"code":  "api_key_not_allowed",
+			})
+			return
+		}
+
+		v := verifier
+		if isAPIKey {
+			v = apiKeyVerifier
+		} else if v == nil {
+			v = tokenVerifier
+		}
+		if v == nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Authentication is not configured"})
+			return
+		}
 
-		token, err := firebase.AuthClient.VerifyIDToken(c, idToken)
+		principal, err := v.Verify(c, token)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
 
-		// Pass token UID and email to context
-		c.Set("uid", token.UID)
-		c.Set("email", token.Claims["email"])
-		c.Set("userId", token.Claims["userId"])
+		if accountGate != nil {
+			if err := accountGate.CheckAccount(c, principal); err != nil {
+				switch {
+				case errors.Is(err, ErrAccountUnverified):
+					if !opts.allowUnverified {
+						c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+							"error": "Verify your email address to continue",
+							"code":  "email_unverified",
+						})
+						return
+					}
+				case errors.Is(err, ErrAccountInactive):
+					c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+						"error": "This account is suspended or deleted",
+						"code":  "account_inactive",
+					})
+					return
+				default:
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check account"})
+					return
+				}
+			}
+		}
+
+		SetPrincipal(c, principal)
 		c.Next()
 	}
 }
+
+// SetPrincipal puts the principal on the gin context and on the context of its request
+func SetPrincipal(c *gin.Context, principal *security.Principal) {
+	c.Set(principalKey, principal)
+	c.Request = c.Request.WithContext(security.WithPrincipal(c.Request.Context(), principal))
+}
+
+// CurrentPrincipal returns the principal authenticated by AuthMiddleware
+func CurrentPrincipal(c *gin.Context) (*security.Principal, bool) {
+	value, exists := c.Get(principalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*security.Principal)
+	return principal, ok && principal != nil
+}
+
+// CurrentUserID returns the users table ID of the authenticated principal, or "" when there is none
+func CurrentUserID(c *gin.Context) string {
+	if principal, ok := CurrentPrincipal(c); ok {
+		return principal.UserID
+	}
+	return ""
+}
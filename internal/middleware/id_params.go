@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"konsultn-api/internal/shared/ids"
+	"net/http"
+)
+
+// ValidateIDParams rejects requests whose ID path params are not well-formed for the given strategy
+// Params that are not part of the matched route are skipped, "id" is checked when none are given
+func ValidateIDParams(strategy ids.Strategy, params ...string) gin.HandlerFunc {
+	if len(params) == 0 {
+		params = []string{"id"}
+	}
+
+	return func(c *gin.Context) {
+		for _, name := range params {
+			value := c.Param(name)
+			if value == "" {
+				continue
+			}
+
+			if !strategy.Valid(value) {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Malformed " + name + " parameter"})
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitKey identifies the client a request is counted for
+type RateLimitKey func(c *gin.Context) string
+
+// ByClientIP counts requests per client IP
+func ByClientIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// RateLimit allows limit requests per window for each key, with bursts up to limit
+// Counters live in memory, so every instance of the API limits on its own
+func RateLimit(limit int, window time.Duration, key RateLimitKey) gin.HandlerFunc {
+	limiter := newKeyedLimiter(rate.Every(window/time.Duration(limit)), limit, window)
+
+	return func(c *gin.Context) {
+		reservation := limiter.get(key(c)).Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			// The request is rejected, so it must not consume a token
+			reservation.Cancel()
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, try again later"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// keyedLimiter holds a token bucket per key and forgets the ones idle for longer than idle
+type keyedLimiter struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	idle     time.Duration
+	limiters map[string]*keyedEntry
+	swept    time.Time
+}
+
+type keyedEntry struct {
+	limiter *rate.Limiter
+	seen    time.Time
+}
+
+func newKeyedLimiter(limit rate.Limit, burst int, idle time.Duration) *keyedLimiter {
+	return &keyedLimiter{limit: limit, burst: burst, idle: idle, limiters: make(map[string]*keyedEntry), swept: time.Now()}
+}
+
+func (l *keyedLimiter) get(key string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.swept) > l.idle {
+		for k, entry := range l.limiters {
+			if now.Sub(entry.seen) > l.idle {
+				delete(l.limiters, k)
+			}
+		}
+		l.swept = now
+	}
+
+	entry, ok := l.limiters[key]
+	if !ok {
+		entry = &keyedEntry{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = entry
+	}
+	entry.seen = now
+	return entry.limiter
+}
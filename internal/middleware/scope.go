@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RequireScope rejects API keys that were not granted scope, callers signed in with an ID token have every scope
+// It must run after AuthMiddleware
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		checkScope(c, scope)
+	}
+}
+
+// RequireMethodScope requires the read scope for safe methods and the write scope for the others
+// It must run after AuthMiddleware
+func RequireMethodScope(read, write string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			checkScope(c, read)
+		default:
+			checkScope(c, write)
+		}
+	}
+}
+
+func checkScope(c *gin.Context, scope string) {
+	principal, ok := CurrentPrincipal(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if !principal.HasScope(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "This API key is missing the " + scope + " scope",
+			"code":  "insufficient_scope",
+		})
+		return
+	}
+	c.Next()
+}
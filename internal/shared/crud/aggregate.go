@@ -0,0 +1,31 @@
+package crud
+
+import (
+	"konsultn-api/internal/shared/crud/builder"
+	"konsultn-api/internal/shared/crud/types"
+)
+
+// Sum returns the typed sum of a column over the rows matching the query
+func Sum[N types.Number, T any](qb QueryBuilder[T], column Column) (N, error) {
+	return builder.Sum[N](qb, column)
+}
+
+// Avg returns the average of a column over the rows matching the query
+func Avg[T any](qb QueryBuilder[T], column Column) (float64, error) {
+	return builder.Avg(qb, column)
+}
+
+// Min returns the typed minimum of a column over the rows matching the query
+func Min[V any, T any](qb QueryBuilder[T], column Column) (V, error) {
+	return builder.Min[V](qb, column)
+}
+
+// Max returns the typed maximum of a column over the rows matching the query
+func Max[V any, T any](qb QueryBuilder[T], column Column) (V, error) {
+	return builder.Max[V](qb, column)
+}
+
+// Pluck returns the typed values of a single column for the rows matching the query
+func Pluck[V any, T any](qb QueryBuilder[T], column Column) ([]V, error) {
+	return builder.Pluck[V](qb, column)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"gorm.io/gorm"
 	"konsultn-api/internal/shared/crud/builder/utils"
+	"konsultn-api/internal/shared/crud/pagination"
 	"konsultn-api/internal/shared/crud/types"
 	"strings"
 )
@@ -33,18 +34,18 @@ func (qb *QueryBuilder[T]) Cast(value interface{}, dataType string) types.RawVal
 }
 
 // Coalesce creates a parameterized COALESCE expression
-func (qb *QueryBuilder[T]) Coalesce(fields []string, defaultValue ...interface{}) types.RawValue {
+func (qb *QueryBuilder[T]) Coalesce(fields []types.Column, defaultValue ...interface{}) types.RawValue {
 	var args []interface{}
 	var placeholders []string
 
 	// Convert field names to proper SQL
 	for _, field := range fields {
-		if strings.Contains(field, ".") {
+		if strings.Contains(string(field), ".") {
 			// Field name with table qualifier
-			placeholders = append(placeholders, field)
+			placeholders = append(placeholders, string(field))
 		} else {
 			// Simple field name
-			placeholders = append(placeholders, utils.Quote(field))
+			placeholders = append(placeholders, utils.Quote(string(field)))
 		}
 	}
 
@@ -61,14 +62,15 @@ func (qb *QueryBuilder[T]) Coalesce(fields []string, defaultValue ...interface{}
 // This function is used to group rows that have the same values in specified columns
 // into aggregated results
 // Parameters:
-//   - fields: One or more field names to group by
+//   - fields: One or more field names or generated columns to group by
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) GroupBy(fields ...string) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) GroupBy(fields ...types.Column) types.QueryBuilder[T] {
+	quoted := make([]string, len(fields))
 	for i, field := range fields {
-		fields[i] = utils.Quote(field)
+		quoted[i] = utils.Quote(string(field))
 	}
-	qb.DB = qb.DB.Group(strings.Join(fields, ", "))
+	qb.DB = qb.DB.Group(strings.Join(quoted, ", "))
 	return qb
 }
 
@@ -92,8 +94,8 @@ func (qb *QueryBuilder[T]) Having(condition string, args ...interface{}) types.Q
 //   - op: The operator to use for comparison
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) HavingField(field string, value interface{}, op Operator) types.QueryBuilder[T] {
-	clause := fmt.Sprintf("%s %s ?", utils.Quote(field), string(op))
+func (qb *QueryBuilder[T]) HavingField(field types.Column, value interface{}, op Operator) types.QueryBuilder[T] {
+	clause := fmt.Sprintf("%s %s ?", utils.Quote(string(field)), string(op))
 	qb.DB = qb.DB.Having(clause, value)
 	return qb
 }
@@ -105,7 +107,7 @@ func (qb *QueryBuilder[T]) HavingField(field string, value interface{}, op Opera
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) HavingEQ(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) HavingEQ(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.HavingField(field, value, EQ)
 }
 
@@ -116,7 +118,7 @@ func (qb *QueryBuilder[T]) HavingEQ(field string, value interface{}) types.Query
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) HavingNEQ(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) HavingNEQ(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.HavingField(field, value, NEQ)
 }
 
@@ -127,7 +129,7 @@ func (qb *QueryBuilder[T]) HavingNEQ(field string, value interface{}) types.Quer
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) HavingGT(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) HavingGT(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.HavingField(field, value, GT)
 }
 
@@ -138,7 +140,7 @@ func (qb *QueryBuilder[T]) HavingGT(field string, value interface{}) types.Query
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) HavingGTE(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) HavingGTE(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.HavingField(field, value, GTE)
 }
 
@@ -149,7 +151,7 @@ func (qb *QueryBuilder[T]) HavingGTE(field string, value interface{}) types.Quer
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) HavingLT(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) HavingLT(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.HavingField(field, value, LT)
 }
 
@@ -160,7 +162,7 @@ func (qb *QueryBuilder[T]) HavingLT(field string, value interface{}) types.Query
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) HavingLTE(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) HavingLTE(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.HavingField(field, value, LTE)
 }
 
@@ -171,8 +173,8 @@ func (qb *QueryBuilder[T]) HavingLTE(field string, value interface{}) types.Quer
 //   - values: A slice of values to check against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) HavingIN(field string, values interface{}) types.QueryBuilder[T] {
-	clause := fmt.Sprintf("%s IN (?)", utils.Quote(field))
+func (qb *QueryBuilder[T]) HavingIN(field types.Column, values interface{}) types.QueryBuilder[T] {
+	clause := fmt.Sprintf("%s IN (?)", utils.Quote(string(field)))
 	qb.DB = qb.DB.Having(clause, values)
 	return qb
 }
@@ -183,8 +185,8 @@ func (qb *QueryBuilder[T]) HavingIN(field string, values interface{}) types.Quer
 //   - values: A slice of values to check against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) HavingBetween(field string, min, max interface{}) types.QueryBuilder[T] {
-	clause := fmt.Sprintf("%s BETWEEN ? AND ?", utils.Quote(field))
+func (qb *QueryBuilder[T]) HavingBetween(field types.Column, min, max interface{}) types.QueryBuilder[T] {
+	clause := fmt.Sprintf("%s BETWEEN ? AND ?", utils.Quote(string(field)))
 	qb.DB = qb.DB.Having(clause, min, max)
 	return qb
 }
@@ -237,9 +239,7 @@ func (qb *QueryBuilder[T]) OrHavingGroup(callback func(types.QueryBuilder[T])) t
 //   - int64: The number of matching records
 //   - error: Any error that occurred during counting
 func (qb *QueryBuilder[T]) Count() (int64, error) {
-	var count int64
-	err := qb.build().Count(&count).Error
-	return count, err
+	return countExact(qb.buildBase())
 }
 
 // Exists checks if any records match the current query conditions
@@ -251,3 +251,60 @@ func (qb *QueryBuilder[T]) Exists() (bool, error) {
 	count, err := qb.Count()
 	return count > 0, err
 }
+
+// Facets counts the rows of the current filtered query per distinct value of each column
+// This is meant to be returned next to a PaginatedResult, e.g. tasks per status and priority
+// The ordering and limit of the query are ignored, every matching row is counted
+// Parameters:
+//   - columns: The columns to compute value counts for, unqualified columns resolve against the base table
+//
+// Returns:
+//   - pagination.Facets: The value counts keyed by column, most frequent value first
+//   - error: Any error that occurred during counting
+func (qb *QueryBuilder[T]) Facets(columns ...types.Column) (pagination.Facets, error) {
+	base := qb.buildBase()
+	if _, ok := base.Statement.Clauses["GROUP BY"]; ok {
+		return nil, fmt.Errorf("facets are not supported on grouped queries")
+	}
+
+	facets := make(pagination.Facets, len(columns))
+	for _, column := range columns {
+		field := string(column)
+		if !strings.Contains(field, ".") && !qb.knownAliases[field] {
+			field = qb.baseTable + "." + field
+		}
+		quoted := utils.Quote(field)
+
+		db := base.Session(&gorm.Session{}).
+			Select(fmt.Sprintf("%s AS %s, COUNT(*) AS %s", quoted, utils.Quote("value"), utils.Quote("count")))
+		// The ordering and limit of the query apply to its rows, not to the groups counted here
+		delete(db.Statement.Clauses, "ORDER BY")
+		delete(db.Statement.Clauses, "LIMIT")
+
+		rows, err := db.
+			Group(quoted).
+			Order(fmt.Sprintf("%s DESC", utils.Quote("count"))).
+			Rows()
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute facets for '%s': %w", column, err)
+		}
+
+		counts := make([]pagination.FacetCount, 0)
+		for rows.Next() {
+			var facet pagination.FacetCount
+			if err := rows.Scan(&facet.Value, &facet.Count); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("failed to scan facets for '%s': %w", column, err)
+			}
+			counts = append(counts, facet)
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read facets for '%s': %w", column, err)
+		}
+		facets[string(column)] = counts
+	}
+
+	return facets, nil
+}
@@ -0,0 +1,53 @@
+package builder_test
+
+import (
+	"konsultn-api/internal/domain/task"
+	"konsultn-api/internal/shared/crud/builder"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/testkit"
+	"testing"
+)
+
+func TestFacetsIgnoreOrderAndLimit(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	for _, status := range []string{"open", "open", "done"} {
+		f.Task(nil, func(t *task.Task) { t.Status = status })
+	}
+
+	facets, err := builder.NewQueryBuilder[task.Task](db.Model(&task.Task{})).
+		OrderBy("created_at", "desc").
+		Limit(1).
+		Facets("status")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := facets["status"]
+	if len(counts) != 2 || counts[0].Value != "open" || counts[0].Count != 2 || counts[1].Count != 1 {
+		t.Fatalf("unexpected facets %+v", counts)
+	}
+}
+
+func TestAggregatesIgnoreOrderAndLimit(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	for _, status := range []string{"open", "open", "done"} {
+		f.Task(nil, func(t *task.Task) { t.Status = status })
+	}
+	ordered := func() types.QueryBuilder[task.Task] {
+		return builder.NewQueryBuilder[task.Task](db.Model(&task.Task{})).
+			OrderBy(task.ColTaskCreatedAt, "desc").
+			Limit(1)
+	}
+
+	if sum, err := builder.Sum[int](ordered(), "length(status)"); err != nil || sum != 12 {
+		t.Fatalf("Sum = %d, %v", sum, err)
+	}
+	if first, err := builder.Min[string](ordered(), task.ColTaskStatus); err != nil || first != "done" {
+		t.Fatalf("Min = %q, %v", first, err)
+	}
+	if average, err := builder.Avg(ordered(), "length(status)"); err != nil || average != 4 {
+		t.Fatalf("Avg = %v, %v", average, err)
+	}
+}
@@ -2,7 +2,9 @@ package builder
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"konsultn-api/internal/shared/crud/builder/utils"
+	"konsultn-api/internal/shared/crud/pagination"
 	"konsultn-api/internal/shared/crud/types"
 )
 
@@ -15,6 +17,9 @@ type QueryBuilder[T any] struct {
 	knownAliases    map[string]bool
 	orderClause     string
 	joins           []JoinClause
+	relationJoins   map[string]string
+	lock            *clause.Locking
+	countMode       pagination.CountMode
 	page            int
 	limit           int
 }
@@ -54,11 +59,17 @@ func (qb *QueryBuilder[T]) Unscoped() types.QueryBuilder[T] {
 	return qb
 }
 
-// build assembles the query with all conditions and joins
+// build assembles the query with all conditions, joins and the row lock
+// The joins are applied to a session copy, so the builder can be executed more than once
 func (qb *QueryBuilder[T]) build() *gorm.DB {
-	db := qb.DB.Session(&gorm.Session{NewDB: true})
-	db = qb.buildJoins()
-	return db
+	return qb.buildLock(qb.buildBase())
+}
+
+// buildBase assembles the query with all conditions and joins but without the row lock
+// It is used by aggregates like Count, since PostgreSQL rejects FOR UPDATE with aggregate functions
+func (qb *QueryBuilder[T]) buildBase() *gorm.DB {
+	db := qb.DB.Session(&gorm.Session{})
+	return qb.buildJoins(db)
 }
 
 // Raw creates a safe raw SQL fragment
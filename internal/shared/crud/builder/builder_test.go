@@ -0,0 +1,44 @@
+package builder_test
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"time"
+)
+
+// widget is the model the builder tests query
+type widget struct {
+	ID           string `gorm:"primaryKey"`
+	Name         string
+	Status       string
+	Price        int
+	CustomFields datatypes.JSON `gorm:"type:jsonb"`
+	CreatedAt    time.Time
+	DeletedAt    gorm.DeletedAt
+}
+
+// dryRun returns a Postgres connection that only renders SQL, so the generated statements can be compared
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1 sslmode=disable"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// assertSQL fails unless the statement contains every fragment
+func assertSQL(t *testing.T, sql string, fragments ...string) {
+	t.Helper()
+	for _, fragment := range fragments {
+		if !strings.Contains(sql, fragment) {
+			t.Errorf("expected %q in\n%s", fragment, sql)
+		}
+	}
+}
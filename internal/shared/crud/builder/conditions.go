@@ -13,11 +13,12 @@ import (
 // It processes different types of field specifications and builds the appropriate SQL clauses
 // Parameters:
 //   - distinct: Whether to use DISTINCT selection
-//   - fields: The fields to select, which can be strings, string slices for aliases, or subqueries
+//   - fields: The fields to select, which can be strings, string slices for aliases, raw values or subqueries
 //
 // Returns: The query builder for method chaining
 func (qb *QueryBuilder[T]) withFields(distinct bool, fields ...interface{}) types.QueryBuilder[T] {
 	var selectCols []string
+	var args []interface{}
 
 	for _, field := range fields {
 		switch v := field.(type) {
@@ -25,6 +26,15 @@ func (qb *QueryBuilder[T]) withFields(distinct bool, fields ...interface{}) type
 			// Simple column
 			selectCols = append(selectCols, utils.Quote(v))
 
+		case types.Column:
+			// Generated column reference
+			selectCols = append(selectCols, utils.Quote(string(v)))
+
+		case types.RawValue:
+			// SQL expression, e.g. SQL{}.JSONText("settings->theme"), its args are bound in order
+			selectCols = append(selectCols, v.Value)
+			args = append(args, v.Args...)
+
 		case []string:
 			if len(v) == 2 {
 				alias := v[1]
@@ -35,6 +45,13 @@ func (qb *QueryBuilder[T]) withFields(distinct bool, fields ...interface{}) type
 		case []interface{}:
 			if len(v) == 2 {
 				switch sub := v[0].(type) {
+				case types.RawValue:
+					alias, ok := v[1].(string)
+					if ok {
+						qb.knownAliases[alias] = true
+						selectCols = append(selectCols, fmt.Sprintf("%s AS %s", sub.Value, utils.Quote(alias)))
+						args = append(args, sub.Args...)
+					}
 				case *QueryBuilder[T]: // Or a generic interface if needed
 					rawSQL := fmt.Sprintf("(%s)", sub.ToRawSQL())
 					alias, ok := v[1].(string)
@@ -51,9 +68,9 @@ func (qb *QueryBuilder[T]) withFields(distinct bool, fields ...interface{}) type
 
 	if len(selectCols) > 0 {
 		if distinct {
-			qb.DB = qb.DB.Distinct(strings.Join(selectCols, ","))
+			qb.DB = qb.DB.Distinct(append([]interface{}{strings.Join(selectCols, ",")}, args...)...)
 		} else {
-			qb.DB = qb.DB.Select(strings.Join(selectCols, ", "))
+			qb.DB = qb.DB.Select(strings.Join(selectCols, ", "), args...)
 		}
 	}
 
@@ -62,7 +79,8 @@ func (qb *QueryBuilder[T]) withFields(distinct bool, fields ...interface{}) type
 
 // Select specifies which fields to retrieve from the database
 // Parameters:
-//   - fields: Can be field names as strings, or arrays for aliased fields
+//   - fields: Can be field names as strings, raw values, or arrays for aliased fields
+//     e.g. Select("id", []interface{}{SQL{}.JSONText("custom_fields->estimate"), "estimate"})
 //
 // Returns: The query builder for method chaining
 func (qb *QueryBuilder[T]) Select(fields ...interface{}) types.QueryBuilder[T] {
@@ -86,9 +104,9 @@ func (qb *QueryBuilder[T]) Distinct(fields ...interface{}) types.QueryBuilder[T]
 //   - isOr: Whether to use OR instead of AND for this condition
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) addCondition(field string, value interface{}, operator Operator, isOr bool) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) addCondition(field types.Column, value interface{}, operator Operator, isOr bool) types.QueryBuilder[T] {
 	// Quote field name to prevent SQL injection and handle reserved keywords
-	quotedField := utils.Quote(field)
+	quotedField := utils.Quote(string(field))
 	var clause string
 	var args []interface{}
 
@@ -160,7 +178,7 @@ func (qb *QueryBuilder[T]) addCondition(field string, value interface{}, operato
 //   - op: The operator to use for comparison
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) addWhere(field string, value interface{}, op Operator) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) addWhere(field types.Column, value interface{}, op Operator) types.QueryBuilder[T] {
 	return qb.addCondition(field, value, op, false)
 }
 
@@ -170,7 +188,7 @@ func (qb *QueryBuilder[T]) addWhere(field string, value interface{}, op Operator
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) Where(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) Where(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addWhere(field, value, EQ)
 }
 
@@ -179,7 +197,7 @@ func (qb *QueryBuilder[T]) Where(field string, value interface{}) types.QueryBui
 //   - field: The database field name to check for NULL
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WhereNull(field string) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WhereNull(field types.Column) types.QueryBuilder[T] {
 	return qb.addWhere(field, nil, NULL)
 }
 
@@ -188,7 +206,7 @@ func (qb *QueryBuilder[T]) WhereNull(field string) types.QueryBuilder[T] {
 //   - field: The database field name to check for NOT NULL
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WhereNotNull(field string) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WhereNotNull(field types.Column) types.QueryBuilder[T] {
 	return qb.addWhere(field, nil, NOTNULL)
 }
 
@@ -198,7 +216,7 @@ func (qb *QueryBuilder[T]) WhereNotNull(field string) types.QueryBuilder[T] {
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WhereNot(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WhereNot(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addWhere(field, value, NEQ)
 }
 
@@ -208,7 +226,7 @@ func (qb *QueryBuilder[T]) WhereNot(field string, value interface{}) types.Query
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WhereLT(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WhereLT(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addWhere(field, value, LT)
 }
 
@@ -218,7 +236,7 @@ func (qb *QueryBuilder[T]) WhereLT(field string, value interface{}) types.QueryB
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WhereLTE(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WhereLTE(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addWhere(field, value, LTE)
 }
 
@@ -228,7 +246,7 @@ func (qb *QueryBuilder[T]) WhereLTE(field string, value interface{}) types.Query
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WhereGT(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WhereGT(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addWhere(field, value, GT)
 }
 
@@ -238,7 +256,7 @@ func (qb *QueryBuilder[T]) WhereGT(field string, value interface{}) types.QueryB
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WhereGTE(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WhereGTE(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addWhere(field, value, GTE)
 }
 
@@ -248,7 +266,7 @@ func (qb *QueryBuilder[T]) WhereGTE(field string, value interface{}) types.Query
 //   - values: A slice of values to check against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WhereIN(field string, values interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WhereIN(field types.Column, values interface{}) types.QueryBuilder[T] {
 	return qb.addWhere(field, values, IN)
 }
 
@@ -258,7 +276,7 @@ func (qb *QueryBuilder[T]) WhereIN(field string, values interface{}) types.Query
 //   - values: A slice of values to check against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WhereNotIN(field string, values interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WhereNotIN(field types.Column, values interface{}) types.QueryBuilder[T] {
 	return qb.addWhere(field, values, NIN)
 }
 
@@ -269,7 +287,7 @@ func (qb *QueryBuilder[T]) WhereNotIN(field string, values interface{}) types.Qu
 //   - max: The maximum value for that field
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WhereBetween(field string, min, max interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WhereBetween(field types.Column, min, max interface{}) types.QueryBuilder[T] {
 	values := []interface{}{min, max}
 	return qb.addWhere(field, values, BETWEEN)
 }
@@ -288,7 +306,7 @@ func (qb *QueryBuilder[T]) WhereRaw(sql string, args ...interface{}) types.Query
 //   - op: The operator to use for comparison
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) addOrWhere(field string, value interface{}, op Operator) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) addOrWhere(field types.Column, value interface{}, op Operator) types.QueryBuilder[T] {
 	return qb.addCondition(field, value, op, true)
 }
 
@@ -298,7 +316,7 @@ func (qb *QueryBuilder[T]) addOrWhere(field string, value interface{}, op Operat
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) OrWhere(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) OrWhere(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addOrWhere(field, value, EQ)
 }
 
@@ -307,7 +325,7 @@ func (qb *QueryBuilder[T]) OrWhere(field string, value interface{}) types.QueryB
 //   - field: The database field name to check for NULL
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) OrWhereNull(field string) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) OrWhereNull(field types.Column) types.QueryBuilder[T] {
 	return qb.addOrWhere(field, nil, NULL)
 }
 
@@ -316,7 +334,7 @@ func (qb *QueryBuilder[T]) OrWhereNull(field string) types.QueryBuilder[T] {
 //   - field: The database field name to check for NOT NULL
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) OrWhereNotNull(field string) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) OrWhereNotNull(field types.Column) types.QueryBuilder[T] {
 	return qb.addOrWhere(field, nil, NOTNULL)
 }
 
@@ -326,7 +344,7 @@ func (qb *QueryBuilder[T]) OrWhereNotNull(field string) types.QueryBuilder[T] {
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) OrWhereNot(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) OrWhereNot(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addOrWhere(field, value, NEQ)
 }
 
@@ -336,7 +354,7 @@ func (qb *QueryBuilder[T]) OrWhereNot(field string, value interface{}) types.Que
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) OrWhereGTE(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) OrWhereGTE(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addOrWhere(field, value, GTE)
 }
 
@@ -346,7 +364,7 @@ func (qb *QueryBuilder[T]) OrWhereGTE(field string, value interface{}) types.Que
 //   - value: The value to compare against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) OrWhereLTE(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) OrWhereLTE(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addOrWhere(field, value, LTE)
 }
 
@@ -356,7 +374,7 @@ func (qb *QueryBuilder[T]) OrWhereLTE(field string, value interface{}) types.Que
 //   - value: A slice of values to check against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) OrWhereIN(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) OrWhereIN(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addOrWhere(field, value, IN)
 }
 
@@ -366,7 +384,7 @@ func (qb *QueryBuilder[T]) OrWhereIN(field string, value interface{}) types.Quer
 //   - value: A slice of values to check against
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) OrWhereNotIN(field string, value interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) OrWhereNotIN(field types.Column, value interface{}) types.QueryBuilder[T] {
 	return qb.addOrWhere(field, value, NIN)
 }
 
@@ -377,7 +395,7 @@ func (qb *QueryBuilder[T]) OrWhereNotIN(field string, value interface{}) types.Q
 //   - max: The maximum value for that field
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) OrWhereBetween(field string, min, max interface{}) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) OrWhereBetween(field types.Column, min, max interface{}) types.QueryBuilder[T] {
 	values := []interface{}{min, max}
 	return qb.addOrWhere(field, values, BETWEEN)
 }
@@ -0,0 +1,106 @@
+package builder
+
+import (
+	"gorm.io/gorm"
+	"konsultn-api/internal/shared/crud/pagination"
+	"konsultn-api/internal/shared/crud/types"
+)
+
+// WithEstimatedCount makes pagination estimate the total count instead of counting every row
+// Unfiltered queries read the planner statistics of the table, anything else is counted up to
+// pagination.EstimatedCountCap rows. The result is flagged with Estimated when the count is not exact
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) WithEstimatedCount() types.QueryBuilder[T] {
+	qb.countMode = pagination.CountEstimated
+	return qb
+}
+
+// needsWrappedCount reports whether a plain COUNT would miscount the query
+// GROUP BY (and HAVING, which GORM stores with it) returns one count per group,
+// and DISTINCT is dropped by GORM's Count unless it selects a single column
+func needsWrappedCount(db *gorm.DB) bool {
+	if _, ok := db.Statement.Clauses["GROUP BY"]; ok {
+		return true
+	}
+	return db.Statement.Distinct
+}
+
+// countExact counts the rows returned by the query
+// Grouped and distinct queries are counted as a subquery, so groups rather than rows are counted
+func countExact(db *gorm.DB) (int64, error) {
+	var count int64
+
+	if !needsWrappedCount(db) {
+		err := db.Count(&count).Error
+		return count, err
+	}
+
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table("(?) AS counted", db).
+		Count(&count).Error
+	return count, err
+}
+
+// countCapped counts the rows returned by the query, stopping at limit
+// Returns the count and whether the cap was reached
+func countCapped(db *gorm.DB, limit int) (int64, bool, error) {
+	var count int64
+
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table("(?) AS counted", db.Session(&gorm.Session{}).Limit(limit)).
+		Count(&count).Error
+	return count, count >= int64(limit), err
+}
+
+// countFromStats reads the planner's row estimate of a table from pg_class
+// It reports false when the database is not PostgreSQL or the table was never analyzed
+func countFromStats(db *gorm.DB, table string) (int64, bool) {
+	if db.Dialector.Name() != "postgres" {
+		return 0, false
+	}
+
+	var estimate float64
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Raw("SELECT reltuples FROM pg_class WHERE oid = to_regclass(?)", table).
+		Row().Scan(&estimate)
+	if err != nil || estimate < 0 {
+		return 0, false
+	}
+	return int64(estimate), true
+}
+
+// isUnfiltered reports whether the query reads the whole base table,
+// in which case table statistics are a reasonable estimate of its size
+func (qb *QueryBuilder[T]) isUnfiltered(db *gorm.DB) bool {
+	stmt := db.Statement
+	if len(qb.joins) > 0 || stmt.TableExpr != nil || stmt.Distinct {
+		return false
+	}
+	for _, name := range []string{"WHERE", "GROUP BY", "LIMIT"} {
+		if _, ok := stmt.Clauses[name]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// countTotal computes the total count used by pagination according to the count mode
+// Returns the count and whether it is an estimate
+func (qb *QueryBuilder[T]) countTotal() (int64, bool, error) {
+	db := qb.buildBase()
+
+	if qb.countMode != pagination.CountEstimated {
+		count, err := countExact(db)
+		return count, false, err
+	}
+
+	// Soft deleted rows are included in the statistics, which is acceptable for an estimate
+	// Small tables are cheap to count, so their statistics are only used above the cap
+	if qb.isUnfiltered(db) {
+		if count, ok := countFromStats(db, qb.baseTable); ok && count >= pagination.EstimatedCountCap {
+			return count, true, nil
+		}
+	}
+
+	return countCapped(db, pagination.EstimatedCountCap)
+}
@@ -0,0 +1,43 @@
+package builder_test
+
+import (
+	teamModel "konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/shared/crud/builder"
+	"konsultn-api/internal/shared/crud/pagination"
+	"testing"
+)
+
+func TestCountGrouped(t *testing.T) {
+	qb, _ := seedTeams(t)
+	grouped := qb.Join("team_members").On("id", "team_id").
+		Select("teams.*", []string{"COUNT(team_members.user_id)", "member_count"}).
+		GroupBy(teamModel.ColTeamID).
+		WithPageParams(pagination.QueryParams{Limit: 2})
+
+	if count, err := grouped.Count(); err != nil || count != 3 {
+		t.Fatalf("grouped Count = %d, %v", count, err)
+	}
+	if count, err := grouped.HavingGT("COUNT(team_members.user_id)", 1).Count(); err != nil || count != 2 {
+		t.Fatalf("Count with HAVING = %d, %v", count, err)
+	}
+}
+
+func TestCountDistinct(t *testing.T) {
+	qb, _ := seedTeams(t)
+	joined := qb.Join("team_members").On("id", "team_id").(*builder.QueryBuilder[teamModel.Team])
+	count, err := joined.Distinct(teamModel.ColTeamID).Count()
+	if err != nil || count != 3 {
+		t.Fatalf("distinct Count = %d, %v", count, err)
+	}
+}
+
+func TestEstimatedCount(t *testing.T) {
+	qb, _ := seedTeams(t)
+	page, err := qb.WithEstimatedCount().WithPageParams(pagination.QueryParams{Limit: 2}).Paginate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.TotalCount != 3 || len(page.Result) != 2 {
+		t.Fatalf("estimated page = %d rows of %d", len(page.Result), page.TotalCount)
+	}
+}
@@ -3,6 +3,7 @@ package builder
 import (
 	"fmt"
 	"gorm.io/gorm"
+	"konsultn-api/internal/shared/crud/builder/utils"
 	"konsultn-api/internal/shared/crud/pagination"
 	"konsultn-api/internal/shared/crud/types"
 	"strings"
@@ -87,13 +88,49 @@ func (qb *QueryBuilder[T]) Into(dest interface{}) error {
 	return qb.build().Scan(dest).Error
 }
 
+// OrderBy adds an ORDER BY expression to the query itself
+// Unlike WithPageParams it is part of the generated SQL, which makes it usable in subqueries
+// such as the "latest N per group" side of a lateral join
+// Parameters:
+//   - field: The column to order by, unqualified columns resolve against the base table
+//   - direction: "asc" or "desc", anything else falls back to "asc"
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) OrderBy(column types.Column, direction string) types.QueryBuilder[T] {
+	field := string(column)
+	if !strings.Contains(field, ".") && !qb.knownAliases[field] {
+		field = qb.baseTable + "." + field
+	}
+
+	direction = strings.ToUpper(direction)
+	if direction != "DESC" {
+		direction = "ASC"
+	}
+
+	qb.DB = qb.DB.Order(fmt.Sprintf("%s %s", utils.Quote(field), direction))
+	return qb
+}
+
+// Limit restricts the number of rows returned by the query itself
+// Pagination applies its own limit, so this is mostly useful for subqueries
+// Parameters:
+//   - limit: The maximum number of rows
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) Limit(limit int) types.QueryBuilder[T] {
+	qb.DB = qb.DB.Limit(limit)
+	return qb
+}
+
 // WithPageParams configures the query builder with pagination parameters
 // This sets up sorting, ordering, page number, and items per page
 // Parameters:
 //   - params: An object implementing the pagination.QueryParams interface
+//   - sortable: Optional columns the request may sort by, matched by name or qualified reference
+//     When given, other sort fields are ignored and matches sort by the column's own reference
 //
 // Returns: The query builder for method chaining
-func (qb *QueryBuilder[T]) WithPageParams(params pagination.QueryParams) types.QueryBuilder[T] {
+func (qb *QueryBuilder[T]) WithPageParams(params pagination.QueryParams, sortable ...types.Column) types.QueryBuilder[T] {
 	page, limit, sortStr, orderStr := params.PaginationParams()
 
 	qb.page = page
@@ -107,6 +144,14 @@ func (qb *QueryBuilder[T]) WithPageParams(params pagination.QueryParams) types.Q
 	for i := 0; i < len(sortFields); i++ {
 		field := strings.TrimSpace(sortFields[i])
 
+		if len(sortable) > 0 {
+			column, ok := matchSortable(field, sortable)
+			if !ok {
+				continue
+			}
+			field = string(column)
+		}
+
 		// If no dot notation is present, prefix with main table name
 		if !strings.Contains(field, ".") && !qb.knownAliases[field] {
 			tableName := qb.baseTable
@@ -126,17 +171,25 @@ func (qb *QueryBuilder[T]) WithPageParams(params pagination.QueryParams) types.Q
 	return qb
 }
 
-// preparePagination sets up pagination and returns the prepared query and total count
-func (qb *QueryBuilder[T]) preparePagination() (*gorm.DB, int64, error) {
-	var totalCount int64
+// matchSortable finds the sortable column a requested sort field refers to
+func matchSortable(field string, sortable []types.Column) (types.Column, bool) {
+	for _, column := range sortable {
+		if field == string(column) || field == column.Name() {
+			return column, true
+		}
+	}
+	return "", false
+}
 
-	// Build the base query
+// preparePagination sets up pagination and returns the prepared query, the total count
+// and whether the total count is an estimate
+func (qb *QueryBuilder[T]) preparePagination() (*gorm.DB, int64, bool, error) {
+	// Build the base query, the row lock only applies to the page itself
 	db := qb.build()
 
-	// Clone DB query for counting
-	countDB := db.Session(&gorm.Session{})
-	if err := countDB.Count(&totalCount).Order("").Error; err != nil {
-		return nil, 0, err
+	totalCount, estimated, err := qb.countTotal()
+	if err != nil {
+		return nil, 0, false, err
 	}
 
 	db = db.Order(qb.orderClause)
@@ -144,7 +197,7 @@ func (qb *QueryBuilder[T]) preparePagination() (*gorm.DB, int64, error) {
 	offset := (qb.page - 1) * qb.limit
 	db = db.Offset(offset).Limit(qb.limit)
 
-	return db, totalCount, nil
+	return db, totalCount, estimated, nil
 }
 
 // Paginate executes the query with pagination and returns a paginated result
@@ -153,7 +206,7 @@ func (qb *QueryBuilder[T]) preparePagination() (*gorm.DB, int64, error) {
 //   - *pagination.PaginatedResult[T]: Structure containing results, total count, and pagination info
 //   - error: Any error that occurred during query execution
 func (qb *QueryBuilder[T]) Paginate() (*pagination.PaginatedResult[T], error) {
-	db, totalCount, err := qb.preparePagination()
+	db, totalCount, estimated, err := qb.preparePagination()
 	if err != nil {
 		return nil, err
 	}
@@ -165,12 +218,14 @@ func (qb *QueryBuilder[T]) Paginate() (*pagination.PaginatedResult[T], error) {
 	}
 
 	// Create paginated result with the correctly typed slice
-	return pagination.NewPaginatedResult(results, totalCount, qb.page, qb.limit), nil
+	result := pagination.NewPaginatedResult(results, totalCount, qb.page, qb.limit)
+	result.Estimated = estimated
+	return result, nil
 }
 
 // PaginateMap executes the query with pagination and populates a map
 func (qb *QueryBuilder[T]) PaginateMap() (pagination.PaginatedResult[map[string]interface{}], error) {
-	db, totalCount, err := qb.preparePagination()
+	db, totalCount, estimated, err := qb.preparePagination()
 	if err != nil {
 		return pagination.PaginatedResult[map[string]interface{}]{}, err
 	}
@@ -183,5 +238,7 @@ func (qb *QueryBuilder[T]) PaginateMap() (pagination.PaginatedResult[map[string]
 		return pagination.PaginatedResult[map[string]interface{}]{}, err
 	}
 
-	return *pagination.NewPaginatedResult[map[string]interface{}](results, totalCount, qb.page, qb.limit), nil
+	result := pagination.NewPaginatedResult[map[string]interface{}](results, totalCount, qb.page, qb.limit)
+	result.Estimated = estimated
+	return *result, nil
 }
@@ -0,0 +1,28 @@
+package builder_test
+
+import (
+	teamModel "konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/shared/crud/builder"
+	"konsultn-api/internal/shared/crud/pagination"
+	"konsultn-api/internal/testkit"
+	"testing"
+)
+
+func TestWithPageParamsSortable(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	for _, name := range []string{"b", "a", "c"} {
+		f.Team(f.User(), func(team *teamModel.Team) { team.Name = name })
+	}
+
+	page, err := builder.NewQueryBuilder[teamModel.Team](db.Model(&teamModel.Team{})).
+		WithPageParams(pagination.QueryParams{Sort: "name,evil;drop,teams.slug", Order: "desc,asc,asc", Limit: 2, Page: 2},
+			teamModel.ColTeamName, teamModel.ColTeamSlug).
+		Paginate()
+	if err != nil {
+		t.Fatalf("unlisted sort fields must be ignored: %v", err)
+	}
+	if page.TotalCount != 3 || page.TotalPages != 2 || len(page.Result) != 1 || page.Result[0].Name != "a" {
+		t.Fatalf("second page by name desc = %+v", page)
+	}
+}
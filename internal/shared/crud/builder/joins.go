@@ -11,9 +11,10 @@ import (
 // JoinClause represents a SQL JOIN operation with its type, table, and conditions
 // It contains all the information needed to build a complete JOIN clause
 type JoinClause struct {
-	Type      JoinType              // The type of join (INNER, LEFT, RIGHT, CROSS)
+	Type      JoinType              // The type of join (INNER, LEFT, RIGHT, CROSS, LATERAL)
 	Table     string                // The table name or alias to join with
 	Condition *JoinConditionBuilder // The join conditions (ON clause)
+	Subquery  types.Subquery        // The subquery joined by a LATERAL join, nil for tables
 }
 
 // Join performs an INNER JOIN with the specified table
@@ -60,8 +61,57 @@ func (qb *QueryBuilder[T]) CrossJoin(table string, opts ...string) types.QueryBu
 	return qb.addJoin(table, opts, JoinCross)
 }
 
+// JoinLateral performs an INNER JOIN LATERAL against a subquery
+// The subquery may reference columns of the tables joined before it, e.g. to fetch
+// the latest N rows per group. Without On conditions the join uses ON TRUE
+// Parameters:
+//   - subquery: The query builder producing the joined rows
+//   - alias: The alias of the joined subquery
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) JoinLateral(subquery types.Subquery, alias string) types.QueryBuilder[T] {
+	return qb.addLateralJoin(subquery, alias, JoinInnerLateral)
+}
+
+// LeftJoinLateral performs a LEFT JOIN LATERAL against a subquery
+// Rows of the left side are kept even when the subquery returns nothing
+// Parameters:
+//   - subquery: The query builder producing the joined rows
+//   - alias: The alias of the joined subquery
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) LeftJoinLateral(subquery types.Subquery, alias string) types.QueryBuilder[T] {
+	return qb.addLateralJoin(subquery, alias, JoinLeftLateral)
+}
+
+// joinLeftSide returns the table a new join is relative to: the previously joined alias,
+// or the base table for the first join
+func (qb *QueryBuilder[T]) joinLeftSide() string {
+	if qb.lastJoinedTable != "" {
+		return qb.lastJoinedTable
+	}
+	return qb.baseTable
+}
+
+// addLateralJoin adds a LATERAL join against a subquery to the internal list
+func (qb *QueryBuilder[T]) addLateralJoin(subquery types.Subquery, alias string, joinType JoinType) types.QueryBuilder[T] {
+	left := qb.joinLeftSide()
+	qb.lastJoinedTable = alias
+	qb.knownAliases[alias] = true
+
+	qb.joins = append(qb.joins, JoinClause{
+		Type:      joinType,
+		Table:     "? AS " + utils.Quote(alias),
+		Condition: NewJoinConditionBuilder(left, alias),
+		Subquery:  subquery,
+	})
+
+	return qb
+}
+
 // addJoin is a helper method that implements the common functionality for all join types
 // It handles table name quoting, aliasing, and adds the join to the internal list
+// The left side of the join defaults to the previously joined table, see From to change it
 // Parameters:
 //   - table: The table name to join
 //   - opts: Optional parameters for aliasing the table
@@ -77,17 +127,35 @@ func (qb *QueryBuilder[T]) addJoin(table string, opts []string, joinType JoinTyp
 		table = utils.Quote(table)
 	}
 
+	left := qb.joinLeftSide()
 	qb.lastJoinedTable = alias // Track only alias (or original name if no alias)
 
 	qb.joins = append(qb.joins, JoinClause{
 		Type:      joinType,
 		Table:     table,
-		Condition: NewJoinConditionBuilder(qb.baseTable, alias),
+		Condition: NewJoinConditionBuilder(left, alias),
 	})
 
 	return qb
 }
 
+// From sets the left side of the last join to the given table or alias
+// By default a join is relative to the previously joined table, or to the base table for the first join
+// Parameters:
+//   - alias: The table name or alias that unqualified left-hand columns resolve against
+//
+// Returns: The query builder for method chaining
+// Panics: If called without a preceding join operation
+func (qb *QueryBuilder[T]) From(alias string) types.QueryBuilder[T] {
+	if len(qb.joins) == 0 {
+		panic("No join to apply condition to")
+	}
+
+	lastJoin := &qb.joins[len(qb.joins)-1]
+	lastJoin.Condition.Base = alias
+	return qb
+}
+
 // On specifies the join condition between tables using an equality comparison
 // This method should be called immediately after a join method
 // Parameters:
@@ -125,20 +193,28 @@ func (qb *QueryBuilder[T]) OnGroup(builder func(j types.JoinBuilder)) types.Quer
 
 // buildJoins constructs the SQL for all joins and applies them to the query
 // This is an internal method used when finalizing the query before execution
+// Parameters:
+//   - db: The session to apply the joins to
+//
 // Returns: The modified gorm.DB instance with all join clauses applied
-func (qb *QueryBuilder[T]) buildJoins() *gorm.DB {
-	db := qb.DB
-
+func (qb *QueryBuilder[T]) buildJoins(db *gorm.DB) *gorm.DB {
 	for _, join := range qb.joins {
 		joinSQL := fmt.Sprintf("%s %s", strings.ToUpper(join.Type.String()), join.Table)
 
 		if conditionSQL := join.Condition.String(); conditionSQL != "" {
 			joinSQL += " ON " + conditionSQL
+		} else if join.Subquery != nil {
+			joinSQL += " ON TRUE"
 		}
 
 		// Get parameters for this join condition
 		params := join.Condition.GetParams()
 
+		// The subquery is wrapped in an expression, GORM treats a lone *gorm.DB argument as join conditions
+		if join.Subquery != nil {
+			params = append([]interface{}{gorm.Expr("(?)", join.Subquery.G(true))}, params...)
+		}
+
 		if len(params) > 0 {
 			// If we have parameters, use them with the join
 			db = db.Joins(joinSQL, params...)
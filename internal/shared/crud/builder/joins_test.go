@@ -0,0 +1,73 @@
+package builder_test
+
+import (
+	projectModel "konsultn-api/internal/domain/project/model"
+	"konsultn-api/internal/domain/task"
+	teamModel "konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/domain/user"
+	"konsultn-api/internal/shared/crud/builder"
+	"konsultn-api/internal/shared/crud/types"
+	"strings"
+	"testing"
+)
+
+// query returns a builder on the table of T, like Repository.Query
+func query[T any](t *testing.T) types.QueryBuilder[T] {
+	return builder.NewQueryBuilder[T](dryRun(t).Model(new(T)))
+}
+
+func TestJoinRelation(t *testing.T) {
+	sql := query[teamModel.Team](t).JoinRelation("Members").Where(teamModel.ColTeamMemberRole, "admin").ToRawSQL()
+
+	assertSQL(t, sql,
+		`JOIN "team_members" ON "team_members"."team_id" = "teams"."id" AND "team_members"."deleted_at" IS NULL`,
+		`"team_members"."role" = 'admin'`,
+	)
+}
+
+func TestJoinRelationNested(t *testing.T) {
+	qb := query[projectModel.Project](t).
+		JoinRelation("Tasks").
+		LeftJoinRelation("Tasks.Assignee").
+		LeftJoinRelation("Tasks.ParentTask.Assignee")
+
+	assertSQL(t, qb.ToRawSQL(),
+		`JOIN "tasks" ON "tasks"."project_id" = "projects"."id"`,
+		`LEFT JOIN "users" ON "users"."id" = "tasks"."assignee_id"`,
+		`LEFT JOIN "tasks" AS "tasks_parent_task" ON "tasks_parent_task"."id" = "tasks"."parent_task_id"`,
+		`LEFT JOIN "users" AS "tasks_parent_task_assignee" ON "tasks_parent_task_assignee"."id" = "tasks_parent_task"."assignee_id"`,
+	)
+	if alias := qb.RelationAlias("Tasks.ParentTask.Assignee"); alias != "tasks_parent_task_assignee" {
+		t.Fatalf("RelationAlias = %q", alias)
+	}
+}
+
+func TestJoinRelationUnknown(t *testing.T) {
+	err := query[task.Task](t).JoinRelation("Nope").G(true).Error
+	if err == nil || !strings.Contains(err.Error(), "no relationship 'Nope'") {
+		t.Fatalf("expected an unknown relationship error, got %v", err)
+	}
+}
+
+func TestLateralJoins(t *testing.T) {
+	latest := query[task.Task](t).WhereRaw(`"tasks"."assignee_id" = "users"."id"`).OrderBy("created_at", "desc").Limit(3)
+	sql := query[user.User](t).Select("users.id", "latest.title").LeftJoinLateral(latest, "latest").ToRawSQL()
+	assertSQL(t, sql,
+		`LEFT JOIN LATERAL (SELECT * FROM "tasks" WHERE "tasks"."assignee_id" = "users"."id" AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."created_at" DESC LIMIT 3) AS "latest" ON TRUE`,
+	)
+
+	sql = query[user.User](t).JoinLateral(query[task.Task](t).Limit(1), "lt").On("id", "assignee_id").ToRawSQL()
+	assertSQL(t, sql, `JOIN LATERAL (`, `) AS "lt" ON "users"."id" = "lt"."assignee_id"`)
+}
+
+func TestChainedJoins(t *testing.T) {
+	sql := query[teamModel.Team](t).
+		Join("team_members").On("id", "team_id").
+		Join("users", "u").On("user_id", "id").
+		Join("team_members", "tm2").From("teams").On("id", "team_id").
+		ToRawSQL()
+
+	assertSQL(t, sql, `JOIN "team_members" ON "teams"."id" = "team_members"."team_id" `+
+		`JOIN "users" AS "u" ON "team_members"."user_id" = "u"."id" `+
+		`JOIN "team_members" AS "tm2" ON "teams"."id" = "tm2"."team_id"`)
+}
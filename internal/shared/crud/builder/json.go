@@ -0,0 +1,145 @@
+package builder
+
+import (
+	"fmt"
+	"konsultn-api/internal/shared/crud/builder/utils"
+	"konsultn-api/internal/shared/crud/types"
+	"strings"
+)
+
+// JSONPathSeparator separates the column from the keys in a JSON path
+// e.g. "settings->notifications->email" or "tasks.custom_fields->estimate"
+const JSONPathSeparator = "->"
+
+// splitJSONPath splits a JSON path into its column and the keys below it
+func splitJSONPath(path string) (string, []string, error) {
+	parts := strings.Split(path, JSONPathSeparator)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+		if parts[i] == "" {
+			return "", nil, fmt.Errorf("invalid json path '%s'", path)
+		}
+	}
+
+	if len(parts) < 2 {
+		return "", nil, fmt.Errorf("json path '%s' must have the form column%skey", path, JSONPathSeparator)
+	}
+
+	return parts[0], parts[1:], nil
+}
+
+// isJSONComparison reports whether op can be used to compare two jsonb values
+func isJSONComparison(op Operator) bool {
+	switch op {
+	case EQ, NEQ, LT, LTE, GT, GTE:
+		return true
+	}
+	return false
+}
+
+// WhereJSONContains adds a condition checking if a JSONB column contains the given value (field @> value)
+// Parameters:
+//   - field: The JSONB column name
+//   - value: A Go value or raw JSON (datatypes.JSON / json.RawMessage) that must be contained
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) WhereJSONContains(field types.Column, value interface{}) types.QueryBuilder[T] {
+	doc, err := types.ToJSONB(value)
+	if err != nil {
+		_ = qb.DB.AddError(err)
+		return qb
+	}
+
+	qb.DB = qb.DB.Where(fmt.Sprintf("%s @> ?::jsonb", utils.Quote(string(field))), doc)
+	return qb
+}
+
+// WhereJSONPath adds a condition comparing the value found at a JSON path
+// The comparison is done between jsonb values, so numbers, strings and booleans keep their type
+// Parameters:
+//   - path: The column followed by its keys, e.g. "custom_fields->estimate->hours"
+//   - op: The comparison operator (=, !=, <, <=, >, >=)
+//   - value: The value to compare against
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) WhereJSONPath(path types.Column, op string, value interface{}) types.QueryBuilder[T] {
+	column, keys, err := splitJSONPath(string(path))
+	if err != nil {
+		_ = qb.DB.AddError(err)
+		return qb
+	}
+
+	operator := Operator(op)
+	if !isJSONComparison(operator) {
+		_ = qb.DB.AddError(fmt.Errorf("unsupported json path operator '%s'", op))
+		return qb
+	}
+
+	doc, err := types.ToJSONB(value)
+	if err != nil {
+		_ = qb.DB.AddError(err)
+		return qb
+	}
+
+	clause := fmt.Sprintf("%s #> ?::text[] %s ?::jsonb", utils.Quote(column), string(operator))
+	qb.DB = qb.DB.Where(clause, types.TextArray(keys), doc)
+	return qb
+}
+
+// WhereJSONHasKey adds a condition checking if a JSONB column has the given top-level key
+// A nested key can be checked with a path, e.g. WhereJSONHasKey("settings->notifications", "email")
+// Parameters:
+//   - field: The JSONB column name or JSON path
+//   - key: The key that must exist
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) WhereJSONHasKey(field types.Column, key string) types.QueryBuilder[T] {
+	target := utils.Quote(string(field))
+	var args []interface{}
+
+	if strings.Contains(string(field), JSONPathSeparator) {
+		column, keys, err := splitJSONPath(string(field))
+		if err != nil {
+			_ = qb.DB.AddError(err)
+			return qb
+		}
+		target = fmt.Sprintf("(%s #> ?::text[])", utils.Quote(column))
+		args = append(args, types.TextArray(keys))
+	}
+
+	// jsonb_exists is the function behind the "?" operator, which would clash with placeholders
+	qb.DB = qb.DB.Where(fmt.Sprintf("jsonb_exists(%s, ?)", target), append(args, key)...)
+	return qb
+}
+
+// WhereJSONArrayLength adds a condition on the length of a JSONB array
+// Parameters:
+//   - field: The JSONB column name or JSON path pointing to an array
+//   - op: The comparison operator (=, !=, <, <=, >, >=)
+//   - length: The length to compare against
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) WhereJSONArrayLength(field types.Column, op string, length int) types.QueryBuilder[T] {
+	operator := Operator(op)
+	if !isJSONComparison(operator) {
+		_ = qb.DB.AddError(fmt.Errorf("unsupported json array length operator '%s'", op))
+		return qb
+	}
+
+	target := utils.Quote(string(field))
+	var args []interface{}
+
+	if strings.Contains(string(field), JSONPathSeparator) {
+		column, keys, err := splitJSONPath(string(field))
+		if err != nil {
+			_ = qb.DB.AddError(err)
+			return qb
+		}
+		target = fmt.Sprintf("%s #> ?::text[]", utils.Quote(column))
+		args = append(args, types.TextArray(keys))
+	}
+
+	clause := fmt.Sprintf("jsonb_array_length(%s) %s ?", target, string(operator))
+	qb.DB = qb.DB.Where(clause, append(args, length)...)
+	return qb
+}
@@ -0,0 +1,37 @@
+package builder_test
+
+import (
+	"konsultn-api/internal/shared/crud/builder"
+	"testing"
+)
+
+func TestWhereJSON(t *testing.T) {
+	sql := builder.NewQueryBuilder[widget](dryRun(t)).
+		WhereJSONContains("custom_fields", map[string]any{"a": 1}).
+		WhereJSONPath("widgets.custom_fields->estimate->hours", ">", 3).
+		WhereJSONHasKey("custom_fields->settings", "theme").
+		WhereJSONArrayLength("custom_fields->tags", ">=", 2).
+		ToRawSQL()
+
+	assertSQL(t, sql,
+		`"custom_fields" @> '{"a":1}'::jsonb`,
+		`"widgets"."custom_fields" #> '{"estimate","hours"}'::text[] > '3'::jsonb`,
+		`jsonb_exists(("custom_fields" #> '{"settings"}'::text[]), 'theme')`,
+		`jsonb_array_length("custom_fields" #> '{"tags"}'::text[]) >= 2`,
+	)
+}
+
+func TestWhereJSONInvalidPath(t *testing.T) {
+	qb := builder.NewQueryBuilder[widget](dryRun(t)).WhereJSONPath("custom_fields", "=", 1)
+	if qb.G(true).Error == nil {
+		t.Fatal("expected an error for a path without keys")
+	}
+}
+
+func TestSelectRawValue(t *testing.T) {
+	sql := builder.NewQueryBuilder[widget](dryRun(t)).
+		Select("id", []interface{}{builder.SQL{}.JSONText("custom_fields->estimate"), "estimate"}, builder.SQL{}.Lower("name")).
+		ToRawSQL()
+
+	assertSQL(t, sql, `SELECT "id", "custom_fields" #>> '{"estimate"}'::text[] AS "estimate", LOWER("name") FROM "widgets"`)
+}
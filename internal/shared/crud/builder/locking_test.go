@@ -0,0 +1,44 @@
+package builder_test
+
+import (
+	"errors"
+	teamModel "konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/shared/crud/builder"
+	crudErrors "konsultn-api/internal/shared/crud/errors"
+	"konsultn-api/internal/testkit"
+	"testing"
+)
+
+func TestLockOutsideTransaction(t *testing.T) {
+	db := testkit.Open(t)
+	_, err := builder.NewQueryBuilder[teamModel.Team](db.Model(&teamModel.Team{})).ForUpdate().First()
+	if !errors.Is(err, crudErrors.ErrLockOutsideTransaction) {
+		t.Fatalf("expected ErrLockOutsideTransaction, got %v", err)
+	}
+}
+
+func TestLockWithoutStrength(t *testing.T) {
+	db := testkit.DB(t)
+	_, err := builder.NewQueryBuilder[teamModel.Team](db.Model(&teamModel.Team{})).SkipLocked().First()
+	if !errors.Is(err, crudErrors.ErrLockWithoutStrength) {
+		t.Fatalf("expected ErrLockWithoutStrength, got %v", err)
+	}
+}
+
+func TestLockInTransaction(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	team := f.Team(f.User())
+
+	qb := builder.NewQueryBuilder[teamModel.Team](db.Model(&teamModel.Team{})).
+		Join("team_members").On("id", "team_id").
+		Where(teamModel.ColTeamName, team.Name).
+		ForUpdate().
+		SkipLocked()
+	if testkit.Postgres() {
+		assertSQL(t, qb.ToRawSQL(), `FOR UPDATE OF "teams" SKIP LOCKED`)
+	}
+	if count, err := qb.Count(); err != nil || count != 1 {
+		t.Fatalf("locked Count = %d, %v", count, err)
+	}
+}
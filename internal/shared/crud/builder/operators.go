@@ -23,6 +23,9 @@ const (
 	JoinLeft  JoinType = "LEFT JOIN"
 	JoinRight JoinType = "RIGHT JOIN"
 	JoinCross JoinType = "CROSS JOIN"
+
+	JoinInnerLateral JoinType = "JOIN LATERAL"
+	JoinLeftLateral  JoinType = "LEFT JOIN LATERAL"
 )
 
 func (jt JoinType) String() string {
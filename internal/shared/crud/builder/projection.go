@@ -0,0 +1,300 @@
+package builder
+
+import (
+	"database/sql"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"konsultn-api/internal/shared/crud/builder/utils"
+	crudErrors "konsultn-api/internal/shared/crud/errors"
+	"konsultn-api/internal/shared/crud/pagination"
+	"konsultn-api/internal/shared/crud/types"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// viewField maps a result column to a (possibly embedded) field of a view struct
+type viewField struct {
+	Column string
+	Index  []int
+	// Nullable fields are scanned through a pointer, so a NULL column leaves them at their zero value
+	Nullable bool
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// Projection scans query results straight into the view struct V
+// instead of decoding rows through intermediate maps
+type Projection[T any, V any] struct {
+	qb     *QueryBuilder[T]
+	fields []viewField
+	err    error
+}
+
+// NewProjection creates a projection of the given query builder onto the view struct V
+// Parameters:
+//   - qb: The query builder that provides the filters, joins and selected columns
+//
+// Returns: A projection that scans rows into V
+func NewProjection[T any, V any](qb types.QueryBuilder[T]) types.Projection[V] {
+	p := &Projection[T, V]{}
+
+	concrete, ok := qb.(*QueryBuilder[T])
+	if !ok {
+		p.err = fmt.Errorf("projection requires a *builder.QueryBuilder, got %T", qb)
+		return p
+	}
+	p.qb = concrete
+	p.fields = viewFields(reflect.TypeOf((*V)(nil)).Elem(), concrete.DB.NamingStrategy)
+
+	if len(p.fields) == 0 {
+		p.err = fmt.Errorf("%w: %s has no mappable fields", crudErrors.ErrViewMismatch, viewName[V]())
+	}
+
+	return p
+}
+
+// viewFields resolves the result column of every field in a view struct
+// The column is taken from the `db` tag, then the `mapstructure` tag, then the
+// gorm `column` setting, and finally from the naming strategy
+// Fields tagged with "-" in any of these tags are skipped
+func viewFields(t reflect.Type, namer schema.Namer) []viewField {
+	var fields []viewField
+	collectViewFields(t, nil, namer, &fields)
+	return fields
+}
+
+func collectViewFields(t reflect.Type, parent []int, namer schema.Namer, fields *[]viewField) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		dbTag := tagName(f.Tag.Get("db"))
+		msTag := tagName(f.Tag.Get("mapstructure"))
+		gormTag := schema.ParseTagSetting(f.Tag.Get("gorm"), ";")
+
+		if dbTag == "-" || msTag == "-" || f.Tag.Get("gorm") == "-" {
+			continue
+		}
+
+		index := append(append([]int{}, parent...), i)
+
+		// Embedded structs such as shared.ULID contribute their own columns
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && dbTag == "" && msTag == "" {
+			collectViewFields(f.Type, index, namer, fields)
+			continue
+		}
+
+		column := dbTag
+		if column == "" {
+			column = msTag
+		}
+		if column == "" {
+			column = gormTag["COLUMN"]
+		}
+		if column == "" && namer != nil {
+			column = namer.ColumnName("", f.Name)
+		}
+		if column == "" {
+			continue
+		}
+
+		// Pointers and scanners such as sql.NullString handle NULL themselves
+		nullable := f.Type.Kind() != reflect.Ptr && !reflect.PointerTo(f.Type).Implements(scannerType)
+		*fields = append(*fields, viewField{Column: column, Index: index, Nullable: nullable})
+	}
+}
+
+// tagName returns the name part of a struct tag, dropping options like ",omitempty"
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	return strings.TrimSpace(name)
+}
+
+func viewName[V any]() string {
+	return reflect.TypeOf((*V)(nil)).Elem().String()
+}
+
+// Columns returns the result columns expected by the view struct
+func (p *Projection[T, V]) Columns() []string {
+	columns := make([]string, 0, len(p.fields))
+	for _, f := range p.fields {
+		columns = append(columns, f.Column)
+	}
+	return columns
+}
+
+// prepare derives the SELECT list from the view struct when the query has none
+// Only columns of the base table are derived, joined or aggregated columns
+// always need an explicit Select with an alias and are reported as missing otherwise
+// Queries over a derived table, such as set operations, keep selecting all of its columns
+func (p *Projection[T, V]) prepare() {
+	stmt := p.qb.DB.Statement
+	if _, ok := stmt.Clauses["SELECT"]; ok || len(stmt.Selects) > 0 || stmt.TableExpr != nil {
+		return
+	}
+
+	base := &gorm.Statement{DB: p.qb.DB}
+	if err := base.Parse(p.qb.model); err != nil {
+		return
+	}
+
+	var selectCols []string
+	for _, f := range p.fields {
+		if base.Schema.LookUpField(f.Column) != nil {
+			selectCols = append(selectCols, utils.Quote(p.qb.baseTable+"."+f.Column))
+		}
+	}
+	if len(selectCols) > 0 {
+		p.qb.DB = p.qb.DB.Select(strings.Join(selectCols, ", "))
+	}
+}
+
+// scan executes the query and scans every row into V
+// It fails with ErrViewMismatch when a view field has no matching result column
+func (p *Projection[T, V]) scan(db *gorm.DB) ([]V, error) {
+	rows, err := db.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	byColumn := make(map[string]viewField, len(p.fields))
+	for _, f := range p.fields {
+		byColumn[f.Column] = f
+	}
+
+	// Map each result column to its field, the first occurrence of a duplicate column wins
+	targets := make([]*viewField, len(columns))
+	matched := make(map[string]bool, len(columns))
+	for i, column := range columns {
+		if f, ok := byColumn[column]; ok && !matched[column] {
+			targets[i] = &f
+			matched[column] = true
+		}
+	}
+
+	var missing []string
+	for _, f := range p.fields {
+		if !matched[f.Column] {
+			missing = append(missing, f.Column)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: %s expects columns %v that are not selected (selected: %v)",
+			crudErrors.ErrViewMismatch, viewName[V](), missing, columns)
+	}
+
+	results := make([]V, 0)
+	for rows.Next() {
+		var view V
+		rv := reflect.ValueOf(&view).Elem()
+
+		dest := make([]interface{}, len(columns))
+		for i, target := range targets {
+			if target == nil {
+				dest[i] = new(interface{})
+				continue
+			}
+			field := rv.FieldByIndex(target.Index)
+			if target.Nullable {
+				dest[i] = reflect.New(field.Addr().Type()).Interface()
+				continue
+			}
+			dest[i] = field.Addr().Interface()
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row into %s: %w", viewName[V](), err)
+		}
+		for i, target := range targets {
+			if target == nil || !target.Nullable {
+				continue
+			}
+			if value := reflect.ValueOf(dest[i]).Elem(); !value.IsNil() {
+				rv.FieldByIndex(target.Index).Set(value.Elem())
+			}
+		}
+		results = append(results, view)
+	}
+
+	return results, rows.Err()
+}
+
+// First retrieves the first row that matches the query as a view struct
+// Returns:
+//   - *V: Pointer to the view, or nil if no match
+//   - error: gorm.ErrRecordNotFound if no row matched, or any query/scan error
+func (p *Projection[T, V]) First() (*V, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.prepare()
+
+	results, err := p.scan(p.qb.build().Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &results[0], nil
+}
+
+// All retrieves every row that matches the query as view structs
+// Returns:
+//   - []V: Slice of views
+//   - error: Any error that occurred during the query or scan
+func (p *Projection[T, V]) All() ([]V, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.prepare()
+
+	db := p.qb.build()
+	if p.qb.orderClause != "" {
+		db = db.Order(p.qb.orderClause)
+	}
+	return p.scan(db)
+}
+
+// Paginate executes the query with the configured page params and scans the page into V
+// Returns:
+//   - *pagination.PaginatedResult[V]: The page of views with total count and pagination info
+//   - error: Any error that occurred during query execution
+func (p *Projection[T, V]) Paginate() (*pagination.PaginatedResult[V], error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.prepare()
+
+	db, totalCount, estimated, err := p.qb.preparePagination()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := p.scan(db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := pagination.NewPaginatedResult(results, totalCount, p.qb.page, p.qb.limit)
+	result.Estimated = estimated
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package builder_test
+
+import (
+	"errors"
+	"konsultn-api/internal/domain/team/enum"
+	teamModel "konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/shared/crud/builder"
+	crudErrors "konsultn-api/internal/shared/crud/errors"
+	"konsultn-api/internal/shared/crud/pagination"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/testkit"
+	"strings"
+	"testing"
+)
+
+// seedTeams creates teams with one, two and three members
+func seedTeams(t *testing.T) (types.QueryBuilder[teamModel.Team], []*teamModel.Team) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	var teams []*teamModel.Team
+	for i := 0; i < 3; i++ {
+		team := f.Team(f.User())
+		for j := 0; j < i; j++ {
+			f.Member(team, f.User(), enum.Member)
+		}
+		teams = append(teams, team)
+	}
+	return builder.NewQueryBuilder[teamModel.Team](db.Model(&teamModel.Team{})), teams
+}
+
+func TestProjectionPaginate(t *testing.T) {
+	qb, teams := seedTeams(t)
+	grouped := qb.Join("team_members").On("id", "team_id").
+		Select("teams.*", []string{"COUNT(team_members.user_id)", "member_count"}).
+		GroupBy(teamModel.ColTeamID).
+		WithPageParams(pagination.QueryParams{Limit: 2, Sort: "member_count", Order: "desc"})
+
+	page, err := builder.NewProjection[teamModel.Team, teamModel.TeamSummaryView](grouped).Paginate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.TotalCount != 3 || len(page.Result) != 2 {
+		t.Fatalf("page = %d rows of %d", len(page.Result), page.TotalCount)
+	}
+	if first := page.Result[0]; first.ID != teams[2].ID || first.Name != teams[2].Name || first.MemberCount != 3 {
+		t.Fatalf("first summary = %+v", first)
+	}
+}
+
+func TestProjectionRequiresViewColumns(t *testing.T) {
+	qb, _ := seedTeams(t)
+	_, err := builder.NewProjection[teamModel.Team, teamModel.TeamSummaryView](qb).First()
+	if err == nil || !strings.Contains(err.Error(), "[member_count]") {
+		t.Fatalf("expected the missing member_count column to be reported, got %v", err)
+	}
+}
+
+func TestProjectionScansNullAsZero(t *testing.T) {
+	qb, teams := seedTeams(t)
+
+	type nullView struct {
+		ID          string  `db:"id"`
+		Description string  `db:"description"`
+		MemberCount int     `db:"member_count"`
+		Slug        *string `db:"slug"`
+	}
+	views, err := builder.NewProjection[teamModel.Team, nullView](
+		qb.Where(teamModel.ColTeamID, teams[0].ID).
+			Select("id", []string{"NULL", "description"}, []string{"NULL", "member_count"}, []string{"NULL", "slug"}),
+	).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 1 || views[0].ID != teams[0].ID || views[0].Description != "" || views[0].MemberCount != 0 || views[0].Slug != nil {
+		t.Fatalf("views = %+v", views)
+	}
+}
+
+func TestProjectionReportsMismatchedColumnTypes(t *testing.T) {
+	qb, _ := seedTeams(t)
+
+	type nameView struct {
+		Name int `db:"name"`
+	}
+	_, err := builder.NewProjection[teamModel.Team, nameView](qb).All()
+	if err == nil || !strings.Contains(err.Error(), "failed to scan row into") {
+		t.Fatalf("expected a scan error for a text column in an int field, got %v", err)
+	}
+	if errors.Is(err, crudErrors.ErrViewMismatch) {
+		t.Fatalf("expected a scan error rather than a missing column, got %v", err)
+	}
+}
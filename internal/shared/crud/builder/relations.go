@@ -0,0 +1,321 @@
+package builder
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"konsultn-api/internal/shared/crud/builder/utils"
+	"konsultn-api/internal/shared/crud/types"
+	"reflect"
+	"strings"
+)
+
+// relationQuery is a correlated subquery over the related table of a relationship
+type relationQuery struct {
+	db    *gorm.DB
+	alias string
+}
+
+// schema parses the schema of the model the query builder runs against
+func (qb *QueryBuilder[T]) schema() (*schema.Schema, error) {
+	model := qb.DB.Statement.Model
+	if model == nil {
+		model = qb.model
+	}
+
+	stmt := &gorm.Statement{DB: qb.DB}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("failed to parse schema of %T: %w", model, err)
+	}
+	return stmt.Schema, nil
+}
+
+// relationship looks up a relationship of the model by its field name, e.g. "Members"
+func (qb *QueryBuilder[T]) relationship(name string) (*schema.Relationship, error) {
+	s, err := qb.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	rel, ok := s.Relationships.Relations[name]
+	if !ok {
+		return nil, fmt.Errorf("%s has no relationship '%s'", s.Name, name)
+	}
+	return rel, nil
+}
+
+// relationAlias returns the alias of a related table inside a subquery
+// Self-referencing relationships get a distinct alias so both sides can be told apart
+func relationAlias(rel *schema.Relationship, parent string, namer schema.Namer) string {
+	alias := rel.FieldSchema.Table
+	if alias == parent {
+		alias = parent + "_" + namer.ColumnName("", rel.Name)
+	}
+	return alias
+}
+
+// correlate builds the conditions linking a related table (or the join table of a many2many
+// relationship) to the parent table, based on the references GORM resolved for the relationship
+// Returns the join ON conditions (many2many only) and the WHERE conditions
+func correlate(rel *schema.Relationship, parent, related string) (on []string, where []string) {
+	target := related
+	if rel.JoinTable != nil {
+		target = rel.JoinTable.Table
+	}
+
+	for _, ref := range rel.References {
+		fk := utils.Quote(target + "." + ref.ForeignKey.DBName)
+
+		switch {
+		case ref.PrimaryValue != "":
+			// Polymorphic type column
+			where = append(where, fmt.Sprintf("%s = '%s'", fk, strings.ReplaceAll(ref.PrimaryValue, "'", "''")))
+		case rel.JoinTable != nil && !ref.OwnPrimaryKey:
+			on = append(on, fmt.Sprintf("%s = %s", fk, utils.Quote(related+"."+ref.PrimaryKey.DBName)))
+		case ref.OwnPrimaryKey:
+			// has one, has many and the parent side of many2many
+			where = append(where, fmt.Sprintf("%s = %s", fk, utils.Quote(parent+"."+ref.PrimaryKey.DBName)))
+		default:
+			// belongs to, the foreign key lives on the parent
+			where = append(where, fmt.Sprintf("%s = %s",
+				utils.Quote(related+"."+ref.PrimaryKey.DBName), utils.Quote(parent+"."+ref.ForeignKey.DBName)))
+		}
+	}
+
+	return on, where
+}
+
+// relationQuery builds a subquery over the related rows of each parent row
+// The callback receives a query builder for the related model to add its own conditions
+func (qb *QueryBuilder[T]) relationQuery(name string, callback func(types.QueryBuilder[any])) (*relationQuery, error) {
+	rel, err := qb.relationship(name)
+	if err != nil {
+		return nil, err
+	}
+
+	alias := relationAlias(rel, qb.baseTable, qb.DB.NamingStrategy)
+	related := reflect.New(rel.FieldSchema.ModelType).Interface()
+
+	// The model keeps the related soft delete scope, the alias keeps it apart from the parent
+	db := qb.DB.Session(&gorm.Session{NewDB: true}).
+		Model(related).
+		Table(fmt.Sprintf("%s AS %s", utils.Quote(rel.FieldSchema.Table), alias))
+
+	on, where := correlate(rel, qb.baseTable, alias)
+	if rel.JoinTable != nil {
+		db = db.Joins(fmt.Sprintf("JOIN %s ON %s", utils.Quote(rel.JoinTable.Table), strings.Join(on, " AND ")))
+	}
+	for _, condition := range where {
+		db = db.Where(condition)
+	}
+
+	if callback != nil {
+		sub := &QueryBuilder[any]{
+			DB:           db,
+			baseTable:    alias,
+			knownAliases: make(map[string]bool),
+			page:         1,
+			limit:        10,
+		}
+		callback(sub)
+		db = sub.build()
+	}
+
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	return &relationQuery{db: db, alias: alias}, nil
+}
+
+// WhereHas keeps only the rows that have at least one related row matching the callback
+// The foreign keys are taken from the GORM relationship, e.g. Team.Members or Project.Tasks
+//
+//	teams.WhereHas("Members", func(q crud.QueryBuilder[any]) { q.Where("role", "admin") })
+//
+// Parameters:
+//   - relation: The relationship field name of the model
+//   - callback: Optional conditions on the related rows, unqualified columns resolve against the related table
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) WhereHas(relation string, callback func(types.QueryBuilder[any])) types.QueryBuilder[T] {
+	return qb.whereExists(relation, callback, "EXISTS")
+}
+
+// WhereDoesntHave keeps only the rows that have no related row matching the callback
+// Parameters:
+//   - relation: The relationship field name of the model
+//   - callback: Optional conditions on the related rows, unqualified columns resolve against the related table
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) WhereDoesntHave(relation string, callback func(types.QueryBuilder[any])) types.QueryBuilder[T] {
+	return qb.whereExists(relation, callback, "NOT EXISTS")
+}
+
+func (qb *QueryBuilder[T]) whereExists(relation string, callback func(types.QueryBuilder[any]), op string) types.QueryBuilder[T] {
+	sub, err := qb.relationQuery(relation, callback)
+	if err != nil {
+		_ = qb.DB.AddError(err)
+		return qb
+	}
+
+	qb.DB = qb.DB.Where(op+" (?)", sub.db.Select("1"))
+	return qb
+}
+
+// WithCount selects the number of related rows of each row as "<relation>_count", e.g. "members_count"
+// The count is a correlated subquery, so it does not require a GroupBy on the query
+// Parameters:
+//   - relation: The relationship field name of the model
+//   - callback: Optional conditions restricting which related rows are counted
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) WithCount(relation string, callback func(types.QueryBuilder[any])) types.QueryBuilder[T] {
+	sub, err := qb.relationQuery(relation, callback)
+	if err != nil {
+		_ = qb.DB.AddError(err)
+		return qb
+	}
+
+	alias := qb.DB.NamingStrategy.ColumnName("", relation) + "_count"
+	qb.knownAliases[alias] = true
+
+	// Keep the current selection, or every column of the base table when there is none
+	stmt := qb.DB.Statement
+	sql := utils.Quote(qb.baseTable + ".*")
+	var vars []interface{}
+	if c, ok := stmt.Clauses["SELECT"]; ok && c.Expression != nil {
+		if expr, ok := c.Expression.(clause.Expr); ok {
+			sql, vars = expr.SQL, expr.Vars
+		}
+	} else if len(stmt.Selects) > 0 {
+		sql = strings.Join(stmt.Selects, ", ")
+	}
+
+	vars = append(append([]interface{}{}, vars...), sub.db.Select("COUNT(*)"))
+	qb.DB = qb.DB.Select(fmt.Sprintf("%s, (?) AS %s", sql, utils.Quote(alias)), vars...)
+	qb.DB.Statement.Selects = nil
+	return qb
+}
+
+// JoinRelation performs an INNER JOIN along a relationship path of the model, e.g. "Members" or "Tasks.Assignee"
+// Tables and ON conditions are derived from the GORM relationships, many2many relationships join their
+// join table first. Each related table is aliased by its table name, or by "<parent alias>_<relation>" when
+// that name is already used in the query, e.g. "tasks_parent_task" for a self-reference
+// Joining a path twice, or a path after one of its prefixes, reuses the joins already made
+// Parameters:
+//   - path: The relationship field names separated by dots
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) JoinRelation(path string) types.QueryBuilder[T] {
+	return qb.joinRelation(path, JoinInner)
+}
+
+// LeftJoinRelation performs a LEFT JOIN along a relationship path of the model, see JoinRelation
+// Parameters:
+//   - path: The relationship field names separated by dots, e.g. "Tasks.Assignee"
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) LeftJoinRelation(path string) types.QueryBuilder[T] {
+	return qb.joinRelation(path, JoinLeft)
+}
+
+// RelationAlias returns the alias a relationship path was joined as by JoinRelation or LeftJoinRelation
+// It can be used to qualify columns of the joined tables, e.g. qb.RelationAlias("Tasks.ParentTask") + ".title"
+func (qb *QueryBuilder[T]) RelationAlias(path string) string {
+	return qb.relationJoins[path]
+}
+
+func (qb *QueryBuilder[T]) joinRelation(path string, joinType JoinType) types.QueryBuilder[T] {
+	current, err := qb.schema()
+	if err != nil {
+		_ = qb.DB.AddError(err)
+		return qb
+	}
+
+	if qb.relationJoins == nil {
+		qb.relationJoins = make(map[string]string)
+	}
+
+	parent := qb.baseTable
+	segments := strings.Split(path, ".")
+	for i, name := range segments {
+		rel, ok := current.Relationships.Relations[name]
+		if !ok {
+			_ = qb.DB.AddError(fmt.Errorf("%s has no relationship '%s' in path '%s'", current.Name, name, path))
+			return qb
+		}
+
+		prefix := strings.Join(segments[:i+1], ".")
+		alias, joined := qb.relationJoins[prefix]
+		if !joined {
+			alias = qb.uniqueAlias(rel.FieldSchema.Table, parent+"_"+qb.DB.NamingStrategy.ColumnName("", rel.Name))
+			on, where := correlate(rel, parent, alias)
+			if condition := qb.softDeleteCondition(rel.FieldSchema, alias); condition != "" {
+				if rel.JoinTable != nil {
+					on = append(on, condition)
+				} else {
+					where = append(where, condition)
+				}
+			}
+
+			if rel.JoinTable != nil {
+				qb.addRelationJoin(rel.JoinTable.Table, rel.JoinTable.Table, parent, where, joinType)
+				qb.addRelationJoin(rel.FieldSchema.Table, alias, rel.JoinTable.Table, on, joinType)
+			} else {
+				qb.addRelationJoin(rel.FieldSchema.Table, alias, parent, where, joinType)
+			}
+			qb.relationJoins[prefix] = alias
+		}
+
+		parent = alias
+		current = rel.FieldSchema
+	}
+
+	qb.lastJoinedTable = parent
+	return qb
+}
+
+// softDeleteCondition excludes soft deleted rows of a joined model, unless the query is unscoped
+func (qb *QueryBuilder[T]) softDeleteCondition(s *schema.Schema, alias string) string {
+	if qb.DB.Statement.Unscoped {
+		return ""
+	}
+
+	field := s.LookUpField("DeletedAt")
+	if field == nil || field.FieldType != reflect.TypeOf(gorm.DeletedAt{}) {
+		return ""
+	}
+	return fmt.Sprintf("%s IS NULL", utils.Quote(alias+"."+field.DBName))
+}
+
+// uniqueAlias returns name, or fallback when name is already used by the base table or a join
+func (qb *QueryBuilder[T]) uniqueAlias(name, fallback string) string {
+	if name == qb.baseTable {
+		return fallback
+	}
+	for _, join := range qb.joins {
+		if join.Condition.JoinTable == name {
+			return fallback
+		}
+	}
+	return name
+}
+
+// addRelationJoin adds a join with conditions already derived from a relationship
+func (qb *QueryBuilder[T]) addRelationJoin(table, alias, parent string, conditions []string, joinType JoinType) {
+	source := utils.Quote(table)
+	if alias != table {
+		source = fmt.Sprintf("%s AS %s", source, utils.Quote(alias))
+	}
+
+	condition := NewJoinConditionBuilder(parent, alias)
+	condition.conditions = conditions
+	condition.currentOp = "AND"
+
+	qb.joins = append(qb.joins, JoinClause{
+		Type:      joinType,
+		Table:     source,
+		Condition: condition,
+	})
+}
@@ -0,0 +1,45 @@
+package builder_test
+
+import (
+	"konsultn-api/internal/domain/team/enum"
+	teamModel "konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/shared/crud/builder"
+	"konsultn-api/internal/shared/crud/pagination"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/testkit"
+	"testing"
+)
+
+func TestRelationConditions(t *testing.T) {
+	sql := query[teamModel.Team](t).
+		WhereHas("Members", func(q types.QueryBuilder[any]) { q.Where("role", "admin") }).
+		WithCount("Members", nil).
+		WhereDoesntHave("Members", nil).
+		ToRawSQL()
+
+	assertSQL(t, sql,
+		`EXISTS (SELECT 1 FROM "team_members" AS team_members WHERE "team_members"."team_id" = "teams"."id" AND "role" = 'admin' AND "team_members"."deleted_at" IS NULL)`,
+		`(SELECT COUNT(*) FROM "team_members"`,
+		`AS "members_count"`,
+		`NOT EXISTS (SELECT 1 FROM "team_members"`,
+	)
+}
+
+func TestWithCountSort(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	small, large := f.Team(f.User()), f.Team(f.User())
+	f.Member(large, f.User(), enum.Admin)
+	f.Member(large, f.User(), enum.Member)
+
+	page, err := builder.NewQueryBuilder[teamModel.Team](db.Model(&teamModel.Team{})).
+		WithCount("Members", nil).
+		WithPageParams(pagination.QueryParams{Sort: "members_count", Order: "desc"}).
+		PaginateMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Result) != 2 || page.Result[0]["id"] != large.ID || page.Result[1]["id"] != small.ID {
+		t.Fatalf("teams by member count = %+v", page.Result)
+	}
+}
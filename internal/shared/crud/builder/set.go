@@ -0,0 +1,99 @@
+package builder
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"konsultn-api/internal/shared/crud/types"
+)
+
+// SetOperator represents a SQL set operation combining the rows of two queries
+type SetOperator string
+
+const (
+	SetUnion     SetOperator = "UNION"
+	SetUnionAll  SetOperator = "UNION ALL"
+	SetIntersect SetOperator = "INTERSECT"
+	SetExcept    SetOperator = "EXCEPT"
+)
+
+// Union combines the rows of the current query with the given queries, removing duplicates
+// See combine for how conditions before and after the call apply
+// Parameters:
+//   - others: The queries to combine with, they must select compatible columns
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) Union(others ...types.Subquery) types.QueryBuilder[T] {
+	return qb.combine(SetUnion, others)
+}
+
+// UnionAll combines the rows of the current query with the given queries, keeping duplicates
+// Parameters:
+//   - others: The queries to combine with, they must select compatible columns
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) UnionAll(others ...types.Subquery) types.QueryBuilder[T] {
+	return qb.combine(SetUnionAll, others)
+}
+
+// Intersect keeps only the rows returned by the current query and every given query
+// Parameters:
+//   - others: The queries to intersect with, they must select compatible columns
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) Intersect(others ...types.Subquery) types.QueryBuilder[T] {
+	return qb.combine(SetIntersect, others)
+}
+
+// Except removes the rows returned by any of the given queries from the current query
+// Parameters:
+//   - others: The queries whose rows are removed, they must select compatible columns
+//
+// Returns: The query builder for method chaining
+func (qb *QueryBuilder[T]) Except(others ...types.Subquery) types.QueryBuilder[T] {
+	return qb.combine(SetExcept, others)
+}
+
+// combine replaces the query with a set operation between the current query and others
+// The combined rows become the FROM of a new query, aliased as the base table, so that
+// conditions, WithPageParams, Paginate and Count called afterward apply to the combined result:
+//
+//	SELECT * FROM (SELECT * FROM (...) AS set_0 UNION ALL SELECT * FROM (...) AS set_1) AS tasks
+//	ORDER BY tasks.created_at DESC LIMIT 10
+//
+// Conditions, joins and OrderBy/Limit added before the call stay in the left operand
+// Each operand can be built from a different model, e.g. to merge tasks and projects in one feed
+func (qb *QueryBuilder[T]) combine(op SetOperator, others []types.Subquery) types.QueryBuilder[T] {
+	if len(others) == 0 {
+		return qb
+	}
+
+	// PostgreSQL rejects FOR UPDATE/SHARE together with set operations
+	if qb.lock != nil {
+		_ = qb.DB.AddError(fmt.Errorf("row locks are not supported with %s", op))
+		return qb
+	}
+
+	// Operands are wrapped in derived tables so they may carry their own ORDER BY and LIMIT
+	sql := "SELECT * FROM (?) AS set_0"
+	vars := []interface{}{qb.build()}
+	for i, other := range others {
+		operand := other.G(true)
+		if operand.Error != nil {
+			_ = qb.DB.AddError(operand.Error)
+			return qb
+		}
+		sql = fmt.Sprintf("%s %s SELECT * FROM (?) AS set_%d", sql, op, i+1)
+		vars = append(vars, operand)
+	}
+
+	// The model's soft delete scope cannot apply to the combined rows, each operand carries its own
+	qb.DB = qb.DB.Session(&gorm.Session{NewDB: true}).
+		Table(fmt.Sprintf("(%s) AS %s", sql, qb.baseTable), vars...).
+		Unscoped()
+
+	qb.joins = nil
+	qb.relationJoins = nil
+	qb.lastJoinedTable = ""
+	qb.orderClause = ""
+	return qb
+}
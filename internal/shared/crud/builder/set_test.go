@@ -0,0 +1,66 @@
+package builder_test
+
+import (
+	projectModel "konsultn-api/internal/domain/project/model"
+	"konsultn-api/internal/domain/task"
+	teamModel "konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/shared/crud/builder"
+	"konsultn-api/internal/shared/crud/pagination"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/testkit"
+	"testing"
+)
+
+// feedItem is a row of a union of tasks and projects
+type feedItem struct {
+	ID    string `db:"id"`
+	Title string `db:"title"`
+	Kind  string `db:"kind"`
+}
+
+func TestUnionAllSQL(t *testing.T) {
+	tasks := query[task.Task](t).Select("id", "title", []string{"'task'", "kind"}).Where(task.ColTaskStatus, "open")
+	projects := query[projectModel.Project](t).Select("id", []string{"name", "title"}, []string{"'project'", "kind"})
+
+	sql := tasks.UnionAll(projects).WhereLT("created_at", "2024").ToRawSQL()
+	assertSQL(t, sql,
+		`SELECT * FROM (SELECT * FROM (SELECT "id", "title", 'task' AS "kind" FROM "tasks" WHERE`,
+		`AS set_0 UNION ALL SELECT * FROM (`,
+		`AS set_1) AS tasks WHERE "created_at" < '2024'`,
+	)
+}
+
+func TestSetOperations(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	for _, name := range []string{"a", "b", "c"} {
+		f.Team(f.User(), func(team *teamModel.Team) { team.Name = name })
+	}
+	names := func(in ...string) types.QueryBuilder[teamModel.Team] {
+		return builder.NewQueryBuilder[teamModel.Team](db.Model(&teamModel.Team{})).
+			Select("id", "name").
+			WhereIN(teamModel.ColTeamName, in)
+	}
+
+	union := names("a", "b").Union(names("b", "c")).WithPageParams(pagination.QueryParams{Limit: 2, Sort: "name", Order: "desc"})
+	page, err := union.PaginateMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.TotalCount != 3 || len(page.Result) != 2 || page.Result[0]["name"] != "c" {
+		t.Fatalf("union page = %+v", page)
+	}
+
+	both, err := names("a", "b").Intersect(names("b", "c")).AllAsMaps()
+	if err != nil || len(both) != 1 || both[0]["name"] != "b" {
+		t.Fatalf("intersection = %+v, %v", both, err)
+	}
+
+	feed, err := builder.NewProjection[teamModel.Team, feedItem](
+		names("a").Select("id", []string{"name", "title"}, []string{"'team'", "kind"}).
+			UnionAll(names("a").Select("id", []string{"name", "title"}, []string{"'copy'", "kind"})),
+	).All()
+	if err != nil || len(feed) != 2 || feed[0].Title != "a" {
+		t.Fatalf("feed = %+v, %v", feed, err)
+	}
+}
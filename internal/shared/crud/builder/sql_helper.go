@@ -28,33 +28,33 @@ func (s SQL) Now() types.RawValue {
 }
 
 // Count creates a COUNT expression
-func (s SQL) Count(field string) types.RawValue {
-	return types.RawValue{Value: "COUNT(" + utils.Quote(field) + ")"}
+func (s SQL) Count(field types.Column) types.RawValue {
+	return types.RawValue{Value: "COUNT(" + utils.Quote(string(field)) + ")"}
 }
 
 // Sum creates a SUM expression
-func (s SQL) Sum(field string) types.RawValue {
-	return types.RawValue{Value: "SUM(" + utils.Quote(field) + ")"}
+func (s SQL) Sum(field types.Column) types.RawValue {
+	return types.RawValue{Value: "SUM(" + utils.Quote(string(field)) + ")"}
 }
 
 // Avg creates an AVG expression
-func (s SQL) Avg(field string) types.RawValue {
-	return types.RawValue{Value: "AVG(" + utils.Quote(field) + ")"}
+func (s SQL) Avg(field types.Column) types.RawValue {
+	return types.RawValue{Value: "AVG(" + utils.Quote(string(field)) + ")"}
 }
 
 // Min creates a MIN expression
-func (s SQL) Min(field string) types.RawValue {
-	return types.RawValue{Value: "MIN(" + utils.Quote(field) + ")"}
+func (s SQL) Min(field types.Column) types.RawValue {
+	return types.RawValue{Value: "MIN(" + utils.Quote(string(field)) + ")"}
 }
 
 // Max creates a MAX expression
-func (s SQL) Max(field string) types.RawValue {
-	return types.RawValue{Value: "MAX(" + utils.Quote(field) + ")"}
+func (s SQL) Max(field types.Column) types.RawValue {
+	return types.RawValue{Value: "MAX(" + utils.Quote(string(field)) + ")"}
 }
 
 // Coalesce creates a COALESCE expression
-func (s SQL) Coalesce(fields []string, defaultValue ...interface{}) types.RawValue {
-	sql := "COALESCE(" + strings.Join(fields, ", ")
+func (s SQL) Coalesce(fields []types.Column, defaultValue ...interface{}) types.RawValue {
+	sql := "COALESCE(" + strings.Join(types.Columns(fields...), ", ")
 	if len(defaultValue) > 0 {
 		sql += ", ?"
 		return utils.SafeSQL(sql+")", defaultValue[0])
@@ -68,13 +68,13 @@ func (s SQL) Cast(value interface{}, dataType string) types.RawValue {
 }
 
 // Lower creates a LOWER function call
-func (s SQL) Lower(field string) types.RawValue {
-	return types.RawValue{Value: "LOWER(" + utils.Quote(field) + ")"}
+func (s SQL) Lower(field types.Column) types.RawValue {
+	return types.RawValue{Value: "LOWER(" + utils.Quote(string(field)) + ")"}
 }
 
 // Upper creates an UPPER function call
-func (s SQL) Upper(field string) types.RawValue {
-	return types.RawValue{Value: "UPPER(" + utils.Quote(field) + ")"}
+func (s SQL) Upper(field types.Column) types.RawValue {
+	return types.RawValue{Value: "UPPER(" + utils.Quote(string(field)) + ")"}
 }
 
 // Concat creates a string concatenation expression
@@ -119,3 +119,19 @@ func (s SQL) Interval(value string) types.RawValue {
 func (s SQL) Extract(part string, field string) types.RawValue {
 	return types.RawValue{Value: fmt.Sprintf("EXTRACT(%s FROM %s)", part, utils.Quote(field))}
 }
+
+// JSONText extracts the value at a JSON path as text
+// e.g. SQL.JSONText("settings->notifications->email")
+// A path without keys is returned as a plain column so the database reports it
+func (s SQL) JSONText(path string) types.RawValue {
+	column, keys, err := splitJSONPath(path)
+	if err != nil {
+		return types.RawValue{Value: utils.Quote(path)}
+	}
+	return utils.SafeSQL(utils.Quote(column)+" #>> ?::text[]", types.TextArray(keys))
+}
+
+// JSONArrayLength creates a jsonb_array_length expression
+func (s SQL) JSONArrayLength(field string) types.RawValue {
+	return types.RawValue{Value: "jsonb_array_length(" + utils.Quote(string(field)) + ")"}
+}
@@ -0,0 +1,135 @@
+package builder
+
+import (
+	"database/sql"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"konsultn-api/internal/shared/crud/builder/utils"
+	"konsultn-api/internal/shared/crud/types"
+)
+
+// aggregateQuery prepares the filtered query of qb to select a single aggregate expression
+// Grouped queries are rejected since they would return one aggregate per group
+// The ordering and limit of the query are ignored, the aggregate covers every matching row
+func aggregateQuery[T any](qb types.QueryBuilder[T], expression string) (*gorm.DB, error) {
+	db := qb.G(true)
+	if _, ok := db.Statement.Clauses["GROUP BY"]; ok {
+		return nil, fmt.Errorf("aggregate %s is not supported on grouped queries, use Pluck instead", expression)
+	}
+	db = db.Select(expression)
+
+	// PostgreSQL rejects FOR UPDATE/SHARE together with aggregate functions
+	delete(db.Statement.Clauses, clause.Locking{}.Name())
+	// The ordering and limit of the query apply to its rows, not to the single aggregate row,
+	// and PostgreSQL rejects ordering by a column that is neither grouped nor aggregated
+	delete(db.Statement.Clauses, "ORDER BY")
+	delete(db.Statement.Clauses, "LIMIT")
+	return db, nil
+}
+
+// scanSingle executes the query and scans the single row it returns into dest
+func scanSingle(db *gorm.DB, dest ...interface{}) error {
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+// Sum returns the sum of a column over all rows matching the query
+// An empty result set sums to zero
+// Parameters:
+//   - qb: The query builder providing the filters and joins
+//   - column: The column to sum
+//
+// Returns:
+//   - N: The typed sum
+//   - error: Any error that occurred during the query
+func Sum[N types.Number, T any](qb types.QueryBuilder[T], column types.Column) (N, error) {
+	var sum N
+	db, err := aggregateQuery(qb, fmt.Sprintf("COALESCE(SUM(%s), 0)", utils.Quote(string(column))))
+	if err != nil {
+		return sum, err
+	}
+	err = scanSingle(db, &sum)
+	return sum, err
+}
+
+// Avg returns the average of a column over all rows matching the query
+// Parameters:
+//   - qb: The query builder providing the filters and joins
+//   - column: The column to average
+//
+// Returns:
+//   - float64: The average, or zero when no rows matched
+//   - error: Any error that occurred during the query
+func Avg[T any](qb types.QueryBuilder[T], column types.Column) (float64, error) {
+	var avg sql.Null[float64]
+	db, err := aggregateQuery(qb, fmt.Sprintf("AVG(%s)", utils.Quote(string(column))))
+	if err != nil {
+		return 0, err
+	}
+	err = scanSingle(db, &avg)
+	return avg.V, err
+}
+
+// Min returns the smallest value of a column over all rows matching the query
+// Parameters:
+//   - qb: The query builder providing the filters and joins
+//   - column: The column to inspect
+//
+// Returns:
+//   - V: The typed minimum, or the zero value when no rows matched
+//   - error: Any error that occurred during the query
+func Min[V any, T any](qb types.QueryBuilder[T], column types.Column) (V, error) {
+	return extremum[V](qb, "MIN", column)
+}
+
+// Max returns the largest value of a column over all rows matching the query
+// Parameters:
+//   - qb: The query builder providing the filters and joins
+//   - column: The column to inspect
+//
+// Returns:
+//   - V: The typed maximum, or the zero value when no rows matched
+//   - error: Any error that occurred during the query
+func Max[V any, T any](qb types.QueryBuilder[T], column types.Column) (V, error) {
+	return extremum[V](qb, "MAX", column)
+}
+
+func extremum[V any, T any](qb types.QueryBuilder[T], fn string, column types.Column) (V, error) {
+	var value sql.Null[V]
+	db, err := aggregateQuery(qb, fmt.Sprintf("%s(%s)", fn, utils.Quote(string(column))))
+	if err != nil {
+		return value.V, err
+	}
+	err = scanSingle(db, &value)
+	return value.V, err
+}
+
+// Pluck returns the values of a single column for all rows matching the query
+// Parameters:
+//   - qb: The query builder providing the filters and joins
+//   - column: The column to retrieve
+//
+// Returns:
+//   - []V: The typed column values
+//   - error: Any error that occurred during the query
+func Pluck[V any, T any](qb types.QueryBuilder[T], column types.Column) ([]V, error) {
+	values := make([]V, 0)
+	err := qb.G(true).Pluck(utils.Quote(string(column)), &values).Error
+	return values, err
+}
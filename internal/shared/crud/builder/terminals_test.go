@@ -0,0 +1,51 @@
+package builder_test
+
+import (
+	"konsultn-api/internal/domain/task"
+	"konsultn-api/internal/shared/crud/builder"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/testkit"
+	"testing"
+)
+
+func TestAggregateTerminals(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+	for _, status := range []string{"open", "open", "done"} {
+		f.Task(nil, func(t *task.Task) { t.Status = status })
+	}
+	query := func() types.QueryBuilder[task.Task] {
+		return builder.NewQueryBuilder[task.Task](db.Model(&task.Task{}))
+	}
+
+	statuses, err := builder.Pluck[string](query().Where(task.ColTaskStatus, "open"), task.ColTaskStatus)
+	if err != nil || len(statuses) != 2 {
+		t.Fatalf("Pluck = %v, %v", statuses, err)
+	}
+
+	first, err := builder.Min[string](query(), task.ColTaskStatus)
+	if err != nil || first != "done" {
+		t.Fatalf("Min = %q, %v", first, err)
+	}
+
+	last, err := builder.Max[string](query(), task.ColTaskStatus)
+	if err != nil || last != "open" {
+		t.Fatalf("Max = %q, %v", last, err)
+	}
+
+	count, err := builder.Sum[int](query().Where(task.ColTaskStatus, "none"), "length(status)")
+	if err != nil || count != 0 {
+		t.Fatalf("Sum over no rows = %d, %v", count, err)
+	}
+}
+
+func TestHavingColumns(t *testing.T) {
+	sql := builder.NewQueryBuilder[widget](dryRun(t)).
+		Select("status", builder.SQL{}.Count("id")).
+		GroupBy("status").
+		HavingGT("COUNT(id)", 1).
+		HavingIN("status", []string{"open", "done"}).
+		ToRawSQL()
+
+	assertSQL(t, sql, `GROUP BY "status" HAVING COUNT(id) > 1 AND "status" IN ('open','done')`)
+}
@@ -0,0 +1,94 @@
+package crudtest
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"konsultn-api/internal/shared/crud/types"
+	"reflect"
+	"testing"
+)
+
+// AssertCount fails the test when the repository does not hold exactly want records
+func AssertCount[T any, ID comparable](t testing.TB, repo types.Repository[T, ID], want int64) {
+	t.Helper()
+
+	got, err := repo.Count()
+	if err != nil {
+		t.Fatalf("count %s: %v", repo.GetTableName(), err)
+	}
+	if got != want {
+		t.Errorf("%s holds %d records, want %d", repo.GetTableName(), got, want)
+	}
+}
+
+// AssertExists fails the test when no record with the given ID exists, and returns it otherwise
+func AssertExists[T any, ID comparable](t testing.TB, repo types.Repository[T, ID], id ID) *T {
+	t.Helper()
+
+	model, err := repo.FindById(id)
+	if err != nil {
+		t.Fatalf("%s record %v not found: %v", repo.GetTableName(), id, err)
+	}
+	return model
+}
+
+// AssertMissing fails the test when a record with the given ID exists
+// Soft deleted records count as missing
+func AssertMissing[T any, ID comparable](t testing.TB, repo types.Repository[T, ID], id ID) {
+	t.Helper()
+
+	_, err := repo.FindById(id)
+	if err == nil {
+		t.Errorf("%s record %v exists, want it missing", repo.GetTableName(), id)
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("find %s record %v: %v", repo.GetTableName(), id, err)
+	}
+}
+
+// AssertSoftDeleted fails the test unless the record with the given ID is stored but soft deleted
+func AssertSoftDeleted[T any, ID comparable](t testing.TB, repo *Repository[T, ID], id ID) {
+	t.Helper()
+
+	match := repo.matcher([]condition{repo.idCondition(id)})
+	for _, row := range repo.Unscoped() {
+		if ok, _ := match(row); ok {
+			if !repo.isDeleted(row) {
+				t.Errorf("%s record %v is not soft deleted", repo.GetTableName(), id)
+			}
+			return
+		}
+	}
+	t.Errorf("%s record %v is not stored", repo.GetTableName(), id)
+}
+
+// AssertWhere fails the test when the number of records matching the filters is not want
+func AssertWhere[T any, ID comparable](t testing.TB, repo types.Repository[T, ID], filters map[string]interface{}, want int) []*T {
+	t.Helper()
+
+	models, err := repo.FindWhere(filters)
+	if err != nil {
+		t.Fatalf("find %s where %v: %v", repo.GetTableName(), filters, err)
+	}
+	if len(models) != want {
+		t.Errorf("%s has %d records where %v, want %d", repo.GetTableName(), len(models), filters, want)
+	}
+	return models
+}
+
+// AssertColumn fails the test when a column of model does not equal want
+// The column may be given by its name or its Go field name
+func AssertColumn[T any, ID comparable](t testing.TB, repo *Repository[T, ID], model *T, column string, want interface{}) {
+	t.Helper()
+
+	field := repo.field(column)
+	if field == nil {
+		t.Fatalf("%s has no column '%s'", repo.GetTableName(), column)
+	}
+
+	got, _ := field.ValueOf(repo.db.Statement.Context, reflect.ValueOf(model).Elem())
+	if !equalValues(got, want) && !(isNull(got) && isNull(want)) {
+		t.Errorf("%s.%s = %v, want %v", repo.GetTableName(), field.DBName, normalize(got), want)
+	}
+}
@@ -0,0 +1,254 @@
+package crudtest
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"gorm.io/datatypes"
+	"gorm.io/gorm/schema"
+	"konsultn-api/internal/shared/crud/types"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// condition compares a column of a row with a value
+type condition struct {
+	field *schema.Field
+	op    string // "=", "!=", "IN", "IS NULL" or "IS NOT NULL"
+	value interface{}
+}
+
+// exprCondition matches a single comparison such as `status = ?`, `teams.id IN (?)` or `deleted_at IS NULL`
+var exprCondition = regexp.MustCompile(`(?i)^\s*"?([\w.]+?)"?\s*(=|!=|<>|IN\s*\(\s*\?\s*\)|IN\s*\?|IS\s+NULL|IS\s+NOT\s+NULL)\s*(\?)?\s*$`)
+
+var exprAnd = regexp.MustCompile(`(?i)\s+AND\s+`)
+
+// filterConditions turns a FindWhere filter map into conditions
+func (r *Repository[T, ID]) filterConditions(filters map[string]interface{}) ([]condition, error) {
+	conditions := make([]condition, 0, len(filters))
+	for column, value := range filters {
+		field := r.field(column)
+		if field == nil {
+			return nil, fmt.Errorf("unknown column '%s' of %s", column, r.schema.Name)
+		}
+
+		op := "="
+		if isSlice(value) {
+			op = "IN"
+		}
+		conditions = append(conditions, condition{field: field, op: op, value: value})
+	}
+	return conditions, nil
+}
+
+// parseExpr parses the simple SQL expressions accepted by FindWhereExpr, Exists and DeleteWhere:
+// comparisons of a column with a placeholder (=, !=, <>, IN ?, IN (?)) or NULL checks, joined with AND
+// Anything else fails with ErrUnsupportedQuery
+func (r *Repository[T, ID]) parseExpr(query string, args []interface{}) ([]condition, error) {
+	var conditions []condition
+	for _, part := range exprAnd.Split(strings.TrimSpace(query), -1) {
+		match := exprCondition.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedQuery, query)
+		}
+
+		field := r.field(match[1])
+		if field == nil {
+			return nil, fmt.Errorf("unknown column '%s' of %s", match[1], r.schema.Name)
+		}
+
+		op := strings.ToUpper(strings.Join(strings.Fields(match[2]), " "))
+		c := condition{field: field, op: op}
+
+		switch {
+		case op == "IS NULL" || op == "IS NOT NULL":
+		case strings.HasPrefix(op, "IN"):
+			c.op = "IN"
+			fallthrough
+		default:
+			if op == "<>" {
+				c.op = "!="
+			}
+			if len(args) == 0 {
+				return nil, fmt.Errorf("%w: missing argument in %s", ErrUnsupportedQuery, query)
+			}
+			c.value, args = args[0], args[1:]
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+// matcher returns a function reporting whether a row matches all conditions
+func (r *Repository[T, ID]) matcher(conditions []condition) func(*T) (bool, error) {
+	return func(row *T) (bool, error) {
+		rv := reflect.ValueOf(row).Elem()
+		for _, c := range conditions {
+			value, _ := c.field.ValueOf(r.db.Statement.Context, rv)
+
+			var ok bool
+			switch c.op {
+			case "=":
+				ok = equalValues(value, c.value)
+			case "!=":
+				ok = !isNull(value) && !equalValues(value, c.value)
+			case "IN":
+				ok = containsValue(c.value, value)
+			case "IS NULL":
+				ok = isNull(value)
+			case "IS NOT NULL":
+				ok = !isNull(value)
+			default:
+				return false, fmt.Errorf("%w: operator %s", ErrUnsupportedQuery, c.op)
+			}
+
+			if !ok {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// normalize dereferences pointers and unwraps driver.Valuer values such as gorm.DeletedAt
+func normalize(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	value = rv.Interface()
+
+	if _, isTime := value.(time.Time); !isTime {
+		if valuer, ok := value.(driver.Valuer); ok {
+			v, err := valuer.Value()
+			if err == nil {
+				return normalize(v)
+			}
+		}
+	}
+	return value
+}
+
+// isNull reports whether a column value is SQL NULL
+func isNull(value interface{}) bool {
+	return normalize(value) == nil
+}
+
+// equalValues compares a column value with a filter value the way the database would,
+// ignoring pointer indirection and differences between convertible types
+func equalValues(a, b interface{}) bool {
+	a, b = normalize(a), normalize(b)
+	if a == nil || b == nil {
+		// NULL never equals anything
+		return false
+	}
+
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if vb.Type().ConvertibleTo(va.Type()) && va.Kind() == vb.Kind() {
+		return reflect.DeepEqual(a, vb.Convert(va.Type()).Interface())
+	}
+	if isNumber(va.Kind()) && isNumber(vb.Kind()) {
+		return fmt.Sprint(a) == fmt.Sprint(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// containsValue reports whether value equals any element of the slice list
+func containsValue(list interface{}, value interface{}) bool {
+	rv := reflect.ValueOf(list)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return equalValues(value, list)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if equalValues(value, rv.Index(i).Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSlice(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	switch value.(type) {
+	case []byte, datatypes.JSON, json.RawMessage:
+		return false
+	}
+	kind := reflect.TypeOf(value).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func lastDot(column string) int {
+	return strings.LastIndex(column, ".")
+}
+
+// applyJSONSets applies jsonb_set style updates to a JSON document held in a column
+// Missing intermediate keys fail, like jsonb_set which leaves the document unchanged
+func applyJSONSets(current interface{}, sets types.JSONSets) (datatypes.JSON, error) {
+	doc := map[string]interface{}{}
+
+	var raw []byte
+	switch v := normalize(current).(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, set := range sets {
+		if len(set.Path) == 0 {
+			return nil, fmt.Errorf("jsonb_set requires a non-empty path")
+		}
+
+		encoded, err := types.ToJSONB(set.Value)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(encoded), &value); err != nil {
+			return nil, err
+		}
+
+		node := doc
+		for _, key := range set.Path[:len(set.Path)-1] {
+			next, ok := node[key].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("json path %v does not exist", set.Path)
+			}
+			node = next
+		}
+		node[set.Path[len(set.Path)-1]] = value
+	}
+
+	return json.Marshal(doc)
+}
@@ -0,0 +1,720 @@
+// Package crudtest provides an in-memory types.Repository and assertion helpers,
+// so services can be unit tested without a database
+//
+//	repo := crudtest.NewRepository[model.Team, string]()
+//	team, _ := repo.Save(&model.Team{Name: "Core", Slug: "core", OwnerID: "u1"})
+//	crudtest.AssertCount[model.Team, string](t, repo, 1)
+//
+// Rows are stored as copies, so changes to a returned model are only visible after saving it.
+// IDs are generated through the model's BeforeCreate hook (e.g. shared.ULID), or auto-incremented
+// for integer keys. Soft deletes, timestamps and unique indexes are taken from the GORM tags
+package crudtest
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"konsultn-api/internal/shared/crud/builder"
+	"konsultn-api/internal/shared/crud/types"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrUnsupportedQuery is returned for SQL the in-memory repository cannot evaluate,
+// such as QueryBuilder queries or raw expressions beyond simple column comparisons
+var ErrUnsupportedQuery = errors.New("query is not supported by the in-memory repository")
+
+// table holds the rows of a model, shared by a repository and its clones
+type table[T any] struct {
+	mu     sync.RWMutex
+	rows   []*T
+	nextID int64
+}
+
+// Repository is an in-memory implementation of types.Repository
+type Repository[T any, ID comparable] struct {
+	table   *table[T]
+	schema  *schema.Schema
+	db      *gorm.DB
+	selects []string
+}
+
+// Verify implementation at compile time
+var _ types.Repository[any, string] = (*Repository[any, string])(nil)
+
+// NewRepository creates an empty in-memory repository for the model T
+// It panics when T cannot be parsed as a GORM model
+func NewRepository[T any, ID comparable](seed ...*T) *Repository[T, ID] {
+	s, err := schema.Parse(new(T), &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Sprintf("crudtest: failed to parse %T: %v", new(T), err))
+	}
+
+	// The dry run database is only handed to model hooks and QueryBuilder, it renders PostgreSQL but never connects
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "sslmode=disable"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("crudtest: failed to open dry run database: %v", err))
+	}
+
+	r := &Repository[T, ID]{
+		table:  &table[T]{},
+		schema: s,
+		db:     db,
+	}
+
+	for _, model := range seed {
+		if _, err := r.Save(model); err != nil {
+			panic(fmt.Sprintf("crudtest: failed to seed %T: %v", model, err))
+		}
+	}
+	return r
+}
+
+// SetDB is a no-op, the in-memory repository has no database
+func (r *Repository[T, ID]) SetDB(*gorm.DB) {}
+
+// GetDB returns the dry run database used for hooks
+func (r *Repository[T, ID]) GetDB() *gorm.DB {
+	return r.db
+}
+
+// GetTableName returns the table name GORM derives for the model
+func (r *Repository[T, ID]) GetTableName() string {
+	return r.schema.Table
+}
+
+// Clone creates a copy of the repository that shares the same rows
+func (r *Repository[T, ID]) Clone() types.Repository[T, ID] {
+	return &Repository[T, ID]{
+		table:   r.table,
+		schema:  r.schema,
+		db:      r.db,
+		selects: append([]string{}, r.selects...),
+	}
+}
+
+// WithTx returns a copy of the repository, writes are applied immediately since there is no transaction
+func (r *Repository[T, ID]) WithTx(*gorm.DB) types.Repository[T, ID] {
+	return r.Clone()
+}
+
+// Select creates a copy of the repository that only fills the given columns of returned models
+func (r *Repository[T, ID]) Select(fields []string) types.Repository[T, ID] {
+	repo := r.Clone().(*Repository[T, ID])
+	repo.selects = fields
+	return repo
+}
+
+// Query returns a query builder that fails on execution, QueryBuilder needs a real database
+// Use the testkit package to test code built on QueryBuilder
+func (r *Repository[T, ID]) Query() types.QueryBuilder[T] {
+	db := r.db.Session(&gorm.Session{}).Model(new(T))
+	_ = db.AddError(fmt.Errorf("%w: QueryBuilder", ErrUnsupportedQuery))
+	return builder.NewQueryBuilder[T](db)
+}
+
+// FindAll retrieves all records that are not soft deleted
+func (r *Repository[T, ID]) FindAll() ([]*T, error) {
+	return r.find(func(*T) (bool, error) { return true, nil })
+}
+
+// Count returns the number of records that are not soft deleted
+func (r *Repository[T, ID]) Count() (int64, error) {
+	models, err := r.FindAll()
+	return int64(len(models)), err
+}
+
+// FindWhere retrieves records whose columns equal the filter values
+// Slice values match any of their elements, like an IN condition
+func (r *Repository[T, ID]) FindWhere(filters map[string]interface{}) ([]*T, error) {
+	conditions, err := r.filterConditions(filters)
+	if err != nil {
+		return nil, err
+	}
+	return r.find(r.matcher(conditions))
+}
+
+// FindWhereExpr retrieves records matching a simple expression, see parseExpr for the supported syntax
+func (r *Repository[T, ID]) FindWhereExpr(query string, args ...interface{}) ([]*T, error) {
+	conditions, err := r.parseExpr(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return r.find(r.matcher(conditions))
+}
+
+// FindBy retrieves records where the specified field matches the provided value
+func (r *Repository[T, ID]) FindBy(field string, value any) ([]*T, error) {
+	models, err := r.FindWhere(map[string]interface{}{field: value})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find records: %w", err)
+	}
+	return models, nil
+}
+
+// FindFirstBy retrieves the first record where the specified field matches the provided value
+func (r *Repository[T, ID]) FindFirstBy(field string, value any) (*T, error) {
+	models, err := r.FindWhere(map[string]interface{}{field: value})
+	if err == nil && len(models) == 0 {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find first record: %w", err)
+	}
+	return models[0], nil
+}
+
+// FindById retrieves a record by its ID
+func (r *Repository[T, ID]) FindById(id ID) (*T, error) {
+	model, err := r.FindFirstBy(r.primaryKey().DBName, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find record by ID: %w", errors.Unwrap(err))
+	}
+	return model, nil
+}
+
+// FindByIds retrieves multiple records by their IDs
+func (r *Repository[T, ID]) FindByIds(ids []ID) ([]*T, error) {
+	models, err := r.FindWhere(map[string]interface{}{r.primaryKey().DBName: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find records by IDs: %w", err)
+	}
+	return models, nil
+}
+
+// Preload loads the first record where key matches value into model
+// Relationships are left as stored, the in-memory repository knows no other tables
+func (r *Repository[T, ID]) Preload(model *T, _ []string, key string, value any) error {
+	found, err := r.FindWhere(map[string]interface{}{key: value})
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	*model = *found[0]
+	return nil
+}
+
+// Exists checks if any record matches a simple expression, see parseExpr for the supported syntax
+func (r *Repository[T, ID]) Exists(query string, args ...any) (bool, error) {
+	models, err := r.FindWhereExpr(query, args...)
+	return len(models) > 0, err
+}
+
+// ExistByID checks if a record exists with the specified ID
+func (r *Repository[T, ID]) ExistByID(id ID) (bool, error) {
+	models, err := r.FindWhere(map[string]interface{}{r.primaryKey().DBName: id})
+	return len(models) > 0, err
+}
+
+// Save creates the model when its ID is unknown and replaces the stored row otherwise
+func (r *Repository[T, ID]) Save(model *T) (*T, error) {
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	if err := r.save(model); err != nil {
+		return nil, fmt.Errorf("failed to save entity: %w", err)
+	}
+	return model, nil
+}
+
+// SaveAll saves multiple models, stopping at the first error
+func (r *Repository[T, ID]) SaveAll(models []*T) error {
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	for _, model := range models {
+		if err := r.save(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Updates specific columns of a stored model using the provided update map
+// JSONSet values are applied to the stored JSON document like jsonb_set
+func (r *Repository[T, ID]) Updates(model *T, m types.UpdateMap) error {
+	if err := m.Valid(); err != nil {
+		return err
+	}
+
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	index := r.indexOf(model)
+	if index < 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	updated := *r.table.rows[index]
+	for column, value := range m {
+		field := r.field(column)
+		if field == nil {
+			return fmt.Errorf("unknown column '%s' of %s", column, r.schema.Name)
+		}
+		if err := r.assign(&updated, field, value); err != nil {
+			return err
+		}
+	}
+	r.touch(&updated, false)
+
+	if err := r.checkUnique(&updated, index); err != nil {
+		return err
+	}
+	r.table.rows[index] = &updated
+	*model = updated
+	return nil
+}
+
+// UpsertOnlyColumns inserts the model, or when a row with the same conflict columns exists,
+// copies only the update columns into it. The model receives the stored row
+func (r *Repository[T, ID]) UpsertOnlyColumns(model *T, conflictColumns []string, updateColumns []string) (*T, error) {
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	conditions := make([]condition, 0, len(conflictColumns))
+	for _, column := range conflictColumns {
+		field := r.field(column)
+		if field == nil {
+			return nil, fmt.Errorf("failed to upsert entity with specified columns: unknown column '%s'", column)
+		}
+		value, _ := field.ValueOf(r.db.Statement.Context, reflect.ValueOf(model).Elem())
+		conditions = append(conditions, condition{field: field, op: "=", value: value})
+	}
+
+	match := r.matcher(conditions)
+	for i, row := range r.table.rows {
+		if ok, _ := match(row); !ok {
+			continue
+		}
+
+		updated := *row
+		source := reflect.ValueOf(model).Elem()
+		for _, column := range updateColumns {
+			field := r.field(column)
+			if field == nil {
+				return nil, fmt.Errorf("failed to upsert entity with specified columns: unknown column '%s'", column)
+			}
+			value, _ := field.ValueOf(r.db.Statement.Context, source)
+			if err := r.assign(&updated, field, value); err != nil {
+				return nil, err
+			}
+		}
+		r.table.rows[i] = &updated
+		*model = updated
+		return model, nil
+	}
+
+	if err := r.save(model); err != nil {
+		return nil, fmt.Errorf("failed to upsert entity with specified columns: %w", err)
+	}
+	return model, nil
+}
+
+// Delete performs either a soft or hard delete on the model based on the hard parameter
+// Models without a gorm.DeletedAt field are always removed
+func (r *Repository[T, ID]) Delete(model *T, hard bool) error {
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	if index := r.indexOf(model); index >= 0 {
+		r.remove(index, hard)
+	}
+	return nil
+}
+
+// DeleteById deletes a record by its ID, either soft or hard delete based on the hard parameter
+func (r *Repository[T, ID]) DeleteById(id ID, hard bool) error {
+	return r.deleteWhere([]condition{r.idCondition(id)}, hard)
+}
+
+// DeleteWhere soft deletes records matching a simple expression, see parseExpr for the supported syntax
+func (r *Repository[T, ID]) DeleteWhere(query string, args ...any) error {
+	conditions, err := r.parseExpr(query, args)
+	if err != nil {
+		return err
+	}
+	return r.deleteWhere(conditions, false)
+}
+
+// DeleteAll soft deletes all records
+func (r *Repository[T, ID]) DeleteAll() error {
+	return r.deleteWhere(nil, false)
+}
+
+// DeleteMany soft deletes multiple models
+func (r *Repository[T, ID]) DeleteMany(models []*T) error {
+	for _, model := range models {
+		if err := r.Delete(model, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteManyByIds soft deletes multiple records by their IDs
+func (r *Repository[T, ID]) DeleteManyByIds(ids []ID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.deleteWhere([]condition{{field: r.primaryKey(), op: "IN", value: ids}}, false)
+}
+
+// SoftDelete performs a soft delete on the model by setting its deleted_at timestamp
+func (r *Repository[T, ID]) SoftDelete(model *T) error {
+	return r.Delete(model, false)
+}
+
+// Unscoped returns every stored row, including soft deleted ones
+// It is meant for assertions on deleted rows
+func (r *Repository[T, ID]) Unscoped() []*T {
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+
+	models := make([]*T, 0, len(r.table.rows))
+	for _, row := range r.table.rows {
+		model := *row
+		models = append(models, &model)
+	}
+	return models
+}
+
+// find returns copies of the rows that are not soft deleted and match
+func (r *Repository[T, ID]) find(match func(*T) (bool, error)) ([]*T, error) {
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+
+	models := make([]*T, 0)
+	for _, row := range r.table.rows {
+		if r.isDeleted(row) {
+			continue
+		}
+		ok, err := match(row)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			models = append(models, r.project(row))
+		}
+	}
+	return models, nil
+}
+
+// project copies a stored row, keeping only the selected columns when Select was used
+func (r *Repository[T, ID]) project(row *T) *T {
+	model := *row
+	if len(r.selects) == 0 {
+		return &model
+	}
+
+	var selected T
+	source := reflect.ValueOf(&model).Elem()
+	target := reflect.ValueOf(&selected).Elem()
+	for _, column := range r.selects {
+		if field := r.field(column); field != nil {
+			value, _ := field.ValueOf(r.db.Statement.Context, source)
+			_ = field.Set(r.db.Statement.Context, target, value)
+		}
+	}
+	return &selected
+}
+
+// save creates or replaces a model, the caller holds the write lock
+func (r *Repository[T, ID]) save(model *T) error {
+	index := r.indexOf(model)
+	if index < 0 {
+		return r.create(model)
+	}
+
+	if hook, ok := any(model).(callbacks.BeforeSaveInterface); ok {
+		if err := hook.BeforeSave(r.db); err != nil {
+			return err
+		}
+	}
+	if hook, ok := any(model).(callbacks.BeforeUpdateInterface); ok {
+		if err := hook.BeforeUpdate(r.db); err != nil {
+			return err
+		}
+	}
+
+	r.touch(model, false)
+	if err := r.checkUnique(model, index); err != nil {
+		return err
+	}
+
+	stored := *model
+	r.table.rows[index] = &stored
+	return nil
+}
+
+// create inserts a new row, generating its ID and timestamps
+func (r *Repository[T, ID]) create(model *T) error {
+	if hook, ok := any(model).(callbacks.BeforeSaveInterface); ok {
+		if err := hook.BeforeSave(r.db); err != nil {
+			return err
+		}
+	}
+	if hook, ok := any(model).(callbacks.BeforeCreateInterface); ok {
+		if err := hook.BeforeCreate(r.db); err != nil {
+			return err
+		}
+	}
+
+	if err := r.generateID(model); err != nil {
+		return err
+	}
+	r.touch(model, true)
+
+	if err := r.checkUnique(model, -1); err != nil {
+		return err
+	}
+
+	stored := *model
+	r.table.rows = append(r.table.rows, &stored)
+	return nil
+}
+
+// generateID auto-increments integer primary keys that are still zero after the hooks ran
+func (r *Repository[T, ID]) generateID(model *T) error {
+	pk := r.primaryKey()
+	if pk == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(model).Elem()
+	if _, zero := pk.ValueOf(r.db.Statement.Context, rv); !zero {
+		return nil
+	}
+
+	switch pk.FieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		r.table.nextID++
+		return pk.Set(r.db.Statement.Context, rv, r.table.nextID)
+	}
+	return fmt.Errorf("%s has no value for primary key '%s'", r.schema.Name, pk.DBName)
+}
+
+// touch sets the autoCreateTime and autoUpdateTime fields of a model
+func (r *Repository[T, ID]) touch(model *T, creating bool) {
+	now := time.Now()
+	rv := reflect.ValueOf(model).Elem()
+	ctx := r.db.Statement.Context
+
+	for _, field := range r.schema.Fields {
+		if creating && field.AutoCreateTime > 0 {
+			if _, zero := field.ValueOf(ctx, rv); zero {
+				_ = field.Set(ctx, rv, now)
+			}
+		}
+		if field.AutoUpdateTime > 0 {
+			_ = field.Set(ctx, rv, now)
+		}
+	}
+}
+
+// remove soft deletes the row at index, or removes it for hard deletes and models without soft delete
+func (r *Repository[T, ID]) remove(index int, hard bool) {
+	deletedAt := r.deletedAtField()
+	if hard || deletedAt == nil {
+		r.table.rows = append(r.table.rows[:index], r.table.rows[index+1:]...)
+		return
+	}
+
+	deleted := *r.table.rows[index]
+	_ = deletedAt.Set(r.db.Statement.Context, reflect.ValueOf(&deleted).Elem(), time.Now())
+	r.table.rows[index] = &deleted
+}
+
+// deleteWhere deletes every row that is not soft deleted and matches all conditions
+func (r *Repository[T, ID]) deleteWhere(conditions []condition, hard bool) error {
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	match := r.matcher(conditions)
+	for i := len(r.table.rows) - 1; i >= 0; i-- {
+		row := r.table.rows[i]
+		if r.isDeleted(row) {
+			continue
+		}
+		ok, err := match(row)
+		if err != nil {
+			return err
+		}
+		if ok {
+			r.remove(i, hard)
+		}
+	}
+	return nil
+}
+
+// indexOf returns the position of the stored row with the same primary key as model, or -1
+// Soft deleted rows are found as well, like an UPDATE by primary key would
+func (r *Repository[T, ID]) indexOf(model *T) int {
+	pk := r.primaryKey()
+	if pk == nil {
+		return -1
+	}
+
+	ctx := r.db.Statement.Context
+	id, zero := pk.ValueOf(ctx, reflect.ValueOf(model).Elem())
+	if zero {
+		return -1
+	}
+
+	for i, row := range r.table.rows {
+		if stored, _ := pk.ValueOf(ctx, reflect.ValueOf(row).Elem()); equalValues(stored, id) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isDeleted reports whether a row is soft deleted
+func (r *Repository[T, ID]) isDeleted(row *T) bool {
+	field := r.deletedAtField()
+	if field == nil {
+		return false
+	}
+
+	value, _ := field.ValueOf(r.db.Statement.Context, reflect.ValueOf(row).Elem())
+	deletedAt, ok := value.(gorm.DeletedAt)
+	return ok && deletedAt.Valid
+}
+
+// deletedAtField returns the gorm.DeletedAt field of the model, or nil
+func (r *Repository[T, ID]) deletedAtField() *schema.Field {
+	for _, field := range r.schema.Fields {
+		if field.FieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+			return field
+		}
+	}
+	return nil
+}
+
+// primaryKey returns the primary key field of the model, or nil
+func (r *Repository[T, ID]) primaryKey() *schema.Field {
+	return r.schema.PrioritizedPrimaryField
+}
+
+// idCondition matches the row with the given ID
+func (r *Repository[T, ID]) idCondition(id ID) condition {
+	return condition{field: r.primaryKey(), op: "=", value: id}
+}
+
+// field resolves a column name, qualified column name or Go field name to its schema field
+func (r *Repository[T, ID]) field(column string) *schema.Field {
+	if i := lastDot(column); i >= 0 {
+		column = column[i+1:]
+	}
+	return r.schema.LookUpField(column)
+}
+
+// assign sets a column of model to value, applying JSONSet updates to the current document
+func (r *Repository[T, ID]) assign(model *T, field *schema.Field, value interface{}) error {
+	rv := reflect.ValueOf(model).Elem()
+	ctx := r.db.Statement.Context
+
+	var sets types.JSONSets
+	switch v := value.(type) {
+	case types.JSONSet:
+		sets = types.JSONSets{v}
+	case types.JSONSets:
+		sets = v
+	default:
+		return field.Set(ctx, rv, value)
+	}
+
+	current, _ := field.ValueOf(ctx, rv)
+	doc, err := applyJSONSets(current, sets)
+	if err != nil {
+		return fmt.Errorf("failed to update '%s': %w", field.DBName, err)
+	}
+	return field.Set(ctx, rv, doc)
+}
+
+// checkUnique fails with gorm.ErrDuplicatedKey when model collides with another row
+// on a unique column or unique index. Soft deleted rows count, like in the database
+func (r *Repository[T, ID]) checkUnique(model *T, self int) error {
+	ctx := r.db.Statement.Context
+	rv := reflect.ValueOf(model).Elem()
+
+	for _, fields := range r.uniqueKeys() {
+		values := make([]interface{}, len(fields))
+		hasNull := false
+		for i, field := range fields {
+			values[i], _ = field.ValueOf(ctx, rv)
+			hasNull = hasNull || isNull(values[i])
+		}
+		// NULLs never collide in a unique index
+		if hasNull {
+			continue
+		}
+
+		for i, row := range r.table.rows {
+			if i == self {
+				continue
+			}
+			other := reflect.ValueOf(row).Elem()
+			collides := true
+			for j, field := range fields {
+				value, _ := field.ValueOf(ctx, other)
+				if !equalValues(value, values[j]) {
+					collides = false
+					break
+				}
+			}
+			if collides {
+				return fmt.Errorf("%w: %s", gorm.ErrDuplicatedKey, uniqueKeyName(fields))
+			}
+		}
+	}
+	return nil
+}
+
+// uniqueKeys lists the field sets that must be unique: the primary key, unique columns and unique indexes
+func (r *Repository[T, ID]) uniqueKeys() [][]*schema.Field {
+	var keys [][]*schema.Field
+	if len(r.schema.PrimaryFields) > 0 {
+		keys = append(keys, r.schema.PrimaryFields)
+	}
+
+	for _, field := range r.schema.Fields {
+		if field.Unique && !field.PrimaryKey {
+			keys = append(keys, []*schema.Field{field})
+		}
+	}
+
+	for _, index := range r.schema.ParseIndexes() {
+		if index.Class != "UNIQUE" || index.Where != "" {
+			continue
+		}
+		fields := make([]*schema.Field, 0, len(index.Fields))
+		for _, option := range index.Fields {
+			fields = append(fields, option.Field)
+		}
+		keys = append(keys, fields)
+	}
+	return keys
+}
+
+func uniqueKeyName(fields []*schema.Field) string {
+	name := ""
+	for i, field := range fields {
+		if i > 0 {
+			name += ", "
+		}
+		name += field.DBName
+	}
+	return name
+}
@@ -0,0 +1,206 @@
+package crudtest_test
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"konsultn-api/internal/shared"
+	"konsultn-api/internal/shared/crud/crudtest"
+	"konsultn-api/internal/shared/crud/repository"
+	"konsultn-api/internal/shared/crud/types"
+	"konsultn-api/internal/testkit"
+	"testing"
+	"time"
+)
+
+// gadget is the model both repositories are compared on
+type gadget struct {
+	shared.ULID `gorm:"embedded"`
+	Slug        string `gorm:"size:50;uniqueIndex"`
+	Name        string `gorm:"size:50"`
+	Color       string `gorm:"size:20"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+// counter has an auto-incremented primary key
+type counter struct {
+	ID    int64 `gorm:"primaryKey"`
+	Label string
+}
+
+// implementations runs the test against the in-memory repository and BaseRepository on a testkit database
+func implementations[T any, ID comparable](t *testing.T, test func(t *testing.T, repo types.Repository[T, ID])) {
+	t.Run("crudtest", func(t *testing.T) {
+		test(t, crudtest.NewRepository[T, ID]())
+	})
+	t.Run("BaseRepository", func(t *testing.T) {
+		db := testkit.DB(t)
+		if err := db.AutoMigrate(new(T)); err != nil {
+			t.Fatal(err)
+		}
+		test(t, repository.NewBaseRepository[T, ID](db))
+	})
+}
+
+func TestSaveGeneratesIDs(t *testing.T) {
+	implementations(t, func(t *testing.T, repo types.Repository[gadget, string]) {
+		first, err := repo.Save(&gadget{Slug: "a", Name: "A"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := repo.Save(&gadget{Slug: "b", Name: "B"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first.ID == "" || first.ID >= second.ID {
+			t.Errorf("expected increasing ULIDs, got %q and %q", first.ID, second.ID)
+		}
+		if first.CreatedAt.IsZero() || first.UpdatedAt.IsZero() {
+			t.Error("expected timestamps to be set")
+		}
+	})
+
+	implementations(t, func(t *testing.T, repo types.Repository[counter, int64]) {
+		first, _ := repo.Save(&counter{Label: "a"})
+		second, _ := repo.Save(&counter{Label: "b"})
+		if first.ID != 1 || second.ID != 2 {
+			t.Errorf("expected IDs 1 and 2, got %d and %d", first.ID, second.ID)
+		}
+	})
+}
+
+func TestSaveUpdatesExistingRow(t *testing.T) {
+	implementations(t, func(t *testing.T, repo types.Repository[gadget, string]) {
+		saved, _ := repo.Save(&gadget{Slug: "a", Name: "A"})
+		saved.Name = "B"
+		if _, err := repo.Save(saved); err != nil {
+			t.Fatal(err)
+		}
+
+		crudtest.AssertCount[gadget, string](t, repo, 1)
+		if found := crudtest.AssertExists[gadget, string](t, repo, saved.ID); found.Name != "B" {
+			t.Errorf("expected the stored name to be B, got %s", found.Name)
+		}
+	})
+}
+
+func TestSoftDelete(t *testing.T) {
+	implementations(t, func(t *testing.T, repo types.Repository[gadget, string]) {
+		kept, _ := repo.Save(&gadget{Slug: "a"})
+		deleted, _ := repo.Save(&gadget{Slug: "b"})
+		removed, _ := repo.Save(&gadget{Slug: "c"})
+
+		if err := repo.Delete(deleted, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.DeleteById(removed.ID, true); err != nil {
+			t.Fatal(err)
+		}
+
+		crudtest.AssertCount[gadget, string](t, repo, 1)
+		crudtest.AssertExists[gadget, string](t, repo, kept.ID)
+		crudtest.AssertMissing[gadget, string](t, repo, deleted.ID)
+		crudtest.AssertMissing[gadget, string](t, repo, removed.ID)
+
+		if exists, _ := repo.ExistByID(deleted.ID); exists {
+			t.Error("expected soft deleted rows to be hidden from ExistByID")
+		}
+		if found, _ := repo.FindWhere(map[string]interface{}{"slug": "b"}); len(found) != 0 {
+			t.Error("expected soft deleted rows to be hidden from FindWhere")
+		}
+	})
+}
+
+func TestUniqueIndex(t *testing.T) {
+	implementations(t, func(t *testing.T, repo types.Repository[gadget, string]) {
+		if _, err := repo.Save(&gadget{Slug: "a"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.Save(&gadget{Slug: "a"}); err == nil {
+			t.Error("expected a duplicated slug to be rejected")
+		}
+	})
+
+	implementations(t, func(t *testing.T, repo types.Repository[gadget, string]) {
+		deleted, _ := repo.Save(&gadget{Slug: "a"})
+		_ = repo.Delete(deleted, false)
+		if _, err := repo.Save(&gadget{Slug: "a"}); err == nil {
+			t.Error("expected soft deleted rows to keep their slug")
+		}
+	})
+}
+
+func TestFindWhere(t *testing.T) {
+	implementations(t, func(t *testing.T, repo types.Repository[gadget, string]) {
+		_, _ = repo.Save(&gadget{Slug: "a", Color: "red"})
+		_, _ = repo.Save(&gadget{Slug: "b", Color: "red"})
+		_, _ = repo.Save(&gadget{Slug: "c", Color: "blue"})
+
+		for _, tc := range []struct {
+			filters map[string]interface{}
+			want    int
+		}{
+			{map[string]interface{}{"color": "red"}, 2},
+			{map[string]interface{}{"color": "red", "slug": "b"}, 1},
+			{map[string]interface{}{"color": []string{"red", "blue"}}, 3},
+			{map[string]interface{}{"color": "green"}, 0},
+		} {
+			crudtest.AssertWhere[gadget, string](t, repo, tc.filters, tc.want)
+		}
+
+		found, err := repo.FindWhereExpr("color = ? AND slug <> ?", "red", "a")
+		if err != nil || len(found) != 1 || found[0].Slug != "b" {
+			t.Errorf("FindWhereExpr = %v, %v", found, err)
+		}
+	})
+}
+
+func TestUpsertOnlyColumns(t *testing.T) {
+	implementations(t, func(t *testing.T, repo types.Repository[gadget, string]) {
+		original, err := repo.UpsertOnlyColumns(&gadget{Slug: "a", Name: "A", Color: "red"}, []string{"slug"}, []string{"name"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := original.ID
+
+		if _, err := repo.UpsertOnlyColumns(&gadget{Slug: "a", Name: "B", Color: "blue"}, []string{"slug"}, []string{"name"}); err != nil {
+			t.Fatal(err)
+		}
+
+		crudtest.AssertCount[gadget, string](t, repo, 1)
+		stored := crudtest.AssertExists[gadget, string](t, repo, id)
+		if stored.Name != "B" || stored.Color != "red" {
+			t.Errorf("expected only the name to be updated, got %+v", stored)
+		}
+	})
+}
+
+func TestUpdates(t *testing.T) {
+	implementations(t, func(t *testing.T, repo types.Repository[gadget, string]) {
+		saved, _ := repo.Save(&gadget{Slug: "a", Name: "A", Color: "red"})
+		if err := repo.Updates(saved, types.UpdateMap{"name": "B"}); err != nil {
+			t.Fatal(err)
+		}
+
+		stored := crudtest.AssertExists[gadget, string](t, repo, saved.ID)
+		if stored.Name != "B" || stored.Color != "red" {
+			t.Errorf("expected only the name to be updated, got %+v", stored)
+		}
+	})
+}
+
+func TestFindByIdMissing(t *testing.T) {
+	implementations(t, func(t *testing.T, repo types.Repository[gadget, string]) {
+		if _, err := repo.FindById("missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("expected gorm.ErrRecordNotFound, got %v", err)
+		}
+	})
+}
+
+func TestQueryIsUnsupported(t *testing.T) {
+	repo := crudtest.NewRepository[gadget, string]()
+	if _, err := repo.Query().Where("slug", "a").All(); !errors.Is(err, crudtest.ErrUnsupportedQuery) {
+		t.Errorf("expected ErrUnsupportedQuery, got %v", err)
+	}
+}
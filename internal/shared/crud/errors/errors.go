@@ -13,6 +13,10 @@ var (
 	ErrInvalidInput   = errors.New("invalid input")
 	ErrDatabaseError  = errors.New("database error")
 	ErrContextTimeout = errors.New("context timeout or cancelled")
+	ErrViewMismatch   = errors.New("view fields do not match selected columns")
+
+	ErrLockOutsideTransaction = errors.New("row locks require a transaction")
+	ErrLockWithoutStrength    = errors.New("NOWAIT and SKIP LOCKED require ForUpdate or ForShare")
 )
 
 // WrapError wraps a database error with a more descriptive message
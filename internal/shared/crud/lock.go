@@ -0,0 +1,53 @@
+package crud
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"hash/fnv"
+	"konsultn-api/internal/shared/crud/builder"
+	crudErrors "konsultn-api/internal/shared/crud/errors"
+)
+
+// advisoryKey maps a string key onto the bigint key space of PostgreSQL advisory locks
+func advisoryKey(key string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+// AdvisoryLock takes a transaction scoped PostgreSQL advisory lock for the given key
+// It blocks until the lock is available and releases it when the transaction ends
+func AdvisoryLock(tx *gorm.DB, key string) error {
+	if !builder.InTransaction(tx) {
+		return crudErrors.ErrLockOutsideTransaction
+	}
+
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", advisoryKey(key)).Error; err != nil {
+		return fmt.Errorf("failed to acquire advisory lock '%s': %w", key, err)
+	}
+	return nil
+}
+
+// TryAdvisoryLock tries to take a transaction scoped advisory lock without waiting
+// It returns false when another transaction already holds the lock
+func TryAdvisoryLock(tx *gorm.DB, key string) (bool, error) {
+	if !builder.InTransaction(tx) {
+		return false, crudErrors.ErrLockOutsideTransaction
+	}
+
+	var acquired bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", advisoryKey(key)).Scan(&acquired).Error; err != nil {
+		return false, fmt.Errorf("failed to try advisory lock '%s': %w", key, err)
+	}
+	return acquired, nil
+}
+
+// WithAdvisoryLock runs fn in a new transaction that holds the advisory lock for the given key
+func WithAdvisoryLock(db *gorm.DB, key string, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := AdvisoryLock(tx, key); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
@@ -0,0 +1,14 @@
+package pagination
+
+// CountMode controls how the total count of a paginated query is computed
+type CountMode int
+
+const (
+	// CountExact runs a full COUNT over the filtered query
+	CountExact CountMode = iota
+	// CountEstimated uses table statistics for unfiltered queries and a capped count otherwise
+	CountEstimated
+)
+
+// EstimatedCountCap is the number of rows a capped count stops at
+const EstimatedCountCap = 10000
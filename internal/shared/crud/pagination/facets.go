@@ -0,0 +1,24 @@
+package pagination
+
+// FacetCount is the number of matching rows for a single value of a column
+type FacetCount struct {
+	Value interface{} `json:"value"`
+	Count int64       `json:"count"`
+}
+
+// Facets maps a column name to the value counts of that column
+type Facets map[string][]FacetCount
+
+// FacetedResult is a paginated result with facet counts for the same filtered query
+type FacetedResult[T any] struct {
+	PaginatedResult[T]
+	Facets Facets `json:"facets"`
+}
+
+// NewFacetedResult attaches facet counts to a paginated result
+func NewFacetedResult[T any](result *PaginatedResult[T], facets Facets) *FacetedResult[T] {
+	return &FacetedResult[T]{
+		PaginatedResult: *result,
+		Facets:          facets,
+	}
+}
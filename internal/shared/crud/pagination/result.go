@@ -9,6 +9,7 @@ type PaginatedResult[T any] struct {
 	TotalPages int   `json:"total_pages"` // Total number of pages
 	HasNext    bool  `json:"has_next"`    // Whether there are more pages after this one
 	HasPrev    bool  `json:"has_prev"`    // Whether there are pages before this one
+	Estimated  bool  `json:"estimated"`   // Whether TotalCount is an estimate, see CountEstimated
 }
 
 // NewPaginatedResult creates a new paginated result from the given parameters
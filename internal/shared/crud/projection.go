@@ -0,0 +1,18 @@
+package crud
+
+import (
+	"konsultn-api/internal/shared/crud/builder"
+	"konsultn-api/internal/shared/crud/pagination"
+)
+
+// Project returns a projection of the query onto the view struct V
+// Rows are scanned straight into V using its `db`/`mapstructure` tags,
+// so joined and aggregated columns need no intermediate map decoding
+func Project[T any, V any](qb QueryBuilder[T]) Projection[V] {
+	return builder.NewProjection[T, V](qb)
+}
+
+// PaginateInto paginates the query and scans the current page into the view struct V
+func PaginateInto[V any, T any](qb QueryBuilder[T]) (*pagination.PaginatedResult[V], error) {
+	return Project[T, V](qb).Paginate()
+}
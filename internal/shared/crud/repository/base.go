@@ -44,6 +44,14 @@ func (r *BaseRepository[T, ID]) WithContext(ctx context.Context) types.Repositor
 	return repo
 }
 
+// WithTx creates a copy of the repository bound to the given transaction
+// Queries built from the copy may use row locks such as ForUpdate
+func (r *BaseRepository[T, ID]) WithTx(tx *gorm.DB) types.Repository[T, ID] {
+	repo := r.Clone()
+	repo.SetDB(tx)
+	return repo
+}
+
 // Select creates a new BaseRepository instance with specified fields to be selected in queries
 // It returns a new BaseRepository instance with the modified database query
 func (r *BaseRepository[T, ID]) Select(fields []string) types.Repository[T, ID] {
@@ -185,12 +193,18 @@ func (r *BaseRepository[T, ID]) SaveAll(models []*T) error {
 }
 
 // Updates specific fields of a model using the provided update map
+// JSONSet values are applied as partial jsonb_set updates of their column
 // It returns any error encountered during the operation
 func (r *BaseRepository[T, ID]) Updates(model *T, m types.UpdateMap) error {
 	if err := m.Valid(); err != nil {
 		return err
 	}
-	return r.db.Model(model).Updates(m).Error
+
+	updates, err := m.Resolve()
+	if err != nil {
+		return err
+	}
+	return r.db.Model(model).Updates(updates).Error
 }
 
 // UpsertOnlyColumns performs an upsert operation with specified conflict and update columns
@@ -26,6 +26,13 @@ type (
 	Query[T any]        = types.QueryBuilder[T]
 	JoinBuilder         = types.JoinBuilder
 	QueryBuilder[T any] = types.QueryBuilder[T]
+	Projection[V any]   = types.Projection[V]
+	Column              = types.Column
+	Subquery            = types.Subquery
+
+	Facets               = pagination.Facets
+	FacetCount           = pagination.FacetCount
+	FacetedResult[T any] = pagination.FacetedResult[T]
 )
 
 func ConvertPaginated[To any](
@@ -0,0 +1,8 @@
+package types
+
+// Number is the set of numeric types accepted by typed aggregate terminals
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
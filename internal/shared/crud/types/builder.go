@@ -20,6 +20,11 @@ type JoinBuilder interface {
 	GetParams() []interface{}
 }
 
+// Subquery is any query builder that can be embedded into another query, regardless of its model
+type Subquery interface {
+	G(scoped bool) *gorm.DB
+}
+
 type QueryBuilder[T any] interface {
 	// G Core/Base Methods
 	G(scoped bool) *gorm.DB
@@ -30,26 +35,39 @@ type QueryBuilder[T any] interface {
 	Select(fields ...interface{}) QueryBuilder[T]
 
 	// Where Conditions
-	Where(field string, value interface{}) QueryBuilder[T]
-	WhereNot(field string, value interface{}) QueryBuilder[T]
-	WhereLT(field string, value interface{}) QueryBuilder[T]
-	WhereLTE(field string, value interface{}) QueryBuilder[T]
-	WhereGT(field string, value interface{}) QueryBuilder[T]
-	WhereGTE(field string, value interface{}) QueryBuilder[T]
-	WhereIN(field string, values interface{}) QueryBuilder[T]
-	WhereNotIN(field string, values interface{}) QueryBuilder[T]
-	WhereBetween(field string, min, max interface{}) QueryBuilder[T]
+	Where(field Column, value interface{}) QueryBuilder[T]
+	WhereNot(field Column, value interface{}) QueryBuilder[T]
+	WhereLT(field Column, value interface{}) QueryBuilder[T]
+	WhereLTE(field Column, value interface{}) QueryBuilder[T]
+	WhereGT(field Column, value interface{}) QueryBuilder[T]
+	WhereGTE(field Column, value interface{}) QueryBuilder[T]
+	WhereIN(field Column, values interface{}) QueryBuilder[T]
+	WhereNotIN(field Column, values interface{}) QueryBuilder[T]
+	WhereBetween(field Column, min, max interface{}) QueryBuilder[T]
+	WhereNull(field Column) QueryBuilder[T]
+	WhereNotNull(field Column) QueryBuilder[T]
 	WhereRaw(sql string, args ...interface{}) QueryBuilder[T]
 	WhereGroup(callback func(QueryBuilder[T])) QueryBuilder[T]
 
+	// Relationship Conditions, resolved from the GORM schema
+	WhereHas(relation string, callback func(QueryBuilder[any])) QueryBuilder[T]
+	WhereDoesntHave(relation string, callback func(QueryBuilder[any])) QueryBuilder[T]
+	WithCount(relation string, callback func(QueryBuilder[any])) QueryBuilder[T]
+
+	// JSONB Conditions
+	WhereJSONContains(field Column, value interface{}) QueryBuilder[T]
+	WhereJSONPath(path Column, op string, value interface{}) QueryBuilder[T]
+	WhereJSONHasKey(field Column, key string) QueryBuilder[T]
+	WhereJSONArrayLength(field Column, op string, length int) QueryBuilder[T]
+
 	// OrWhere Conditions
-	OrWhere(field string, value interface{}) QueryBuilder[T]
-	OrWhereNot(field string, value interface{}) QueryBuilder[T]
-	OrWhereGTE(field string, value interface{}) QueryBuilder[T]
-	OrWhereLTE(field string, value interface{}) QueryBuilder[T]
-	OrWhereIN(field string, value interface{}) QueryBuilder[T]
-	OrWhereNotIN(field string, value interface{}) QueryBuilder[T]
-	OrWhereBetween(field string, min, max interface{}) QueryBuilder[T]
+	OrWhere(field Column, value interface{}) QueryBuilder[T]
+	OrWhereNot(field Column, value interface{}) QueryBuilder[T]
+	OrWhereGTE(field Column, value interface{}) QueryBuilder[T]
+	OrWhereLTE(field Column, value interface{}) QueryBuilder[T]
+	OrWhereIN(field Column, value interface{}) QueryBuilder[T]
+	OrWhereNotIN(field Column, value interface{}) QueryBuilder[T]
+	OrWhereBetween(field Column, min, max interface{}) QueryBuilder[T]
 	OrWhereRaw(sql string, args ...interface{}) QueryBuilder[T]
 	OrWhereGroup(callback func(QueryBuilder[T])) QueryBuilder[T]
 
@@ -58,32 +76,55 @@ type QueryBuilder[T any] interface {
 	LeftJoin(table string, opts ...string) QueryBuilder[T]
 	RightJoin(table string, opts ...string) QueryBuilder[T]
 	CrossJoin(table string, opts ...string) QueryBuilder[T]
+	JoinRelation(path string) QueryBuilder[T]
+	LeftJoinRelation(path string) QueryBuilder[T]
+	RelationAlias(path string) string
+	JoinLateral(subquery Subquery, alias string) QueryBuilder[T]
+	LeftJoinLateral(subquery Subquery, alias string) QueryBuilder[T]
+	From(alias string) QueryBuilder[T]
 	On(left, right string) QueryBuilder[T]
 	OnGroup(builder func(joinBuilder JoinBuilder)) QueryBuilder[T]
 
+	// Set Operations, combining the rows of compatible queries
+	Union(others ...Subquery) QueryBuilder[T]
+	UnionAll(others ...Subquery) QueryBuilder[T]
+	Intersect(others ...Subquery) QueryBuilder[T]
+	Except(others ...Subquery) QueryBuilder[T]
+
 	//RawSelect +
 	RawSelect(expression string, alias string, args ...interface{}) QueryBuilder[T]
 	Now() RawValue
 	Cast(value interface{}, dataType string) RawValue
-	Coalesce(fields []string, defaultValue ...interface{}) RawValue
+	Coalesce(fields []Column, defaultValue ...interface{}) RawValue
 
 	// GroupBy and Having Clauses
-	GroupBy(fields ...string) QueryBuilder[T]
+	GroupBy(fields ...Column) QueryBuilder[T]
 	Having(condition string, args ...interface{}) QueryBuilder[T]
-	HavingEQ(field string, value interface{}) QueryBuilder[T]
-	HavingNEQ(field string, value interface{}) QueryBuilder[T]
-	HavingGT(field string, value interface{}) QueryBuilder[T]
-	HavingGTE(field string, value interface{}) QueryBuilder[T]
-	HavingLT(field string, value interface{}) QueryBuilder[T]
-	HavingLTE(field string, value interface{}) QueryBuilder[T]
-	HavingIN(field string, values interface{}) QueryBuilder[T]
-	HavingBetween(field string, min, max interface{}) QueryBuilder[T]
+	HavingEQ(field Column, value interface{}) QueryBuilder[T]
+	HavingNEQ(field Column, value interface{}) QueryBuilder[T]
+	HavingGT(field Column, value interface{}) QueryBuilder[T]
+	HavingGTE(field Column, value interface{}) QueryBuilder[T]
+	HavingLT(field Column, value interface{}) QueryBuilder[T]
+	HavingLTE(field Column, value interface{}) QueryBuilder[T]
+	HavingIN(field Column, values interface{}) QueryBuilder[T]
+	HavingBetween(field Column, min, max interface{}) QueryBuilder[T]
 	OrHaving(condition string, args ...interface{}) QueryBuilder[T]
 	HavingGroup(callback func(QueryBuilder[T])) QueryBuilder[T]
 	OrHavingGroup(callback func(QueryBuilder[T])) QueryBuilder[T]
 
+	// Row Locking (only valid inside a transaction)
+	ForUpdate() QueryBuilder[T]
+	ForShare() QueryBuilder[T]
+	NoWait() QueryBuilder[T]
+	SkipLocked() QueryBuilder[T]
+
+	// Ordering and limits of the query itself
+	OrderBy(field Column, direction string) QueryBuilder[T]
+	Limit(limit int) QueryBuilder[T]
+
 	// WithPageParams And Paginate Pagination
-	WithPageParams(params pagination.QueryParams) QueryBuilder[T]
+	WithPageParams(params pagination.QueryParams, sortable ...Column) QueryBuilder[T]
+	WithEstimatedCount() QueryBuilder[T]
 	Paginate() (*pagination.PaginatedResult[T], error)
 	PaginateMap() (pagination.PaginatedResult[map[string]interface{}], error)
 
@@ -96,4 +137,5 @@ type QueryBuilder[T any] interface {
 
 	Count() (int64, error)
 	Exists() (bool, error)
+	Facets(columns ...Column) (pagination.Facets, error)
 }
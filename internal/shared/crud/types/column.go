@@ -0,0 +1,49 @@
+package types
+
+import "strings"
+
+// Column is a reference to a table column, e.g. "teams.id" or "role"
+// Query builder methods take columns instead of plain strings so that the constants
+// generated by columngen can be used, while string literals keep working as before:
+//
+//	qb.Where(model.ColTeamMemberRole, "admin").GroupBy(model.ColTeamID)
+//
+// Go converts untyped string constants implicitly, so a literal such as "role" still compiles
+// after the column is renamed. Only the generated constants break the build, prefer them
+// wherever the model has one. String variables must be converted explicitly with Column(name)
+type Column string
+
+// String returns the column reference as written, qualified or not
+func (c Column) String() string {
+	return string(c)
+}
+
+// Name returns the column name without its table
+func (c Column) Name() string {
+	if i := strings.LastIndex(string(c), "."); i >= 0 {
+		return string(c)[i+1:]
+	}
+	return string(c)
+}
+
+// Table returns the table or alias qualifying the column, or an empty string
+func (c Column) Table() string {
+	if i := strings.LastIndex(string(c), "."); i >= 0 {
+		return string(c)[:i]
+	}
+	return ""
+}
+
+// In qualifies the column with another table alias, e.g. ColTeamMemberUserID.In("tm_user")
+func (c Column) In(alias string) Column {
+	return Column(alias + "." + c.Name())
+}
+
+// Columns converts columns to their string references
+func Columns(columns ...Column) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = string(c)
+	}
+	return names
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+)
+
+// JSONSet is an UpdateMap value that replaces a single path inside a JSONB column
+// using jsonb_set instead of overwriting the whole document
+// The last key is created when missing, intermediate keys must already exist
+//
+//	UpdateMap{"settings": JSONSet{Path: []string{"notifications", "email"}, Value: false}}
+type JSONSet struct {
+	Path  []string
+	Value interface{}
+}
+
+// JSONSets applies several JSONSet updates to the same JSONB column, in order
+type JSONSets []JSONSet
+
+// ToJSONB encodes a Go value as a JSONB literal
+// datatypes.JSON and json.RawMessage are used as-is, everything else is marshaled
+func ToJSONB(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case datatypes.JSON:
+		return string(v), nil
+	case json.RawMessage:
+		return string(v), nil
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %T as jsonb: %w", value, err)
+	}
+	return string(b), nil
+}
+
+// TextArray renders keys as a PostgreSQL text[] literal such as {settings,"a,b"}
+func TextArray(keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		key = strings.ReplaceAll(key, `\`, `\\`)
+		key = strings.ReplaceAll(key, `"`, `\"`)
+		quoted[i] = `"` + key + `"`
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
+
+// jsonSetExpr builds a nested jsonb_set expression for the given column
+func jsonSetExpr(column string, sets JSONSets) (clause.Expr, error) {
+	sql := "COALESCE(?, '{}'::jsonb)"
+	vars := []interface{}{clause.Column{Name: column}}
+
+	for _, set := range sets {
+		if len(set.Path) == 0 {
+			return clause.Expr{}, fmt.Errorf("jsonb_set on '%s' requires a non-empty path", column)
+		}
+
+		value, err := ToJSONB(set.Value)
+		if err != nil {
+			return clause.Expr{}, err
+		}
+
+		sql = fmt.Sprintf("jsonb_set(%s, ?::text[], ?::jsonb, true)", sql)
+		vars = append(vars, TextArray(set.Path), value)
+	}
+
+	return gorm.Expr(sql, vars...), nil
+}
+
+// Resolve converts the update map into values GORM can apply directly
+// JSONSet and JSONSets values become jsonb_set expressions on their column
+func (m UpdateMap) Resolve() (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(m))
+
+	for key, value := range m {
+		var sets JSONSets
+		switch v := value.(type) {
+		case JSONSet:
+			sets = JSONSets{v}
+		case JSONSets:
+			sets = v
+		default:
+			resolved[key] = value
+			continue
+		}
+
+		expr, err := jsonSetExpr(key, sets)
+		if err != nil {
+			return nil, err
+		}
+		resolved[key] = expr
+	}
+
+	return resolved, nil
+}
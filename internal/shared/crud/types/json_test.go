@@ -0,0 +1,54 @@
+package types_test
+
+import (
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"konsultn-api/internal/shared/crud/types"
+	"strings"
+	"testing"
+)
+
+// document is a row with a JSONB column
+type document struct {
+	ID     string `gorm:"primaryKey"`
+	Title  string
+	Fields []byte `gorm:"type:jsonb"`
+}
+
+func TestTextArray(t *testing.T) {
+	if got := types.TextArray([]string{"settings", `a,"b"`}); got != `{"settings","a,\"b\""}` {
+		t.Fatalf("TextArray = %s", got)
+	}
+}
+
+func TestUpdateMapResolve(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1 sslmode=disable"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := types.UpdateMap{
+		"fields": types.JSONSets{{Path: []string{"a"}, Value: 1}, {Path: []string{"b", "c"}, Value: "x"}},
+		"title":  "t",
+	}.Resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&document{ID: "1"}).Updates(resolved)
+	})
+	want := `"fields"=jsonb_set(jsonb_set(COALESCE("fields", '{}'::jsonb), '{"a"}'::text[], '1'::jsonb, true), '{"b","c"}'::text[], '"x"'::jsonb, true)`
+	if !strings.Contains(sql, want) || !strings.Contains(sql, `"title"='t'`) {
+		t.Fatalf("expected %s in\n%s", want, sql)
+	}
+}
+
+func TestUpdateMapResolveEmptyPath(t *testing.T) {
+	if _, err := (types.UpdateMap{"fields": types.JSONSet{Value: 1}}).Resolve(); err == nil {
+		t.Fatal("expected an error for a JSONSet without path")
+	}
+}
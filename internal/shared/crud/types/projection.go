@@ -0,0 +1,14 @@
+package types
+
+import (
+	"konsultn-api/internal/shared/crud/pagination"
+)
+
+// Projection scans the rows of a query directly into a view struct V.
+// Columns are matched against the `db` or `mapstructure` tags of V.
+type Projection[V any] interface {
+	Columns() []string
+	First() (*V, error)
+	All() ([]V, error)
+	Paginate() (*pagination.PaginatedResult[V], error)
+}
@@ -11,6 +11,7 @@ type iCRUDRepository[T any, ID comparable] interface {
 	GetTableName() string
 	Select(fields []string) Repository[T, ID]
 	Clone() Repository[T, ID]
+	WithTx(tx *gorm.DB) Repository[T, ID]
 	FindAll() ([]*T, error)
 	Count() (int64, error)
 	Query() QueryBuilder[T]
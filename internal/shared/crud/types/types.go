@@ -11,7 +11,7 @@ type gormDatatype interface {
 
 // UpdateType is a union of acceptable update field types.
 type UpdateType interface {
-	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | string | gormDatatype
+	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | string | gormDatatype | JSONSet | JSONSets
 }
 
 // UpdateMap defines fields and their new values for updating.
@@ -4,17 +4,21 @@ import (
 	"fmt"
 	"gorm.io/datatypes"
 	"reflect"
+	"time"
 )
 
 // Validates field types in the update map.
+// A nil value sets the column to NULL
 func (m UpdateMap) valid() error {
 	for key, value := range m {
 		switch value.(type) {
 		case int, int8, int16, int32, int64,
 			uint, uint8, uint16, uint32, uint64,
-			string,
+			float32, float64, bool, string,
+			time.Time, *time.Time, nil,
 			datatypes.JSON, datatypes.Date, datatypes.Time,
-			datatypes.JSONSlice[any], datatypes.JSONType[any]:
+			datatypes.JSONSlice[any], datatypes.JSONType[any],
+			JSONSet, JSONSets:
 			continue
 		default:
 			return fmt.Errorf("invalid type for key '%s': %v (type: %s)", key, value, reflect.TypeOf(value).Name())
@@ -0,0 +1,83 @@
+package ids
+
+import (
+	"crypto/rand"
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+	"sync"
+	"time"
+)
+
+// Strategy generates and validates primary keys for a model
+type Strategy interface {
+	// Name identifies the strategy, e.g. "ulid" or "uuidv7"
+	Name() string
+	// New returns a new identifier, safe for concurrent use
+	New() string
+	// Valid reports whether s is a well-formed identifier of this strategy
+	Valid(s string) bool
+}
+
+// Process-wide generators shared by every model
+var (
+	ULID   Strategy = newULIDStrategy()
+	UUIDv7 Strategy = uuidV7Strategy{}
+)
+
+// ulidStrategy generates monotonic ULIDs from crypto/rand entropy
+// IDs created within the same millisecond increment the entropy, so they never
+// collide and always sort in creation order, even if the wall clock steps back
+type ulidStrategy struct {
+	mu      sync.Mutex
+	entropy *ulid.MonotonicEntropy
+	lastMs  uint64
+}
+
+func newULIDStrategy() *ulidStrategy {
+	return &ulidStrategy{entropy: ulid.Monotonic(rand.Reader, 0)}
+}
+
+func (s *ulidStrategy) Name() string {
+	return "ulid"
+}
+
+func (s *ulidStrategy) New() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := ulid.Timestamp(time.Now())
+	if ms < s.lastMs {
+		ms = s.lastMs
+	}
+
+	id, err := ulid.New(ms, s.entropy)
+	if err != nil {
+		// The entropy of this millisecond is exhausted, move on to the next one
+		ms++
+		id = ulid.MustNew(ms, s.entropy)
+	}
+
+	s.lastMs = ms
+	return id.String()
+}
+
+func (s *ulidStrategy) Valid(id string) bool {
+	_, err := ulid.ParseStrict(id)
+	return err == nil
+}
+
+// uuidV7Strategy generates time ordered RFC 9562 version 7 UUIDs
+type uuidV7Strategy struct{}
+
+func (uuidV7Strategy) Name() string {
+	return "uuidv7"
+}
+
+func (uuidV7Strategy) New() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+func (uuidV7Strategy) Valid(id string) bool {
+	parsed, err := uuid.Parse(id)
+	return err == nil && parsed.Version() == 7 && len(id) == 36
+}
@@ -0,0 +1,63 @@
+package ids_test
+
+import (
+	"konsultn-api/internal/shared/ids"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestULIDUniqueAcrossGoroutines(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		seen = map[string]bool{}
+	)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10000; i++ {
+				id := ids.ULID.New()
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("duplicate ULID %s", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestULIDSortsInCreationOrder(t *testing.T) {
+	sequence := make([]string, 1000)
+	for i := range sequence {
+		sequence[i] = ids.ULID.New()
+	}
+	if !sort.StringsAreSorted(sequence) {
+		t.Fatal("ULIDs created in a row are not sorted")
+	}
+}
+
+func TestValid(t *testing.T) {
+	ulid, uuid := ids.ULID.New(), ids.UUIDv7.New()
+
+	cases := []struct {
+		strategy ids.Strategy
+		id       string
+		valid    bool
+	}{
+		{ids.ULID, ulid, true},
+		{ids.ULID, "abc", false},
+		{ids.ULID, uuid, false},
+		{ids.UUIDv7, uuid, true},
+		{ids.UUIDv7, ulid, false},
+	}
+	for _, c := range cases {
+		if got := c.strategy.Valid(c.id); got != c.valid {
+			t.Errorf("%s.Valid(%q) = %v, want %v", c.strategy.Name(), c.id, got, c.valid)
+		}
+	}
+}
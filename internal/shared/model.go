@@ -1,21 +1,30 @@
 package shared
 
 import (
-	"github.com/oklog/ulid/v2"
 	"gorm.io/gorm"
-	"math/rand"
-	"time"
+	"konsultn-api/internal/shared/ids"
 )
 
+// ULID is an embeddable primary key generated with the process-wide monotonic ULID generator
 type ULID struct {
 	ID string `gorm:"primaryKey" json:"id"`
 }
 
 func (m *ULID) BeforeCreate(tx *gorm.DB) (err error) {
 	if m.ID == "" {
-		t := time.Now()
-		entropy := rand.New(rand.NewSource(t.UnixNano()))
-		m.ID = ulid.MustNew(ulid.Timestamp(t), entropy).String()
+		m.ID = ids.ULID.New()
+	}
+	return
+}
+
+// UUIDv7 is an embeddable primary key holding a time ordered version 7 UUID
+type UUIDv7 struct {
+	ID string `gorm:"type:uuid;primaryKey" json:"id"`
+}
+
+func (m *UUIDv7) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == "" {
+		m.ID = ids.UUIDv7.New()
 	}
 	return
 }
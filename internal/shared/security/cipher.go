@@ -0,0 +1,67 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrDecrypt is returned for ciphertexts that were not encrypted with the key of the cipher or were tampered with
+var ErrDecrypt = errors.New("failed to decrypt")
+
+// Cipher encrypts secrets kept at rest, like the TOTP secrets of users, with AES-256-GCM
+// Every value is bound to the purpose it was encrypted for and only decrypts for that purpose,
+// so values sealed for one use of the key cannot be passed off as another
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher returns a cipher for a base64 encoded 32 byte key
+func NewCipher(key string) (*Cipher, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("the encryption key is not valid base64: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("the encryption key must be 32 bytes, got %d", len(raw))
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt returns the base64 encoded nonce and ciphertext of plaintext, bound to purpose
+func (c *Cipher) Encrypt(purpose string, plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), []byte(purpose))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of a value returned by Encrypt for the same purpose
+func (c *Cipher) Decrypt(purpose string, ciphertext string) (string, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+
+	size := c.aead.NonceSize()
+	plaintext, err := c.aead.Open(nil, sealed[:size], sealed[size:], []byte(purpose))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
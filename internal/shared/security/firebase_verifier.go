@@ -0,0 +1,25 @@
+package security
+
+import (
+	"context"
+	"firebase.google.com/go/v4/auth"
+	"fmt"
+)
+
+// FirebaseVerifier verifies Firebase ID tokens
+type FirebaseVerifier struct {
+	client *auth.Client
+}
+
+// NewFirebaseVerifier returns a verifier checking tokens against the Firebase project of client
+func NewFirebaseVerifier(client *auth.Client) *FirebaseVerifier {
+	return &FirebaseVerifier{client: client}
+}
+
+func (v *FirebaseVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	verified, err := v.client.VerifyIDToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return principalFromClaims(verified.UID, verified.Claims), nil
+}
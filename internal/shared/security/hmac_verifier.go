@@ -0,0 +1,66 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
+	"time"
+)
+
+// HMACVerifier verifies HS256 tokens signed with a static shared secret
+// It is meant for local development and tests, where it can also issue the tokens with Sign
+type HMACVerifier struct {
+	secret   []byte
+	issuer   string
+	audience string
+}
+
+// NewHMACVerifier returns a verifier for tokens signed with secret
+// Issuer and audience are checked, and set on signed tokens, when they are not empty
+func NewHMACVerifier(secret []byte, issuer, audience string) *HMACVerifier {
+	return &HMACVerifier{secret: secret, issuer: issuer, audience: audience}
+}
+
+func (v *HMACVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	return parseJWT(token, []string{jwt.SigningMethodHS256.Alg()}, v.issuer, v.audience, func(*jwt.Token) (interface{}, error) {
+		return v.secret, nil
+	})
+}
+
+// Sign issues a token for the principal, valid for ttl
+// Extra claims of the principal are copied into the token
+func (v *HMACVerifier) Sign(principal Principal, ttl time.Duration) (string, error) {
+	if principal.UID == "" {
+		return "", errors.New("cannot sign a token without a subject")
+	}
+
+	claims := jwt.MapClaims{}
+	for name, value := range principal.Claims {
+		claims[name] = value
+	}
+
+	now := time.Now()
+	claims["sub"] = principal.UID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+	if v.issuer != "" {
+		claims["iss"] = v.issuer
+	}
+	if v.audience != "" {
+		claims["aud"] = v.audience
+	}
+	if principal.UserID != "" {
+		claims[ClaimUserID] = principal.UserID
+	}
+	if principal.Email != "" {
+		claims[ClaimEmail] = principal.Email
+	}
+	if len(principal.Roles) > 0 {
+		claims[ClaimRoles] = principal.Roles
+	}
+	if !principal.AuthTime.IsZero() {
+		claims[ClaimAuthTime] = principal.AuthTime.Unix()
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(v.secret)
+}
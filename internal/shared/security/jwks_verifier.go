@@ -0,0 +1,182 @@
+package security
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksRefreshInterval is how long fetched keys are trusted before the set is fetched again
+	jwksRefreshInterval = time.Hour
+	// jwksMinRefetch limits refetches caused by tokens signed with an unknown key
+	jwksMinRefetch = time.Minute
+)
+
+// JWKSVerifier verifies RS256 tokens against the keys of a JSON Web Key Set
+// The set is fetched lazily, cached, and fetched again when a token names an unknown key, so keys
+// can be rotated by the issuer. This suits self-hosted issuers such as Keycloak or the API itself
+type JWKSVerifier struct {
+	source   string
+	issuer   string
+	audience string
+	client   *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// NewJWKSVerifier returns a verifier for tokens signed by the keys published at source
+// Source is an http(s) URL or the path of a local JWKS file
+func NewJWKSVerifier(source, issuer, audience string) *JWKSVerifier {
+	return &JWKSVerifier{
+		source:   source,
+		issuer:   issuer,
+		audience: audience,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (v *JWKSVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	return parseJWT(token, []string{jwt.SigningMethodRS256.Alg()}, v.issuer, v.audience, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	})
+}
+
+// key returns the public key with the given ID, refreshing the key set when needed
+// Tokens without a key ID are accepted when the set holds a single key
+func (v *JWKSVerifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.RLock()
+	key, found := v.lookup(kid)
+	stale := time.Since(v.fetchedAt) > jwksRefreshInterval
+	canRefetch := time.Since(v.fetchedAt) > jwksMinRefetch
+	v.mu.RUnlock()
+
+	if found && !stale {
+		return key, nil
+	}
+	if !found && !stale && !canRefetch {
+		return nil, fmt.Errorf("unknown signing key '%s'", kid)
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		if found {
+			// Keep using the cached key while the key set is unreachable
+			return key, nil
+		}
+		return nil, err
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if key, found = v.lookup(kid); !found {
+		return nil, fmt.Errorf("unknown signing key '%s'", kid)
+	}
+	return key, nil
+}
+
+func (v *JWKSVerifier) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// jsonWebKey is the subset of RFC 7517 needed for RSA signature keys
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// refresh fetches the key set and replaces the cached keys
+func (v *JWKSVerifier) refresh(ctx context.Context) error {
+	data, err := v.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch key set: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("failed to parse key set: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") || (jwk.Alg != "" && jwk.Alg != "RS256") {
+			continue
+		}
+		key, err := rsaPublicKey(jwk)
+		if err != nil {
+			return fmt.Errorf("invalid key '%s': %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("key set holds no RS256 signing key")
+	}
+
+	v.mu.Lock()
+	v.keys, v.fetchedAt = keys, time.Now()
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *JWKSVerifier) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(v.source, "http://") && !strings.HasPrefix(v.source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(v.source, "file://"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func rsaPublicKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("unsupported exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
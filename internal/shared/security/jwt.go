@@ -0,0 +1,32 @@
+package security
+
+import (
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// parseJWT parses a token signed with one of methods and checks its registered claims
+// Expiry and not-before are always checked, issuer and audience when they are not empty
+func parseJWT(token string, methods []string, issuer, audience string, key jwt.Keyfunc) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods(methods))
+	if _, err := parser.ParseWithClaims(token, claims, key); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("%w: token has no expiry", ErrInvalidToken)
+	}
+	if issuer != "" && !claims.VerifyIssuer(issuer, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if audience != "" && !claims.VerifyAudience(audience, true) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidToken)
+	}
+	return principalFromClaims(subject, claims), nil
+}
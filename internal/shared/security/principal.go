@@ -0,0 +1,85 @@
+package security
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Principal is the authenticated caller of a request, resolved from a verified token
+type Principal struct {
+	// UID is the subject of the token at the identity provider, e.g. the Firebase UID
+	UID string
+	// UserID is the ID of the caller in the users table
+	UserID string
+	Email  string
+	Roles  []string
+	// AuthTime is when the caller signed in, tokens refreshed from the same sign in share it
+	AuthTime time.Time
+	// Claims holds every claim of the token, including the ones mapped above
+	Claims map[string]interface{}
+	// APIKeyID is set when the caller authenticated with an API key, which limits it to Scopes
+	APIKeyID string
+	Scopes   []string
+	// TeamID is the only team an API key scoped to a team may access
+	TeamID string
+}
+
+// HasRole reports whether the principal was granted role
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// HasScope reports whether the principal may act within scope
+// Principals of ID tokens act as their user and have every scope
+func (p *Principal) HasScope(scope string) bool {
+	if p.APIKeyID == "" {
+		return true
+	}
+	return slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal carried by ctx, if any
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// principalFromClaims maps the claims of a verified token to a principal
+// Roles are read from a "roles" array and from a single "role" claim
+func principalFromClaims(subject string, claims map[string]interface{}) *Principal {
+	principal := &Principal{
+		UID:    subject,
+		UserID: stringClaim(claims, ClaimUserID),
+		Email:  stringClaim(claims, ClaimEmail),
+		Claims: claims,
+	}
+
+	if roles, ok := claims[ClaimRoles].([]interface{}); ok {
+		for _, role := range roles {
+			if s, ok := role.(string); ok && s != "" {
+				principal.Roles = append(principal.Roles, s)
+			}
+		}
+	}
+	if role := stringClaim(claims, ClaimRole); role != "" && !principal.HasRole(role) {
+		principal.Roles = append(principal.Roles, role)
+	}
+	if authTime, ok := claims[ClaimAuthTime].(float64); ok {
+		principal.AuthTime = time.Unix(int64(authTime), 0)
+	}
+
+	return principal
+}
+
+func stringClaim(claims map[string]interface{}, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
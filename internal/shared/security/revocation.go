@@ -0,0 +1,39 @@
+package security
+
+import (
+	"context"
+	"fmt"
+)
+
+// RevocationChecker reports whether the session a principal signed in with was revoked
+type RevocationChecker interface {
+	Revoked(ctx context.Context, principal *Principal) (bool, error)
+}
+
+// revocationVerifier rejects the tokens of revoked sessions after verifying them
+type revocationVerifier struct {
+	verifier TokenVerifier
+	checker  RevocationChecker
+}
+
+// WithRevocation returns a verifier that also rejects tokens of sessions revoked according to checker
+// Tokens are rejected when the check fails, so an unreachable session store does not let revoked tokens through
+func WithRevocation(verifier TokenVerifier, checker RevocationChecker) TokenVerifier {
+	return &revocationVerifier{verifier: verifier, checker: checker}
+}
+
+func (v *revocationVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	principal, err := v.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := v.checker.Revoked(ctx, principal)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to check revocation: %v", ErrInvalidToken, err)
+	}
+	if revoked {
+		return nil, fmt.Errorf("%w: session was revoked", ErrInvalidToken)
+	}
+	return principal, nil
+}
@@ -0,0 +1,18 @@
+package security
+
+import "slices"
+
+// Platform roles granted to users, independent of their roles in teams
+const (
+	RoleFreelancer    = "freelancer"
+	RoleClient        = "client"
+	RolePlatformAdmin = "platform_admin"
+)
+
+// PlatformRoles lists every platform role
+var PlatformRoles = []string{RoleFreelancer, RoleClient, RolePlatformAdmin}
+
+// IsPlatformRole checks if role is one of PlatformRoles
+func IsPlatformRole(role string) bool {
+	return slices.Contains(PlatformRoles, role)
+}
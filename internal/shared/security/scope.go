@@ -0,0 +1,40 @@
+package security
+
+import (
+	"slices"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, it tells them apart from the ID tokens sent in the same header
+const APIKeyPrefix = "kn_"
+
+// Scopes granted to API keys, callers signed in with an ID token have all of them
+const (
+	ScopeReadTasks     = "read:tasks"
+	ScopeWriteTasks    = "write:tasks"
+	ScopeReadProjects  = "read:projects"
+	ScopeWriteProjects = "write:projects"
+	ScopeReadTeams     = "read:teams"
+	ScopeAdminTeam     = "admin:team"
+)
+
+// Scopes lists every scope
+var Scopes = []string{ScopeReadTasks, ScopeWriteTasks, ScopeReadProjects, ScopeWriteProjects, ScopeReadTeams, ScopeAdminTeam}
+
+// TeamScopes lists the scopes of API keys scoped to a team
+var TeamScopes = []string{ScopeReadTeams, ScopeAdminTeam}
+
+// IsScope checks if scope is one of Scopes
+func IsScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+// IsTeamScope checks if scope is one of TeamScopes
+func IsTeamScope(scope string) bool {
+	return slices.Contains(TeamScopes, scope)
+}
+
+// IsAPIKey reports whether a bearer token is an API key rather than an ID token
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
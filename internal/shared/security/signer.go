@@ -0,0 +1,24 @@
+package security
+
+import (
+	"errors"
+	"time"
+)
+
+// TokenSigner issues tokens that the configured TokenVerifier accepts
+type TokenSigner interface {
+	// Sign issues a token for the principal, valid for ttl
+	Sign(principal Principal, ttl time.Duration) (string, error)
+}
+
+// NewTokenSigner builds the signer matching the configured verifier
+// Only the HMAC verifier holds the key needed to issue tokens, the other verifiers only know public keys
+func NewTokenSigner(cfg Config) (TokenSigner, error) {
+	if cfg.Verifier != VerifierHMAC {
+		return nil, errors.New("tokens can only be issued with the hmac token verifier")
+	}
+	if cfg.HMACSecret == "" {
+		return nil, errors.New("the hmac token verifier requires AUTH_HMAC_SECRET")
+	}
+	return NewHMACVerifier([]byte(cfg.HMACSecret), cfg.Issuer, cfg.Audience), nil
+}
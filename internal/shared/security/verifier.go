@@ -0,0 +1,91 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"firebase.google.com/go/v4/auth"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Claims mapped to the principal
+const (
+	ClaimUserID = "userId"
+	ClaimEmail  = "email"
+	ClaimRole   = "role"
+	ClaimRoles  = "roles"
+	// ClaimAuthTime is the sign in time, as set by Firebase
+	ClaimAuthTime = "auth_time"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, expired or not signed by a trusted key
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenVerifier verifies bearer tokens and resolves the principal they were issued to
+type TokenVerifier interface {
+	// Verify checks the signature and validity of a token
+	// Returns an error wrapping ErrInvalidToken when the token is rejected
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+// Token verifiers selectable by configuration
+const (
+	VerifierFirebase = "firebase"
+	VerifierJWKS     = "jwks"
+	VerifierHMAC     = "hmac"
+)
+
+// Config selects and configures the token verifier
+type Config struct {
+	// Verifier is one of VerifierFirebase, VerifierJWKS or VerifierHMAC
+	Verifier string
+	// JWKSURL is the URL or file path of the JSON Web Key Set of the JWKS verifier
+	JWKSURL string
+	// Issuer and Audience are checked by the JWKS and HMAC verifiers when set
+	Issuer   string
+	Audience string
+	// HMACSecret is the shared secret of the HMAC verifier
+	HMACSecret string
+}
+
+// ConfigFromEnv reads the verifier configuration from the environment:
+// AUTH_TOKEN_VERIFIER (firebase by default), AUTH_JWKS_URL, AUTH_ISSUER, AUTH_AUDIENCE and AUTH_HMAC_SECRET
+func ConfigFromEnv() Config {
+	verifier := strings.ToLower(os.Getenv("AUTH_TOKEN_VERIFIER"))
+	if verifier == "" {
+		verifier = VerifierFirebase
+	}
+
+	return Config{
+		Verifier:   verifier,
+		JWKSURL:    os.Getenv("AUTH_JWKS_URL"),
+		Issuer:     os.Getenv("AUTH_ISSUER"),
+		Audience:   os.Getenv("AUTH_AUDIENCE"),
+		HMACSecret: os.Getenv("AUTH_HMAC_SECRET"),
+	}
+}
+
+// NewTokenVerifier builds the verifier selected by the configuration
+// The Firebase client is only used, and required, by the Firebase verifier
+func NewTokenVerifier(cfg Config, client *auth.Client) (TokenVerifier, error) {
+	switch cfg.Verifier {
+	case VerifierFirebase:
+		if client == nil {
+			return nil, errors.New("the firebase token verifier requires an initialized firebase auth client")
+		}
+		return NewFirebaseVerifier(client), nil
+	case VerifierJWKS:
+		if cfg.JWKSURL == "" {
+			return nil, errors.New("the jwks token verifier requires AUTH_JWKS_URL")
+		}
+		return NewJWKSVerifier(cfg.JWKSURL, cfg.Issuer, cfg.Audience), nil
+	case VerifierHMAC:
+		if cfg.HMACSecret == "" {
+			return nil, errors.New("the hmac token verifier requires AUTH_HMAC_SECRET")
+		}
+		return NewHMACVerifier([]byte(cfg.HMACSecret), cfg.Issuer, cfg.Audience), nil
+	default:
+		return nil, fmt.Errorf("unknown token verifier '%s'", cfg.Verifier)
+	}
+}
@@ -0,0 +1,102 @@
+// Package sensitive keeps models holding secrets from being rendered directly
+//
+// Fields holding secrets are tagged `sensitive:"true"` and their model marshals itself through Marshal:
+//
+//	func (u User) MarshalJSON() ([]byte, error) {
+//		type plain User
+//		return sensitive.Marshal(plain(u))
+//	}
+//
+// Handlers are expected to render response views instead. Should a model be rendered anyway its sensitive
+// fields are left out, unless strict mode is on, in which case rendering fails. Tests turn strict mode on,
+// so a handler rendering a sensitive model fails them instead of leaking in production
+package sensitive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Tag marks the fields of a model that must never be rendered
+const Tag = "sensitive"
+
+// ErrRendered is returned when a sensitive model is rendered in strict mode
+var ErrRendered = errors.New("sensitive model rendered directly, render a response view instead")
+
+var strict atomic.Bool
+
+// SetStrict makes rendering sensitive models fail instead of leaving their sensitive fields out
+func SetStrict(enabled bool) {
+	strict.Store(enabled)
+}
+
+// Marshal encodes a model without its sensitive fields, or fails with ErrRendered in strict mode
+// The model is usually a conversion of the sensitive type to a type without its MarshalJSON method
+func Marshal(model interface{}) ([]byte, error) {
+	t := reflect.TypeOf(model)
+	if strict.Load() {
+		return nil, fmt.Errorf("%w: %s", ErrRendered, t)
+	}
+	log.Printf("sensitive model %s rendered directly, its sensitive fields were left out", t)
+
+	encoded, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	keys := Keys(t)
+	if len(keys) == 0 {
+		return encoded, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		delete(fields, key)
+	}
+	return json.Marshal(fields)
+}
+
+var keysCache sync.Map // reflect.Type -> []string
+
+// Keys returns the JSON keys of the sensitive fields of a struct type, the ones of embedded structs included
+func Keys(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if cached, ok := keysCache.Load(t); ok {
+		return cached.([]string)
+	}
+
+	var keys []string
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if field.Anonymous && name == "" {
+				keys = append(keys, Keys(field.Type)...)
+				continue
+			}
+			if field.Tag.Get(Tag) != "true" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			keys = append(keys, name)
+		}
+	}
+
+	keysCache.Store(t, keys)
+	return keys
+}
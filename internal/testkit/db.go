@@ -0,0 +1,177 @@
+// Package testkit provides disposable, migrated databases and model factories for integration tests
+//
+// Every call to DB gets its own schema, created with the same migrations as the API, and a transaction
+// on it that is rolled back when the test ends:
+//
+//	func TestTeamRepository(t *testing.T) {
+//		db := testkit.DB(t)
+//		f := testkit.NewFactory(t, db)
+//		team := f.Team(f.User())
+//
+//		repo := repository.NewTeamRepository(db)
+//		...
+//	}
+//
+// Tests run against the Postgres server in TESTKIT_POSTGRES_DSN when it is set and reachable, and fall
+// back to an in-memory SQLite database otherwise
+//
+// Importing testkit turns on sensitive strict mode, so rendering a sensitive model fails. Handler tests serve
+// their routes with Router, which fails the test when a handler does
+// Packages imported by testkit must use it from external test packages (package foo_test)
+package testkit
+
+import (
+	"fmt"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"konsultn-api/internal/db"
+	"konsultn-api/internal/shared/ids"
+	"konsultn-api/internal/shared/sensitive"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// DSNEnv is the environment variable holding the Postgres DSN used by tests
+const DSNEnv = "TESTKIT_POSTGRES_DSN"
+
+var (
+	postgresOnce sync.Once
+	postgresDB   *gorm.DB
+	postgresDSN  string
+)
+
+func init() {
+	sensitive.SetStrict(true)
+}
+
+// Postgres reports whether tests run against Postgres instead of SQLite
+func Postgres() bool {
+	return admin() != nil
+}
+
+// admin returns the shared connection used to create and drop schemas, or nil when Postgres is unavailable
+func admin() *gorm.DB {
+	postgresOnce.Do(func() {
+		dsn := os.Getenv(DSNEnv)
+		if dsn == "" {
+			return
+		}
+
+		conn, err := gorm.Open(postgres.Open(dsn), config())
+		if err != nil {
+			return
+		}
+		sqlDB, err := conn.DB()
+		if err != nil || sqlDB.Ping() != nil {
+			return
+		}
+		postgresDB, postgresDSN = conn, dsn
+	})
+	return postgresDB
+}
+
+// config returns the GORM configuration of test connections, which only log errors
+func config() *gorm.Config {
+	return &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+}
+
+// DB returns a transaction on an isolated database holding all migrated tables
+// The transaction is rolled back and the database dropped when the test ends
+func DB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	conn := Open(t)
+	tx := conn.Begin()
+	if tx.Error != nil {
+		t.Fatalf("testkit: begin transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+	return tx
+}
+
+// Open returns a connection to an isolated database holding all migrated tables, without a transaction
+// Use it to test code that commits or runs its own transactions, the database is dropped when the test ends
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	var (
+		conn *gorm.DB
+		err  error
+	)
+	if admin() != nil {
+		conn, err = openPostgres(t)
+	} else {
+		conn, err = openSQLite(t)
+	}
+	if err != nil {
+		t.Fatalf("testkit: open database: %v", err)
+	}
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("testkit: migrate: %v", err)
+	}
+	return conn
+}
+
+// AddColumn adds a column that the models no longer have, to test migrations of databases that still hold it
+// The name is quoted, the SQLite migrator rebuilds a table to drop a column and only keeps quoted columns
+func AddColumn(t testing.TB, db *gorm.DB, table string, column string, sqlType string) {
+	t.Helper()
+
+	if err := db.Exec("ALTER TABLE ? ADD COLUMN ? "+sqlType, clause.Table{Name: table}, clause.Column{Name: column}).Error; err != nil {
+		t.Fatalf("testkit: add column %s.%s: %v", table, column, err)
+	}
+}
+
+// openPostgres creates a schema for the test and connects with it as the search path
+func openPostgres(t testing.TB) (*gorm.DB, error) {
+	schema := "testkit_" + strings.ToLower(ids.ULID.New())
+	if err := admin().Exec(fmt.Sprintf("CREATE SCHEMA %q", schema)).Error; err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() {
+		admin().Exec(fmt.Sprintf("DROP SCHEMA IF EXISTS %q CASCADE", schema))
+	})
+
+	conn, err := gorm.Open(postgres.Open(postgresDSN+" search_path="+schema), config())
+	if err != nil {
+		return nil, err
+	}
+	closeOnCleanup(t, conn)
+	return conn, nil
+}
+
+// openSQLite opens a private in-memory SQLite database
+func openSQLite(t testing.TB) (*gorm.DB, error) {
+	name := "testkit_" + strings.ToLower(ids.ULID.New())
+	conn, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared&_pragma=foreign_keys(1)", name)), config())
+	if err != nil {
+		return nil, err
+	}
+
+	// The database lives as long as its connection, a single one also serializes writers
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	closeOnCleanup(t, conn)
+	return conn, nil
+}
+
+// closeOnCleanup closes the connection pool when the test ends
+// Cleanups run last in first out, so the pool closes after the transaction is rolled back
+func closeOnCleanup(t testing.TB, conn *gorm.DB) {
+	t.Cleanup(func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+}
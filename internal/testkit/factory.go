@@ -0,0 +1,129 @@
+package testkit
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	projectModel "konsultn-api/internal/domain/project/model"
+	"konsultn-api/internal/domain/task"
+	"konsultn-api/internal/domain/team/enum"
+	teamModel "konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/domain/user"
+	"testing"
+	"time"
+)
+
+// Factory creates valid records with unique values, tests only set the fields they care about
+//
+//	owner := f.User(func(u *user.User) { u.Status = "ACTIVE" })
+//	team := f.Team(owner)
+//	f.Member(team, f.User(), enum.Admin)
+type Factory struct {
+	t   testing.TB
+	db  *gorm.DB
+	seq int
+}
+
+// NewFactory returns a factory inserting records through db, failing the test on errors
+func NewFactory(t testing.TB, db *gorm.DB) *Factory {
+	return &Factory{t: t, db: db}
+}
+
+// next returns a number unique within the factory
+func (f *Factory) next() int {
+	f.seq++
+	return f.seq
+}
+
+// create applies the overrides to the record and inserts it
+func create[T any](f *Factory, record *T, overrides []func(*T)) *T {
+	f.t.Helper()
+
+	for _, override := range overrides {
+		override(record)
+	}
+	if err := f.db.Create(record).Error; err != nil {
+		f.t.Fatalf("testkit: create %T: %v", record, err)
+	}
+	return record
+}
+
+// User creates a user with a unique UID and email
+func (f *Factory) User(overrides ...func(*user.User)) *user.User {
+	f.t.Helper()
+
+	n := f.next()
+	return create(f, &user.User{
+		UID:       fmt.Sprintf("uid-%d", n),
+		FirstName: "User",
+		LastName:  fmt.Sprint(n),
+		Email:     fmt.Sprintf("user%d@example.com", n),
+		Status:    "ACTIVE",
+	}, overrides)
+}
+
+// Team creates a team owned by owner, with the owner as its first member like the team service does
+func (f *Factory) Team(owner *user.User, overrides ...func(*teamModel.Team)) *teamModel.Team {
+	f.t.Helper()
+
+	n := f.next()
+	team := create(f, &teamModel.Team{
+		Name:      fmt.Sprintf("Team %d", n),
+		Slug:      fmt.Sprintf("team-%d", n),
+		OwnerID:   owner.ID,
+		UpdatedBy: owner.ID,
+	}, overrides)
+
+	f.Member(team, owner, enum.Owner)
+	return team
+}
+
+// Member adds a user to a team with the given role
+func (f *Factory) Member(team *teamModel.Team, member *user.User, role enum.Role, overrides ...func(*teamModel.TeamMember)) *teamModel.TeamMember {
+	f.t.Helper()
+
+	return create(f, &teamModel.TeamMember{
+		TeamID:    team.ID,
+		UserID:    member.ID,
+		Role:      role.String(),
+		UpdatedBy: team.OwnerID,
+	}, overrides)
+}
+
+// Invitation creates a pending member invitation to a team, expiring in a week
+func (f *Factory) Invitation(team *teamModel.Team, from, to *user.User, overrides ...func(*teamModel.TeamInvitation)) *teamModel.TeamInvitation {
+	f.t.Helper()
+
+	expiresAt := time.Now().Add(7 * 24 * time.Hour)
+	return create(f, &teamModel.TeamInvitation{
+		FromUserID: from.ID,
+		ToUserID:   to.ID,
+		TeamID:     team.ID,
+		Status:     enum.Pending.String(),
+		Role:       enum.Member.String(),
+		ExpiresAt:  &expiresAt,
+	}, overrides)
+}
+
+// Project creates a project without tasks
+func (f *Factory) Project(overrides ...func(*projectModel.Project)) *projectModel.Project {
+	f.t.Helper()
+
+	return create(f, &projectModel.Project{
+		Name: fmt.Sprintf("Project %d", f.next()),
+	}, overrides)
+}
+
+// Task creates a task in a project, or a task without a project when project is nil
+func (f *Factory) Task(project *projectModel.Project, overrides ...func(*task.Task)) *task.Task {
+	f.t.Helper()
+
+	n := f.next()
+	record := &task.Task{
+		Title:       fmt.Sprintf("Task %d", n),
+		Description: fmt.Sprintf("Description of task %d", n),
+	}
+	if project != nil {
+		record.ProjectID = &project.ID
+	}
+	return create(f, record, overrides)
+}
@@ -0,0 +1,26 @@
+package testkit
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"konsultn-api/internal/shared/sensitive"
+	"testing"
+)
+
+// Router returns a gin engine for handler tests that fails the test when a handler renders a sensitive model
+// Strict mode makes rendering one fail, but gin only records the failure on the context and still responds
+func Router(t testing.TB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+
+		for _, err := range c.Errors {
+			if errors.Is(err.Err, sensitive.ErrRendered) {
+				t.Errorf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err.Err)
+			}
+		}
+	})
+	return router
+}
@@ -0,0 +1,74 @@
+package testkit_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"konsultn-api/internal/domain/task"
+	"konsultn-api/internal/domain/team/enum"
+	teamModel "konsultn-api/internal/domain/team/model"
+	"konsultn-api/internal/domain/user"
+	"konsultn-api/internal/testkit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFactory(t *testing.T) {
+	db := testkit.DB(t)
+	f := testkit.NewFactory(t, db)
+
+	owner := f.User()
+	team := f.Team(owner)
+	f.Member(team, f.User(), enum.Admin)
+	f.Invitation(team, owner, f.User())
+	project := f.Project()
+	parent := f.Task(project)
+	child := f.Task(project, func(t *task.Task) { t.ParentTaskID = &parent.ID })
+
+	var members int64
+	if err := db.Model(&teamModel.TeamMember{}).Where("team_id = ?", team.ID).Count(&members).Error; err != nil || members != 2 {
+		t.Fatalf("members = %d, %v", members, err)
+	}
+	if child.ProjectID == nil || *child.ProjectID != project.ID {
+		t.Fatalf("task not created in the project: %+v", child)
+	}
+}
+
+func TestDBIsIsolated(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		db := testkit.DB(t)
+		var users int64
+		if err := db.Table("users").Count(&users).Error; err != nil || users != 0 {
+			t.Fatalf("fresh database holds %d users, %v", users, err)
+		}
+		testkit.NewFactory(t, db).User()
+	}
+}
+
+func TestRouterFailsOnSensitiveModels(t *testing.T) {
+	db := testkit.DB(t)
+	account := testkit.NewFactory(t, db).User()
+
+	guarded := &failures{TB: t}
+	router := testkit.Router(guarded)
+	router.GET("/user", func(c *gin.Context) { c.JSON(http.StatusOK, account) })
+	router.GET("/view", func(c *gin.Context) { c.JSON(http.StatusOK, user.ToUserResponse(account)) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/view", nil))
+	if guarded.count != 0 {
+		t.Fatal("expected rendering the user view to pass")
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/user", nil))
+	if guarded.count != 1 {
+		t.Fatal("expected rendering the user model to fail the test")
+	}
+}
+
+// failures counts the failures of a test instead of failing it
+type failures struct {
+	testing.TB
+	count int
+}
+
+func (f *failures) Errorf(string, ...interface{}) {
+	f.count++
+}
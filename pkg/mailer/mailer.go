@@ -0,0 +1,93 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Mailers selectable by configuration
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+	DriverSMTP = "smtp"
+)
+
+// Config selects and configures the mailer
+type Config struct {
+	// Driver is one of DriverLog, DriverFile or DriverSMTP
+	Driver string
+	From   string
+	// Dir is the directory the file mailer writes messages to
+	Dir string
+	// SMTP server of the SMTP mailer, authentication is skipped without a username
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// ConfigFromEnv reads the mailer configuration from the environment:
+// MAILER, MAIL_FROM, MAILER_DIR, SMTP_HOST, SMTP_PORT, SMTP_USERNAME and SMTP_PASSWORD
+// MAILER is required in every environment, local development sets it to log explicitly
+func ConfigFromEnv() (Config, error) {
+	driver := strings.ToLower(os.Getenv("MAILER"))
+	if driver == "" {
+		return Config{}, fmt.Errorf("MAILER is not set, set it to %s to send emails, or to %s or %s for local development", DriverSMTP, DriverLog, DriverFile)
+	}
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@konsultn.com"
+	}
+	dir := os.Getenv("MAILER_DIR")
+	if dir == "" {
+		dir = "mail"
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	return Config{
+		Driver:       driver,
+		From:         from,
+		Dir:          dir,
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     port,
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+	}, nil
+}
+
+// New builds the mailer selected by the configuration
+// The driver has no default, the log mailer writes the links of auth emails to stdout and must be chosen explicitly
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case "":
+		return nil, fmt.Errorf("no mailer is configured, set MAILER to %s, %s or %s", DriverLog, DriverFile, DriverSMTP)
+	case DriverLog:
+		return NewLogMailer(), nil
+	case DriverFile:
+		return NewFileMailer(cfg.Dir, cfg.From), nil
+	case DriverSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("the smtp mailer requires SMTP_HOST")
+		}
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer '%s'", cfg.Driver)
+	}
+}
@@ -0,0 +1,29 @@
+package mailer_test
+
+import (
+	"konsultn-api/pkg/mailer"
+	"strings"
+	"testing"
+)
+
+func TestConfigFromEnvRequiresDriver(t *testing.T) {
+	t.Setenv("MAILER", "")
+	if _, err := mailer.ConfigFromEnv(); err == nil || !strings.Contains(err.Error(), "MAILER") {
+		t.Fatalf("expected an error naming MAILER, got %v", err)
+	}
+
+	t.Setenv("MAILER", "LOG")
+	cfg, err := mailer.ConfigFromEnv()
+	if err != nil || cfg.Driver != mailer.DriverLog {
+		t.Fatalf("driver = %q, %v", cfg.Driver, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := mailer.New(mailer.Config{Driver: mailer.DriverLog}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mailer.New(mailer.Config{Driver: mailer.DriverSMTP}); err == nil {
+		t.Fatal("the smtp mailer was built without a host")
+	}
+}